@@ -0,0 +1,164 @@
+package ctxs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	// DeadlineContextKey ключ хранения крайнего срока, полученного от вызывающей стороны. См. DeadlinePropagator.
+	DeadlineContextKey = "CTXS-Deadline"
+
+	// HeaderTimeout заголовок HTTP с оставшимся временем на обработку запроса в формате grpc-timeout, его же
+	// учитывает grpc-gateway.
+	HeaderTimeout = "Grpc-Timeout"
+
+	maxTimeoutValue = 99999999 // Максимальное значение таймаута в формате grpc-timeout (8 цифр).
+)
+
+var (
+	ErrInvalidTimeout = errors.New("invalid timeout")
+
+	_ Propagator = DeadlinePropagator{}
+
+	timeoutUnits = []struct {
+		unit   byte
+		factor time.Duration
+	}{
+		{'n', time.Nanosecond},
+		{'u', time.Microsecond},
+		{'m', time.Millisecond},
+		{'S', time.Second},
+		{'M', time.Minute},
+		{'H', time.Hour},
+	}
+)
+
+// DeadlinePropagator передает вызываемой стороне оставшееся до крайнего срока время в формате grpc-timeout. Через
+// gRPC крайний срок передается самим gRPC, поэтому распространитель работает только с транспортами, указанными в Names.
+type DeadlinePropagator struct {
+	Names map[Transport]string // Имена ключей носителей по видам транспорта.
+}
+
+// Inject см. Propagator.
+func (p DeadlinePropagator) Inject(ctx context.Context, carrier Carrier) {
+	name, ok := p.Names[carrier.Transport()]
+	if !ok {
+		return
+	}
+
+	if remaining, ok := Remaining(ctx); ok {
+		carrier.Set(name, EncodeTimeout(remaining))
+	}
+}
+
+// Extract см. Propagator. Крайний срок сохраняется в контексте по ключу DeadlineContextKey, применить его к контексту
+// можно с помощью WithBudget.
+func (p DeadlinePropagator) Extract(ctx context.Context, carrier Carrier) context.Context {
+	name, ok := p.Names[carrier.Transport()]
+	if !ok {
+		return ctx
+	}
+
+	value := carrier.Get(name)
+	if value == "" {
+		return ctx
+	}
+
+	timeout, err := DecodeTimeout(value)
+	if err != nil {
+		return ctx
+	}
+
+	return ValuePut(ctx, DeadlineContextKey, time.Now().Add(timeout))
+}
+
+// Detach возвращает контекст, сохраняющий значения родительского (логгер, идентификатор запроса, аутентификацию),
+// но не отменяемый вместе с ним и не имеющий крайнего срока. Предназначен для фоновых задач, которые должны пережить
+// обработку запроса.
+func Detach(ctx context.Context) context.Context {
+	return ValuePut(context.WithoutCancel(ctx), DeadlineContextKey, time.Time{})
+}
+
+// DetachWithTimeout возвращает отсоединенный контекст (см. Detach) с собственным таймаутом.
+func DetachWithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(Detach(ctx), timeout)
+}
+
+// Remaining возвращает время, оставшееся до ближайшего из крайних сроков: контекста и полученного от вызывающей
+// стороны. Вернет false, если крайний срок не установлен.
+func Remaining(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+
+	if propagated := ValueGet[time.Time](ctx, DeadlineContextKey); !propagated.IsZero() {
+		if !ok || propagated.Before(deadline) {
+			deadline, ok = propagated, true
+		}
+	}
+
+	if !ok {
+		return 0, false
+	}
+
+	return time.Until(deadline), true
+}
+
+// WithBudget возвращает контекст с крайним сроком, оставляющим долю reserve (от 0 до 1) оставшегося времени на
+// формирование ответа вызывающей стороне. Нижестоящие вызовы с этим контекстом получат сокращенный бюджет. Если крайний
+// срок не установлен, возвращает отменяемый контекст без крайнего срока.
+func WithBudget(ctx context.Context, reserve float64) (context.Context, context.CancelFunc) {
+	remaining, ok := Remaining(ctx)
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+
+	reserve = min(max(reserve, 0), 1)
+
+	return context.WithTimeout(ctx, time.Duration(float64(remaining)*(1-reserve)))
+}
+
+// EncodeTimeout возвращает представление таймаута в формате grpc-timeout: не более 8 цифр и единица измерения.
+func EncodeTimeout(timeout time.Duration) string {
+	if timeout <= 0 {
+		return "0n"
+	}
+
+	for _, unit := range timeoutUnits {
+		// Округляем вверх, чтобы не сократить бюджет вызываемой стороны до нуля.
+		value := (timeout + unit.factor - 1) / unit.factor
+		if value <= maxTimeoutValue {
+			return strconv.FormatInt(int64(value), 10) + string(unit.unit)
+		}
+	}
+
+	return strconv.Itoa(maxTimeoutValue) + "H"
+}
+
+// DecodeTimeout разбирает таймаут в формате grpc-timeout. Значения, превышающие максимальный time.Duration (например,
+// "99999999H"), ограничиваются им.
+func DecodeTimeout(value string) (time.Duration, error) {
+	if len(value) < 2 || len(value) > 9 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTimeout, value)
+	}
+
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTimeout, value)
+	}
+
+	for _, unit := range timeoutUnits {
+		if unit.unit == value[len(value)-1] {
+			if amount > math.MaxInt64/int64(unit.factor) {
+				return time.Duration(math.MaxInt64), nil
+			}
+
+			return time.Duration(amount) * unit.factor, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: unknown unit: %q", ErrInvalidTimeout, value)
+}
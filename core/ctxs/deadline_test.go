@@ -0,0 +1,96 @@
+package ctxs_test
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/ctxs"
+)
+
+func TestDetach(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), middleware.RequestIDKey, "req-1"), time.Second)
+	parent = ctxs.ValuePut(parent, ctxs.DeadlineContextKey, time.Now().Add(time.Second))
+	cancel()
+
+	detached := ctxs.Detach(parent)
+
+	assert.NoError(t, detached.Err())
+	assert.Equal(t, "req-1", detached.Value(middleware.RequestIDKey))
+
+	_, ok := ctxs.Remaining(detached)
+	assert.False(t, ok)
+
+	header := http.Header{}
+	ctxs.Inject(detached, ctxs.HeaderCarrier(header))
+	assert.Empty(t, header.Get(ctxs.HeaderTimeout))
+}
+
+func TestWithBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		reserve float64
+		want    time.Duration
+		wantOK  bool
+	}{
+		{name: "No deadline", wantOK: false},
+		{name: "Reserve 20%", timeout: 10 * time.Second, reserve: 0.2, want: 8 * time.Second, wantOK: true},
+		{name: "Reserve is clamped", timeout: 10 * time.Second, reserve: -1, want: 10 * time.Second, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				header := http.Header{ctxs.HeaderTimeout: []string{ctxs.EncodeTimeout(tt.timeout)}}
+				ctx = ctxs.Extract(ctx, ctxs.HeaderCarrier(header))
+			}
+
+			ctx, cancel := ctxs.WithBudget(ctx, tt.reserve)
+			defer cancel()
+
+			remaining, ok := ctxs.Remaining(ctx)
+			require.Equal(t, tt.wantOK, ok)
+			assert.InDelta(t, tt.want, remaining, float64(100*time.Millisecond))
+		})
+	}
+}
+
+func TestEncodeTimeout(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		want    string
+	}{
+		{timeout: 0, want: "0n"},
+		{timeout: 1500 * time.Millisecond, want: "1500000u"},
+		{timeout: 5 * time.Minute, want: "300000m"},
+		{timeout: 48 * time.Hour, want: "172800S"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			encoded := ctxs.EncodeTimeout(tt.timeout)
+			assert.Equal(t, tt.want, encoded)
+
+			decoded, err := ctxs.DecodeTimeout(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, tt.timeout, decoded)
+		})
+	}
+
+	decoded, err := ctxs.DecodeTimeout("99999999H")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(math.MaxInt64), decoded, "переполнение ограничивается максимальным значением")
+
+	for _, invalid := range []string{"", "1", "1x", "-1S", "123456789S"} {
+		_, err := ctxs.DecodeTimeout(invalid)
+		assert.ErrorIs(t, err, ctxs.ErrInvalidTimeout, invalid)
+	}
+}
@@ -0,0 +1,395 @@
+package ctxs
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/wal1251/pkg/tools/acceptlanguage"
+)
+
+const (
+	TransportHTTP  Transport = "http"  // Заголовки HTTP запроса.
+	TransportGRPC  Transport = "grpc"  // Метаданные gRPC.
+	TransportKafka Transport = "kafka" // Заголовки сообщения KAFKA.
+
+	// UserInfoKey ключ хранения информации о пользователе в контексте (map[string]any), совместим с grpcx.UserInfoKey
+	// и mw.UserInfoKey.
+	UserInfoKey = "userInfo"
+
+	// TenantIDKey ключ хранения идентификатора арендатора (tenant) в контексте (string).
+	TenantIDKey = "tenantID"
+)
+
+var (
+	_ Carrier = HeaderCarrier(nil)
+	_ Carrier = MetadataCarrier(nil)
+
+	_ Propagator = ValuePropagator{}
+	_ Propagator = UserInfoPropagator{}
+	_ Propagator = TraceContextPropagator{}
+	_ Propagator = (*Propagators)(nil)
+
+	// UserInfoKeys атрибуты информации о пользователе, передаваемые между сервисами по умолчанию: только
+	// идентификаторы.
+	UserInfoKeys = []string{"user_id", "session_id"}
+
+	// UserInfoPIIKeys атрибуты информации о пользователе, содержащие персональные данные. По умолчанию не передаются,
+	// передачу можно включить, зарегистрировав распространитель:
+	//
+	//	ctxs.RegisterPropagator(ctxs.UserInfoPropagator{Keys: ctxs.UserInfoPIIKeys})
+	UserInfoPIIKeys = []string{"phone_number", "name"}
+
+	defaultPropagators = NewPropagators(
+		RequestIDPropagator(),
+		AcceptLanguagePropagator(),
+		UserInfoPropagator{Keys: UserInfoKeys},
+		TenantPropagator(),
+		TraceContextPropagator{TextMapPropagator: propagation.TraceContext{}},
+		DeadlinePropagator{Names: map[Transport]string{TransportHTTP: HeaderTimeout}},
+	)
+)
+
+type (
+	// Transport вид транспорта, через который передаются значения контекста между сервисами.
+	Transport string
+
+	// Carrier носитель значений контекста: заголовки HTTP, метаданные gRPC, заголовки сообщения KAFKA. Совместим с
+	// propagation.TextMapCarrier.
+	Carrier interface {
+		// Transport возвращает вид транспорта носителя.
+		Transport() Transport
+		// Get возвращает значение по ключу или пустую строку.
+		Get(key string) string
+		// Set устанавливает значение по ключу, заменяя имеющееся.
+		Set(key, value string)
+		// Keys возвращает список ключей носителя.
+		Keys() []string
+	}
+
+	// Propagator описывает, как значение контекста записывается в носитель и извлекается из него.
+	Propagator interface {
+		// Inject записывает значение из контекста в носитель.
+		Inject(ctx context.Context, carrier Carrier)
+		// Extract возвращает контекст, дополненный значением из носителя.
+		Extract(ctx context.Context, carrier Carrier) context.Context
+	}
+
+	// Propagators реестр распространителей значений контекста, применяет все зарегистрированные Propagator.
+	Propagators struct {
+		mu          sync.RWMutex
+		propagators []Propagator
+	}
+
+	// ValuePropagator распространяет строковое значение контекста. Names задает имя ключа носителя для каждого вида
+	// транспорта, если транспорт не указан в Names, значение через него не передается.
+	ValuePropagator struct {
+		Names map[Transport]string                                    // Имена ключей носителей по видам транспорта.
+		Get   func(ctx context.Context) (string, bool)                // Получение значения из контекста.
+		Put   func(ctx context.Context, value string) context.Context // Запись значения в контекст.
+	}
+
+	// UserInfoPropagator распространяет информацию о пользователе, хранимую в контексте по ключу UserInfoKey. Через
+	// HTTP значения не передаются и не принимаются, так как заголовки внешнего запроса нельзя считать доверенными.
+	// Извлеченные атрибуты дополняют информацию о пользователе, уже имеющуюся в контексте, поэтому несколько
+	// распространителей с разными Keys могут использоваться совместно.
+	UserInfoPropagator struct {
+		Keys []string // Передаваемые атрибуты пользователя.
+	}
+
+	// TraceContextPropagator распространяет контекст трассировки с помощью propagation.TextMapPropagator.
+	TraceContextPropagator struct {
+		propagation.TextMapPropagator
+	}
+
+	// HeaderCarrier носитель значений контекста в заголовках HTTP.
+	HeaderCarrier http.Header
+
+	// MetadataCarrier носитель значений контекста в метаданных gRPC.
+	MetadataCarrier metadata.MD
+)
+
+// Register добавляет распространители в реестр.
+func (p *Propagators) Register(propagators ...Propagator) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.propagators = append(p.propagators, propagators...)
+}
+
+// Inject записывает в носитель значения контекста всех зарегистрированных распространителей.
+func (p *Propagators) Inject(ctx context.Context, carrier Carrier) {
+	for _, propagator := range p.list() {
+		propagator.Inject(ctx, carrier)
+	}
+}
+
+// Extract возвращает контекст, дополненный значениями из носителя всех зарегистрированных распространителей.
+func (p *Propagators) Extract(ctx context.Context, carrier Carrier) context.Context {
+	for _, propagator := range p.list() {
+		ctx = propagator.Extract(ctx, carrier)
+	}
+
+	return ctx
+}
+
+func (p *Propagators) list() []Propagator {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.propagators
+}
+
+// Inject см. Propagator.
+func (p ValuePropagator) Inject(ctx context.Context, carrier Carrier) {
+	name, ok := p.Names[carrier.Transport()]
+	if !ok || p.Get == nil {
+		return
+	}
+
+	if value, ok := p.Get(ctx); ok && value != "" {
+		carrier.Set(name, value)
+	}
+}
+
+// Extract см. Propagator.
+func (p ValuePropagator) Extract(ctx context.Context, carrier Carrier) context.Context {
+	name, ok := p.Names[carrier.Transport()]
+	if !ok || p.Put == nil {
+		return ctx
+	}
+
+	if value := carrier.Get(name); value != "" {
+		return p.Put(ctx, value)
+	}
+
+	return ctx
+}
+
+// Inject см. Propagator.
+func (p UserInfoPropagator) Inject(ctx context.Context, carrier Carrier) {
+	if carrier.Transport() == TransportHTTP {
+		return
+	}
+
+	userInfo, ok := ctx.Value(UserInfoKey).(map[string]any)
+	if !ok {
+		return
+	}
+
+	for _, key := range p.Keys {
+		if value, ok := userInfo[key]; ok {
+			carrier.Set(key, fmt.Sprintf("%v", value))
+		}
+	}
+}
+
+// Extract см. Propagator.
+func (p UserInfoPropagator) Extract(ctx context.Context, carrier Carrier) context.Context {
+	if carrier.Transport() == TransportHTTP {
+		return ctx
+	}
+
+	var userInfo map[string]any
+
+	for _, key := range p.Keys {
+		value := carrier.Get(key)
+		if value == "" {
+			continue
+		}
+
+		if userInfo == nil {
+			// Информация о пользователе в контексте не изменяется, дополняется ее копия.
+			existing, _ := ctx.Value(UserInfoKey).(map[string]any)
+			userInfo = maps.Clone(existing)
+
+			if userInfo == nil {
+				userInfo = make(map[string]any)
+			}
+		}
+
+		userInfo[key] = value
+	}
+
+	if userInfo == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, UserInfoKey, userInfo) //nolint:revive,staticcheck // совместимость с UserInfoKey.
+}
+
+// Inject см. Propagator.
+func (p TraceContextPropagator) Inject(ctx context.Context, carrier Carrier) {
+	p.TextMapPropagator.Inject(ctx, carrier)
+}
+
+// Extract см. Propagator.
+func (p TraceContextPropagator) Extract(ctx context.Context, carrier Carrier) context.Context {
+	return p.TextMapPropagator.Extract(ctx, carrier)
+}
+
+// Transport см. Carrier.
+func (c HeaderCarrier) Transport() Transport {
+	return TransportHTTP
+}
+
+// Get см. Carrier.
+func (c HeaderCarrier) Get(key string) string {
+	return http.Header(c).Get(key)
+}
+
+// Set см. Carrier.
+func (c HeaderCarrier) Set(key, value string) {
+	http.Header(c).Set(key, value)
+}
+
+// Keys см. Carrier.
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Transport см. Carrier.
+func (c MetadataCarrier) Transport() Transport {
+	return TransportGRPC
+}
+
+// Get см. Carrier.
+func (c MetadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// Set см. Carrier.
+func (c MetadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys см. Carrier.
+func (c MetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// NewPropagators возвращает новый реестр распространителей значений контекста.
+func NewPropagators(propagators ...Propagator) *Propagators {
+	return &Propagators{propagators: propagators}
+}
+
+// DefaultPropagators возвращает реестр распространителей по умолчанию: идентификатор запроса, язык, информация о
+// пользователе, арендатор, контекст трассировки, крайний срок.
+func DefaultPropagators() *Propagators {
+	return defaultPropagators
+}
+
+// RegisterPropagator добавляет распространители в реестр по умолчанию.
+func RegisterPropagator(propagators ...Propagator) {
+	defaultPropagators.Register(propagators...)
+}
+
+// Inject записывает значения контекста в носитель с помощью реестра по умолчанию.
+func Inject(ctx context.Context, carrier Carrier) {
+	defaultPropagators.Inject(ctx, carrier)
+}
+
+// Extract возвращает контекст, дополненный значениями из носителя, с помощью реестра по умолчанию.
+func Extract(ctx context.Context, carrier Carrier) context.Context {
+	return defaultPropagators.Extract(ctx, carrier)
+}
+
+// InjectOutgoingMetadata возвращает контекст, в исходящие метаданные gRPC которого добавлены значения контекста.
+// Имеющиеся в контексте исходящие метаданные сохраняются.
+func InjectOutgoingMetadata(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		md = metadata.MD{}
+	}
+
+	Inject(ctx, MetadataCarrier(md))
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// ExtractIncomingMetadata возвращает контекст, дополненный значениями из входящих метаданных gRPC.
+func ExtractIncomingMetadata(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	return Extract(ctx, MetadataCarrier(md))
+}
+
+// RequestIDPropagator возвращает распространитель идентификатора запроса (middleware.RequestIDKey).
+func RequestIDPropagator() ValuePropagator {
+	return ValuePropagator{
+		Names: map[Transport]string{
+			TransportHTTP:  middleware.RequestIDHeader,
+			TransportGRPC:  strings.ToLower(middleware.RequestIDHeader),
+			TransportKafka: strings.ToLower(middleware.RequestIDHeader),
+		},
+		Get: func(ctx context.Context) (string, bool) {
+			id, ok := ctx.Value(middleware.RequestIDKey).(string)
+
+			return id, ok
+		},
+		Put: func(ctx context.Context, value string) context.Context {
+			return context.WithValue(ctx, middleware.RequestIDKey, value)
+		},
+	}
+}
+
+// AcceptLanguagePropagator возвращает распространитель языка (acceptlanguage.AcceptLanguageKey).
+func AcceptLanguagePropagator() ValuePropagator {
+	return ValuePropagator{
+		Names: map[Transport]string{
+			TransportHTTP:  "Accept-Language",
+			TransportGRPC:  "accept_language",
+			TransportKafka: "accept-language",
+		},
+		Get: func(ctx context.Context) (string, bool) {
+			language, ok := ctx.Value(acceptlanguage.AcceptLanguageKey).(string)
+
+			return language, ok
+		},
+		Put: func(ctx context.Context, value string) context.Context {
+			return context.WithValue(ctx, acceptlanguage.AcceptLanguageKey, acceptlanguage.Validate(value))
+		},
+	}
+}
+
+// TenantPropagator возвращает распространитель идентификатора арендатора (TenantIDKey). Как и информация о
+// пользователе, арендатор не передается и не принимается через HTTP, так как заголовки внешнего запроса нельзя считать
+// доверенными: во входящем HTTP запросе арендатор определяется аутентификацией.
+func TenantPropagator() ValuePropagator {
+	return ValuePropagator{
+		Names: map[Transport]string{
+			TransportGRPC:  "tenant-id",
+			TransportKafka: "tenant-id",
+		},
+		Get: func(ctx context.Context) (string, bool) {
+			return ValueCheckAndGet[string](ctx, TenantIDKey)
+		},
+		Put: func(ctx context.Context, value string) context.Context {
+			return ValuePut(ctx, TenantIDKey, value)
+		},
+	}
+}
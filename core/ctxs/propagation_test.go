@@ -0,0 +1,119 @@
+package ctxs_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/wal1251/pkg/core/ctxs"
+	"github.com/wal1251/pkg/tools/acceptlanguage"
+)
+
+func TestPropagators(t *testing.T) {
+	source := context.WithValue(context.Background(), middleware.RequestIDKey, "req-1")
+	source = context.WithValue(source, acceptlanguage.AcceptLanguageKey, acceptlanguage.IFTELangSubtagKazakh)
+	source = context.WithValue(source, ctxs.UserInfoKey, map[string]any{"user_id": "42", "other": "skip"}) //nolint:staticcheck
+	source = ctxs.ValuePut(source, ctxs.TenantIDKey, "acme")
+
+	tests := []struct {
+		name         string
+		carrier      ctxs.Carrier
+		wantKeys     map[string]string
+		wantUserInfo map[string]any
+		wantTenant   string
+	}{
+		{
+			name:    "HTTP",
+			carrier: ctxs.HeaderCarrier(http.Header{}),
+			wantKeys: map[string]string{
+				"X-Request-Id":    "req-1",
+				"Accept-Language": "kk",
+			},
+		},
+		{
+			name:    "gRPC",
+			carrier: ctxs.MetadataCarrier(metadata.MD{}),
+			wantKeys: map[string]string{
+				"x-request-id":    "req-1",
+				"accept_language": "kk",
+				"user_id":         "42",
+				"tenant-id":       "acme",
+			},
+			wantUserInfo: map[string]any{"user_id": "42"},
+			wantTenant:   "acme",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctxs.Inject(source, tt.carrier)
+
+			assert.Len(t, tt.carrier.Keys(), len(tt.wantKeys))
+			for key, value := range tt.wantKeys {
+				assert.Equal(t, value, tt.carrier.Get(key), key)
+			}
+
+			ctx := ctxs.Extract(context.Background(), tt.carrier)
+			assert.Equal(t, "req-1", ctx.Value(middleware.RequestIDKey))
+			assert.Equal(t, "kk", ctx.Value(acceptlanguage.AcceptLanguageKey))
+
+			userInfo, _ := ctx.Value(ctxs.UserInfoKey).(map[string]any)
+			assert.Equal(t, tt.wantUserInfo, userInfo)
+			assert.Equal(t, tt.wantTenant, ctxs.ValueGet[string](ctx, ctxs.TenantIDKey))
+		})
+	}
+}
+
+func TestInjectOutgoingMetadata(t *testing.T) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-custom", "value")
+	ctx = context.WithValue(ctx, middleware.RequestIDKey, "req-1")
+
+	md, ok := metadata.FromOutgoingContext(ctxs.InjectOutgoingMetadata(ctx))
+	assert.True(t, ok)
+	assert.Equal(t, []string{"value"}, md.Get("x-custom"))
+	assert.Equal(t, []string{"req-1"}, md.Get("x-request-id"))
+}
+
+func TestUserInfoPropagator(t *testing.T) {
+	source := context.WithValue(context.Background(), ctxs.UserInfoKey, map[string]any{ //nolint:staticcheck
+		"user_id":      "42",
+		"session_id":   "s-1",
+		"phone_number": "+79123456789",
+		"name":         "John",
+	})
+
+	tests := []struct {
+		name         string
+		propagators  *ctxs.Propagators
+		wantUserInfo map[string]any
+	}{
+		{
+			name:         "Только идентификаторы по умолчанию",
+			propagators:  ctxs.NewPropagators(ctxs.UserInfoPropagator{Keys: ctxs.UserInfoKeys}),
+			wantUserInfo: map[string]any{"user_id": "42", "session_id": "s-1"},
+		},
+		{
+			name: "Персональные данные по выбору",
+			propagators: ctxs.NewPropagators(
+				ctxs.UserInfoPropagator{Keys: ctxs.UserInfoKeys},
+				ctxs.UserInfoPropagator{Keys: ctxs.UserInfoPIIKeys},
+			),
+			wantUserInfo: map[string]any{"user_id": "42", "session_id": "s-1", "phone_number": "+79123456789", "name": "John"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			carrier := ctxs.MetadataCarrier(metadata.MD{})
+			tt.propagators.Inject(source, carrier)
+			assert.Len(t, carrier.Keys(), len(tt.wantUserInfo))
+
+			ctx := tt.propagators.Extract(context.Background(), carrier)
+			assert.Equal(t, tt.wantUserInfo, ctx.Value(ctxs.UserInfoKey))
+		})
+	}
+}
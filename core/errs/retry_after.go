@@ -0,0 +1,44 @@
+package errs
+
+import (
+	"errors"
+	"time"
+)
+
+var _ error = (*RetryAfterError)(nil)
+
+// RetryAfterError ошибка с рекомендованной задержкой перед повторным запросом, например, для ErrTooManyRequests.
+// Задержка передается клиенту, например, в HTTP заголовке Retry-After.
+type RetryAfterError struct {
+	Err   error         // Ошибка-причина.
+	After time.Duration // Задержка перед повторным запросом.
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap вернет исходную ошибку-причину.
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// WithRetryAfter возвращает ошибку err, дополненную задержкой перед повторным запросом.
+//
+//	return errs.WithRetryAfter(errs.Wrapf(errs.ErrTooManyRequests, "too many attempts"), time.Minute)
+func WithRetryAfter(err error, after time.Duration) *RetryAfterError {
+	return &RetryAfterError{
+		Err:   err,
+		After: after,
+	}
+}
+
+// RetryAfter возвращает задержку перед повторным запросом, если она указана в цепочке ошибки err.
+func RetryAfter(err error) (time.Duration, bool) {
+	var target *RetryAfterError
+	if errors.As(err, &target) {
+		return target.After, true
+	}
+
+	return 0, false
+}
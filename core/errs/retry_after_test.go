@@ -0,0 +1,25 @@
+package errs_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wal1251/pkg/core/errs"
+)
+
+func TestRetryAfter(t *testing.T) {
+	err := fmt.Errorf("login: %w", errs.WithRetryAfter(errs.Wrapf(errs.ErrTooManyRequests, "locked"), time.Minute))
+
+	after, ok := errs.RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, after)
+	assert.ErrorIs(t, err, errs.ErrTooManyRequests)
+	assert.Equal(t, "login: TOO_MANY_REQUESTS: locked", err.Error())
+
+	_, ok = errs.RetryAfter(errors.New("fake"))
+	assert.False(t, ok)
+}
@@ -5,14 +5,16 @@ import "github.com/wal1251/pkg/core/cfg"
 const (
 	LevelInfo = "info" // Уровень логирования 'info'.
 
-	CfgKeyLevel  cfg.Key = "LOG_LEVEL"  // Конфиг: string - уровень логирования.
-	CfgKeyPretty cfg.Key = "LOG_PRETTY" // Конфиг: bool - форматированный вывод логов.
+	CfgKeyLevel       cfg.Key = "LOG_LEVEL"        // Конфиг: string - уровень логирования.
+	CfgKeyPretty      cfg.Key = "LOG_PRETTY"       // Конфиг: bool - форматированный вывод логов.
+	CfgKeyDebugSecret cfg.Key = "LOG_DEBUG_SECRET" //nolint:gosec // Конфиг: string - секрет подписи заголовка отладки запроса.
 
 	CfgDefaultLevel = LevelInfo // Уровень логирования по умолчанию.
 )
 
 // Config параметры конфигурации логера.
 type Config struct {
-	Level  string // Уровень логирования.
-	Pretty bool   // Форматированный вывод логов.
+	Level       string // Уровень логирования.
+	Pretty      bool   // Форматированный вывод логов.
+	DebugSecret string // Секрет подписи заголовка отладки запроса, если пуст - отладка отдельных запросов отключена.
 }
@@ -10,7 +10,8 @@ import (
 // CfgFromViper загружает конфиг с помощью viper.
 func CfgFromViper(v *viper.Viper, keyMapping ...cfg.KeyMap) *Config {
 	return &Config{
-		Level:  viperx.Get(v, CfgKeyLevel.Map(keyMapping...), CfgDefaultLevel),
-		Pretty: viperx.Get(v, CfgKeyPretty.Map(keyMapping...), false),
+		Level:       viperx.Get(v, CfgKeyLevel.Map(keyMapping...), CfgDefaultLevel),
+		Pretty:      viperx.Get(v, CfgKeyPretty.Map(keyMapping...), false),
+		DebugSecret: viperx.Get(v, CfgKeyDebugSecret.Map(keyMapping...), ""),
 	}
 }
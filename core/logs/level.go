@@ -0,0 +1,118 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+
+	"github.com/wal1251/pkg/core/ctxs"
+)
+
+// VerbosityContextKey ключ хранения уровня логирования, повышенного для отдельного запроса. См. Verbose.
+const VerbosityContextKey = "LOGS-Verbosity"
+
+var (
+	_ zerolog.Hook    = LevelHook{}
+	_ zerolog.Sampler = (*LevelVar)(nil)
+)
+
+type (
+	// LevelVar уровень логирования, который может быть изменен во время работы приложения, например, с помощью
+	// httpx.LogLevelHandler. Действует только на логеры, созданные с ним (см. LoggerWithLevel), один LevelVar может
+	// использоваться несколькими логерами. Безопасен для конкурентного использования.
+	//
+	// LevelVar реализует zerolog.Sampler: установленный с помощью zerolog.Logger.Sample, он отбрасывает события ниже
+	// уровня еще до их создания.
+	LevelVar struct {
+		level atomic.Int32
+	}
+
+	// LevelHook хук логера, отбрасывающий события ниже уровня Level. События логеров, созданных с помощью Verbose,
+	// фильтруются по уровню, установленному для запроса. Если Level не задан, хук события не отбрасывает.
+	LevelHook struct {
+		Level *LevelVar
+	}
+)
+
+// Level возвращает текущий уровень логирования.
+func (v *LevelVar) Level() zerolog.Level {
+	return zerolog.Level(v.level.Load())
+}
+
+// SetLevel устанавливает уровень логирования.
+func (v *LevelVar) SetLevel(level zerolog.Level) {
+	v.level.Store(int32(level))
+}
+
+// Set устанавливает уровень логирования, заданный строкой, например "debug".
+func (v *LevelVar) Set(level string) error {
+	lvl, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("can't set log level: %w", err)
+	}
+
+	v.SetLevel(lvl)
+
+	return nil
+}
+
+// Sample см. zerolog.Sampler.
+func (v *LevelVar) Sample(level zerolog.Level) bool {
+	return level >= v.Level()
+}
+
+// Run см. zerolog.Hook.Run().
+func (h LevelHook) Run(event *zerolog.Event, level zerolog.Level, _ string) {
+	if h.Level == nil || level >= h.Level.Level() {
+		return
+	}
+
+	if verbosity, ok := ctxs.ValueCheckAndGet[zerolog.Level](event.GetCtx(), VerbosityContextKey); ok && level >= verbosity {
+		return
+	}
+
+	event.Discard()
+}
+
+// NewLevelVar возвращает новый LevelVar с уровнем level.
+func NewLevelVar(level zerolog.Level) *LevelVar {
+	v := new(LevelVar)
+	v.SetLevel(level)
+
+	return v
+}
+
+// Verbose возвращает новый контекст, логер которого пишет события, начиная с указанного уровня level, независимо от
+// уровня логирования (LevelVar) логера. Например, позволяет включить трассировку для отдельного запроса:
+//
+//	ctx = logs.Verbose(ctx, zerolog.TraceLevel)
+//
+// Verbose действует только на логер, возвращенный в контексте, и не меняет LevelVar: остальные логеры, в том числе
+// логеры других запросов, продолжают отбрасывать события ниже своего уровня. События логера Verbose создаются до
+// уровня level и отбрасываются LevelHook, если они ниже и level, и текущего уровня логера. Сэмплер логера из контекста
+// (см. zerolog.Logger.Sample) при этом не используется.
+func Verbose(ctx context.Context, level zerolog.Level) context.Context {
+	ctx = ctxs.ValuePut(ctx, VerbosityContextKey, level)
+
+	logger := FromContext(ctx).Sample(nil).With().Ctx(ctx).Logger()
+	if logger.GetLevel() > level {
+		logger = logger.Level(level)
+	}
+
+	return ToContext(ctx, &logger)
+}
+
+// Enabled вернет true, если событие уровня level будет записано логером из контекста ctx. Полезно, чтобы не
+// формировать затратные сообщения, которые все равно будут отброшены. В контексте Verbose события ниже уровня,
+// установленного для запроса, считаются отключенными.
+func Enabled(ctx context.Context, level zerolog.Level) bool {
+	if !FromContext(ctx).WithLevel(level).Enabled() {
+		return false
+	}
+
+	verbosity, ok := ctxs.ValueCheckAndGet[zerolog.Level](ctx, VerbosityContextKey)
+
+	return !ok || level >= verbosity
+}
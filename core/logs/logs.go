@@ -66,16 +66,32 @@ func (m EventOption) To(newEvent func() *zerolog.Event) *zerolog.Event {
 	return m.ApplyTo(newEvent())
 }
 
-// Logger возвращает новый экземпляр логера с указанными опциями LoggerOption.
+// Logger возвращает новый экземпляр логера с указанными опциями LoggerOption и уровнем логирования из конфигурации.
+// Уровень каждого логера независим от других, чтобы изменять его во время работы приложения, используйте
+// LoggerWithLevel.
 func Logger(cfg *Config, options ...LoggerOption) zerolog.Logger {
+	lvl, _ := zerolog.ParseLevel(cfg.Level)
+
+	return LoggerWithLevel(cfg, NewLevelVar(lvl), options...)
+}
+
+// LoggerWithLevel возвращает новый экземпляр логера с указанными опциями LoggerOption, уровень логирования которого
+// задается level и может быть изменен во время работы приложения. Уровень из конфигурации не используется:
+//
+//	level := logs.NewLevelVar(zerolog.InfoLevel)
+//	logger := logs.LoggerWithLevel(cfg, level)
+//	handler, err := httpx.LogLevelHandler(level, authManager, "admin")
+//
+// .
+func LoggerWithLevel(cfg *Config, level *LevelVar, options ...LoggerOption) zerolog.Logger {
 	var output io.Writer = os.Stdout
 	if cfg.Pretty {
 		output = zerolog.ConsoleWriter{Out: output, TimeFormat: time.RFC3339}
 	}
 
-	lvl, _ := zerolog.ParseLevel(cfg.Level)
+	logger := zerolog.New(output).Sample(level).Hook(LevelHook{Level: level})
 
-	return Options(options...).ApplyTo(zerolog.New(output).Level(lvl).With().Timestamp()).Logger()
+	return Options(options...).ApplyTo(logger.With().Timestamp()).Logger()
 }
 
 // SubLogger возвращает новый сублогер, наследованный от указанного, с примененными функциональными опциями.
@@ -21,11 +21,14 @@ const (
 	UserIDTag          Tag = "user_id"           // Идентификатор авторизованного пользователя.
 	UserAuthorityTag   Tag = "user_authority"    // Полномочия авторизованного пользователя.
 	TokenIDTag         Tag = "token_id"          // Идентификатор токена авторизованного пользователя.
+	TenantIDTag        Tag = "tenant_id"         // Идентификатор арендатора (tenant) авторизованного пользователя.
 	ComponentTag       Tag = "component"         // Идентифицирует вызываемый компонент.
 	ComponentLabelTag  Tag = "component_label"   // Идентифицирует экземпляр вызываемого компонента.
 	ComponentCallIDTag Tag = "component_call_id" // Идентифицирует конкретный запрос к компоненту.
 	PathTag            Tag = "path"              // URL запроса.
 	HeadersTag         Tag = "headers"           // Заголовки запроса.
+	AuditTag           Tag = "audit"             // Категория записи журнала аудита.
+	PolicyTag          Tag = "policy"            // Примененная политика доступа.
 )
 
 // Tag структурный тэг логов.
@@ -139,6 +142,18 @@ func WithRequestID(ctx context.Context) LoggerOption {
 	return RequestIDTag.Option(RequestID(ctx))
 }
 
+// WithTenantID возвращает функциональную опцию логера, которая установит тег с идентификатором арендатора, см.
+// TenantIDTag. Пустой идентификатор не записывается.
+func WithTenantID(tenantID string) LoggerOption {
+	return func(z zerolog.Context) zerolog.Context {
+		if tenantID == "" {
+			return z
+		}
+
+		return TenantIDTag.Option(tenantID).ApplyTo(z)
+	}
+}
+
 // WithMethod возвращает функциональную опцию логера, которая применит и установит тег с названием метода,
 // сформированный из значений method и object (в формате {object}::{method}).
 func WithMethod(method string, object any) LoggerOption {
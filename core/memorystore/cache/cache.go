@@ -0,0 +1,352 @@
+// Package cache предоставляет типизированный кэш поверх memorystore.MemoryStore по схеме cache-aside: значение ищется в
+// хранилище, а при отсутствии загружается функцией Loader и сохраняется.
+//
+// Одновременные загрузки одного ключа объединяются в одну (singleflight). Чтобы при истечении популярного ключа
+// хранилище источника не получило лавину одинаковых запросов, значение обновляется заранее с вероятностью, растущей
+// по мере приближения срока истечения (probabilistic early expiration, XFetch). Отсутствие значения (ошибка
+// errs.ErrNotFound или memorystore.ErrKeyNotFound) также кэшируется на время WithNegativeTTL.
+//
+//	users := cache.New[uuid.UUID, User](store, cache.WithKeyBuilder(cache.KeyPrefix[uuid.UUID]("user")))
+//	user, err := users.GetOrLoad(ctx, id, func(ctx context.Context, id uuid.UUID) (User, error) {
+//		return repo.GetUser(ctx, id)
+//	})
+//
+// Статистику обращений (Cache.Stats) пакет не экспортирует сам: счетчики монотонно растут, поэтому их можно передавать в
+// систему мониторинга как есть, например, в функции обратного вызова асинхронных счетчиков OpenTelemetry
+// (metric.Int64ObservableCounter) или при периодическом опросе.
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/core/memorystore"
+)
+
+const (
+	DefaultTTL  = 5 * time.Minute // Время жизни значения по умолчанию.
+	DefaultBeta = 1.0             // Коэффициент раннего обновления по умолчанию.
+)
+
+var ErrLoaderPanics = errors.New("cache loader panics") // Паника при загрузке значения.
+
+type (
+	// Cache типизированный кэш значений V по ключам K поверх memorystore.MemoryStore.
+	Cache[K any, V any] struct {
+		store       memorystore.MemoryStore
+		keyBuilder  KeyBuilder[K]
+		ttl         time.Duration
+		negativeTTL time.Duration
+		beta        float64
+		now         func() time.Time
+		random      func() float64
+		group       singleflight.Group
+		stats       counters
+	}
+
+	// Loader загружает значение по ключу из источника, например, из базы данных.
+	Loader[K any, V any] func(ctx context.Context, key K) (V, error)
+
+	// Option опция Cache.
+	Option[K any, V any] func(*Cache[K, V])
+
+	// Stats статистика обращений к кэшу.
+	Stats struct {
+		Hits           uint64 // Значение найдено в кэше.
+		NegativeHits   uint64 // В кэше найдена отметка об отсутствии значения.
+		Misses         uint64 // Значение не найдено в кэше.
+		Loads          uint64 // Вызовы Loader.
+		LoadErrors     uint64 // Ошибки и паники Loader, кроме отсутствия значения.
+		EarlyRefreshes uint64 // Значения, обновленные до истечения срока жизни.
+		StoreErrors    uint64 // Ошибки хранилища, при которых значение загружалось из источника.
+	}
+
+	counters struct {
+		hits, negativeHits, misses, loads, loadErrors, earlyRefreshes, storeErrors atomic.Uint64
+	}
+
+	// entry значение в хранилище. Срок жизни и время загрузки нужны для раннего обновления.
+	entry[V any] struct {
+		Value     V             `json:"v"`
+		Missing   bool          `json:"m,omitempty"`
+		Reason    string        `json:"r,omitempty"`
+		ExpiresAt time.Time     `json:"e"`
+		Delta     time.Duration `json:"d"`
+	}
+)
+
+// GetOrLoad возвращает значение по ключу из кэша, а при отсутствии или скором истечении загружает его с помощью loader и
+// сохраняет в кэш. Одновременные загрузки одного ключа выполняются один раз. Если значение отсутствует в источнике,
+// вернет ошибку errs.ErrNotFound. Ошибки хранилища не прерывают загрузку, а записываются в журнал. Если раннее
+// обновление завершилось ошибкой, возвращается закэшированное значение, а ошибка учитывается в Stats.LoadErrors. Паника
+// в loader возвращается как ошибка ErrLoaderPanics.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader Loader[K, V]) (V, error) {
+	storeKey := c.keyBuilder(key)
+
+	cached, found := c.lookup(ctx, storeKey)
+	if found {
+		if !c.expiresSoon(cached) {
+			return c.hit(cached)
+		}
+
+		c.stats.earlyRefreshes.Add(1)
+	} else {
+		c.stats.misses.Add(1)
+	}
+
+	result := c.group.DoChan(storeKey, func() (loaded any, err error) {
+		// Паника в DoChan завершает процесс, поэтому она преобразуется в ошибку здесь.
+		defer func() {
+			if x := recover(); x != nil {
+				c.stats.loadErrors.Add(1)
+				logs.FromContext(ctx).Error().Stack().Str("key", storeKey).Msgf("cache loader panic: %v", x)
+				err = fmt.Errorf("%w: %v", ErrLoaderPanics, x)
+			}
+		}()
+
+		// Загрузка не прерывается отменой контекста первого вызывающего, так как ее результат ждут и другие.
+		return c.load(context.WithoutCancel(ctx), key, storeKey, loader)
+	})
+
+	select {
+	case <-ctx.Done():
+		var zero V
+
+		return zero, fmt.Errorf("cache load is canceled: %w", ctx.Err())
+	case res := <-result:
+		if res.Err != nil && found {
+			// Значение еще не истекло, поэтому при ошибке раннего обновления возвращается закэшированное
+			logs.FromContext(ctx).Warn().Err(res.Err).Str("key", storeKey).Msg("failed to refresh cached value")
+
+			return c.value(cached)
+		}
+
+		if res.Err != nil {
+			var zero V
+
+			return zero, res.Err
+		}
+
+		return c.value(res.Val.(entry[V])) //nolint:forcetypeassert
+	}
+}
+
+// Get возвращает значение по ключу из кэша. Если значения нет в кэше, вернет ошибку memorystore.ErrKeyNotFound, если
+// закэшировано его отсутствие - errs.ErrNotFound.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	cached, found := c.lookup(ctx, c.keyBuilder(key))
+	if !found {
+		c.stats.misses.Add(1)
+
+		var zero V
+
+		return zero, memorystore.ErrKeyNotFound
+	}
+
+	return c.hit(cached)
+}
+
+// Set сохраняет значение в кэш.
+func (c *Cache[K, V]) Set(ctx context.Context, key K, value V) error {
+	return c.save(ctx, c.keyBuilder(key), entry[V]{Value: value, ExpiresAt: c.now().Add(c.ttl)}, c.ttl)
+}
+
+// Delete удаляет значения из кэша, например, после изменения их в источнике.
+func (c *Cache[K, V]) Delete(ctx context.Context, keys ...K) error {
+	storeKeys := make([]string, len(keys))
+	for i, key := range keys {
+		storeKeys[i] = c.keyBuilder(key)
+	}
+
+	if _, err := c.store.Delete(ctx, storeKeys...); err != nil {
+		return fmt.Errorf("can't delete cached values: %w", err)
+	}
+
+	return nil
+}
+
+// Stats возвращает статистику обращений к кэшу.
+func (c *Cache[K, V]) Stats() Stats {
+	return Stats{
+		Hits:           c.stats.hits.Load(),
+		NegativeHits:   c.stats.negativeHits.Load(),
+		Misses:         c.stats.misses.Load(),
+		Loads:          c.stats.loads.Load(),
+		LoadErrors:     c.stats.loadErrors.Load(),
+		EarlyRefreshes: c.stats.earlyRefreshes.Load(),
+		StoreErrors:    c.stats.storeErrors.Load(),
+	}
+}
+
+func (c *Cache[K, V]) lookup(ctx context.Context, storeKey string) (entry[V], bool) {
+	var cached entry[V]
+
+	value, err := c.store.Get(ctx, storeKey)
+	if err != nil {
+		if !errors.Is(err, memorystore.ErrKeyNotFound) {
+			c.stats.storeErrors.Add(1)
+			logs.FromContext(ctx).Warn().Err(err).Str("key", storeKey).Msg("failed to read cached value")
+		}
+
+		return cached, false
+	}
+
+	if err = value.Struct(&cached); err != nil {
+		c.stats.storeErrors.Add(1)
+		logs.FromContext(ctx).Warn().Err(err).Str("key", storeKey).Msg("failed to decode cached value")
+
+		return cached, false
+	}
+
+	return cached, true
+}
+
+func (c *Cache[K, V]) load(ctx context.Context, key K, storeKey string, loader Loader[K, V]) (entry[V], error) {
+	c.stats.loads.Add(1)
+
+	started := c.now()
+	value, err := loader(ctx, key)
+	now := c.now()
+
+	if err != nil {
+		if !isNotFound(err) {
+			c.stats.loadErrors.Add(1)
+
+			return entry[V]{}, err
+		}
+
+		missing := entry[V]{Missing: true, Reason: err.Error(), ExpiresAt: now.Add(c.negativeTTL)}
+		if c.negativeTTL > 0 {
+			c.saveQuietly(ctx, storeKey, missing, c.negativeTTL)
+		}
+
+		return missing, nil
+	}
+
+	loaded := entry[V]{Value: value, ExpiresAt: now.Add(c.ttl), Delta: now.Sub(started)}
+	c.saveQuietly(ctx, storeKey, loaded, c.ttl)
+
+	return loaded, nil
+}
+
+func (c *Cache[K, V]) save(ctx context.Context, storeKey string, value entry[V], ttl time.Duration) error {
+	if err := c.store.Set(ctx, storeKey, value, ttl); err != nil {
+		return fmt.Errorf("can't save cached value: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Cache[K, V]) saveQuietly(ctx context.Context, storeKey string, value entry[V], ttl time.Duration) {
+	if err := c.save(ctx, storeKey, value, ttl); err != nil {
+		c.stats.storeErrors.Add(1)
+		logs.FromContext(ctx).Warn().Err(err).Str("key", storeKey).Msg("failed to save cached value")
+	}
+}
+
+func (c *Cache[K, V]) hit(cached entry[V]) (V, error) {
+	if cached.Missing {
+		c.stats.negativeHits.Add(1)
+	} else {
+		c.stats.hits.Add(1)
+	}
+
+	return c.value(cached)
+}
+
+func (c *Cache[K, V]) value(cached entry[V]) (V, error) {
+	if cached.Missing {
+		var zero V
+
+		return zero, errs.Wrapf(errs.ErrNotFound, "%s", cached.Reason)
+	}
+
+	return cached.Value, nil
+}
+
+// expiresSoon реализует XFetch: значение обновляется заранее, если now - delta * beta * ln(random) >= expiresAt, где
+// delta - время загрузки значения. Чем дольше загрузка и ближе срок истечения, тем выше вероятность обновления.
+func (c *Cache[K, V]) expiresSoon(cached entry[V]) bool {
+	if c.beta <= 0 || cached.Missing || cached.Delta <= 0 {
+		return false
+	}
+
+	gap := time.Duration(float64(cached.Delta) * c.beta * -math.Log(c.random()))
+
+	return !c.now().Add(gap).Before(cached.ExpiresAt)
+}
+
+func isNotFound(err error) bool {
+	return errs.ErrNotFound.Is(err) || errors.Is(err, memorystore.ErrKeyNotFound)
+}
+
+// WithKeyBuilder устанавливает формирование ключей хранилища, по умолчанию KeyPrefix("cache").
+func WithKeyBuilder[K any, V any](keyBuilder KeyBuilder[K]) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.keyBuilder = keyBuilder
+	}
+}
+
+// WithTTL устанавливает время жизни значений, по умолчанию DefaultTTL.
+func WithTTL[K any, V any](ttl time.Duration) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.ttl = ttl
+	}
+}
+
+// WithNegativeTTL устанавливает время, на которое кэшируется отсутствие значения в источнике. По умолчанию отсутствие
+// значения не кэшируется.
+func WithNegativeTTL[K any, V any](ttl time.Duration) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.negativeTTL = ttl
+	}
+}
+
+// WithEarlyRefresh устанавливает коэффициент раннего обновления beta (по умолчанию DefaultBeta): чем он больше, тем
+// раньше обновляются значения. Значение 0 отключает раннее обновление.
+func WithEarlyRefresh[K any, V any](beta float64) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.beta = beta
+	}
+}
+
+// WithClock устанавливает источник текущего времени.
+func WithClock[K any, V any](now func() time.Time) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.now = now
+	}
+}
+
+// WithRandom устанавливает источник случайных чисел в интервале [0, 1) для раннего обновления.
+func WithRandom[K any, V any](random func() float64) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.random = random
+	}
+}
+
+// New возвращает новый Cache поверх хранилища store.
+func New[K any, V any](store memorystore.MemoryStore, opts ...Option[K, V]) *Cache[K, V] {
+	c := &Cache[K, V]{
+		store:      store,
+		keyBuilder: KeyPrefix[K]("cache"),
+		ttl:        DefaultTTL,
+		beta:       DefaultBeta,
+		now:        time.Now,
+		random:     rand.Float64, //nolint:gosec
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
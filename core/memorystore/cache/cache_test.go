@@ -0,0 +1,227 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/core/memorystore/cache"
+	"github.com/wal1251/pkg/core/memorystore/local"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newStore(t *testing.T) *local.Store {
+	t.Helper()
+
+	store, err := local.NewStore(&local.Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close(context.Background()) })
+
+	return store
+}
+
+func TestCache_GetOrLoad(t *testing.T) {
+	ctx := context.Background()
+	users := cache.New[int, user](newStore(t), cache.WithKeyBuilder[int, user](cache.KeyPrefix[int]("user")))
+
+	var loads atomic.Int32
+
+	loader := func(_ context.Context, id int) (user, error) {
+		loads.Add(1)
+
+		return user{ID: id, Name: "john"}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		got, err := users.GetOrLoad(ctx, 1, loader)
+		require.NoError(t, err)
+		assert.Equal(t, user{ID: 1, Name: "john"}, got)
+	}
+
+	assert.Equal(t, int32(1), loads.Load())
+	assert.Equal(t, cache.Stats{Hits: 2, Misses: 1, Loads: 1}, users.Stats())
+
+	require.NoError(t, users.Delete(ctx, 1))
+
+	_, err := users.Get(ctx, 1)
+	assert.ErrorIs(t, err, memorystore.ErrKeyNotFound)
+}
+
+func TestCache_GetOrLoad_singleflight(t *testing.T) {
+	ctx := context.Background()
+	users := cache.New[int, user](newStore(t))
+
+	var loads atomic.Int32
+
+	release := make(chan struct{})
+	loader := func(_ context.Context, id int) (user, error) {
+		loads.Add(1)
+		<-release
+
+		return user{ID: id}, nil
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			got, err := users.GetOrLoad(ctx, 7, loader)
+			assert.NoError(t, err)
+			assert.Equal(t, 7, got.ID)
+		}()
+	}
+
+	// Загрузка не завершится, пока все вызывающие не обнаружат отсутствие значения и не присоединятся к ней.
+	require.Eventually(t, func() bool { return users.Stats().Misses == 10 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+}
+
+func TestCache_GetOrLoad_negative(t *testing.T) {
+	ctx := context.Background()
+	users := cache.New[int, user](newStore(t), cache.WithNegativeTTL[int, user](time.Minute))
+
+	var loads atomic.Int32
+
+	loader := func(context.Context, int) (user, error) {
+		loads.Add(1)
+
+		return user{}, errs.Wrapf(errs.ErrNotFound, "user not found")
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := users.GetOrLoad(ctx, 1, loader)
+		assert.True(t, errs.ErrNotFound.Is(err))
+	}
+
+	assert.Equal(t, int32(1), loads.Load())
+	assert.Equal(t, uint64(1), users.Stats().NegativeHits)
+
+	failure := errors.New("db is down")
+	_, err := users.GetOrLoad(ctx, 2, func(context.Context, int) (user, error) { return user{}, failure })
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, uint64(1), users.Stats().LoadErrors)
+}
+
+func TestCache_GetOrLoad_earlyRefresh(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	tests := []struct {
+		name      string
+		elapsed   time.Duration
+		random    float64
+		wantLoads int32
+	}{
+		{name: "Far from expiry", elapsed: time.Second, random: 0.5, wantLoads: 1},
+		{name: "Close to expiry", elapsed: 59500 * time.Millisecond, random: 0.5, wantLoads: 2},
+		{name: "Unlucky close to expiry", elapsed: 59500 * time.Millisecond, random: 0.999, wantLoads: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := now
+
+			var loads atomic.Int32
+
+			loader := func(_ context.Context, id int) (user, error) {
+				loads.Add(1)
+				now = now.Add(time.Second) // Загрузка занимает секунду.
+
+				return user{ID: id}, nil
+			}
+
+			users := cache.New[int, user](newStore(t),
+				cache.WithTTL[int, user](time.Minute),
+				cache.WithClock[int, user](clock),
+				cache.WithRandom[int, user](func() float64 { return tt.random }),
+			)
+
+			_, err := users.GetOrLoad(ctx, 1, loader)
+			require.NoError(t, err)
+
+			now = started.Add(time.Second + tt.elapsed)
+
+			_, err = users.GetOrLoad(ctx, 1, loader)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantLoads, loads.Load())
+		})
+	}
+}
+
+func TestCache_GetOrLoad_earlyRefreshFailure(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	users := cache.New[int, user](newStore(t),
+		cache.WithTTL[int, user](time.Minute),
+		cache.WithClock[int, user](func() time.Time { return now }),
+		cache.WithRandom[int, user](func() float64 { return 0.5 }),
+	)
+
+	_, err := users.GetOrLoad(ctx, 1, func(_ context.Context, id int) (user, error) {
+		now = now.Add(time.Second) // Загрузка занимает секунду.
+
+		return user{ID: id, Name: "john"}, nil
+	})
+	require.NoError(t, err)
+
+	now = now.Add(59500 * time.Millisecond)
+
+	got, err := users.GetOrLoad(ctx, 1, func(context.Context, int) (user, error) {
+		return user{}, errors.New("db is down")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, user{ID: 1, Name: "john"}, got)
+	assert.Equal(t, cache.Stats{Misses: 1, Loads: 2, LoadErrors: 1, EarlyRefreshes: 1}, users.Stats())
+}
+
+func TestCache_GetOrLoad_panic(t *testing.T) {
+	ctx := context.Background()
+	users := cache.New[int, user](newStore(t))
+
+	_, err := users.GetOrLoad(ctx, 1, func(context.Context, int) (user, error) {
+		panic("boom")
+	})
+	assert.ErrorIs(t, err, cache.ErrLoaderPanics)
+	assert.Equal(t, cache.Stats{Misses: 1, Loads: 1, LoadErrors: 1}, users.Stats())
+
+	got, err := users.GetOrLoad(ctx, 1, func(_ context.Context, id int) (user, error) {
+		return user{ID: id}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, user{ID: 1}, got)
+}
+
+func TestKeyHash(t *testing.T) {
+	type query struct {
+		Name  string
+		Limit int
+	}
+
+	build := cache.KeyHash[query]("search")
+
+	assert.Equal(t, build(query{Name: "a", Limit: 10}), build(query{Name: "a", Limit: 10}))
+	assert.NotEqual(t, build(query{Name: "a", Limit: 10}), build(query{Name: "a", Limit: 20}))
+	assert.Regexp(t, "^search:[0-9a-f]{64}$", build(query{}))
+}
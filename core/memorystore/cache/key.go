@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// KeyBuilder формирует ключ хранилища по ключу кэша.
+type KeyBuilder[K any] func(key K) string
+
+// KeyPrefix возвращает KeyBuilder, формирующий ключ хранилища из префикса и строкового представления ключа (fmt.Sprint):
+// "prefix:key". Подходит для строковых, числовых ключей и UUID.
+func KeyPrefix[K any](prefix string) KeyBuilder[K] {
+	return func(key K) string {
+		return prefix + ":" + fmt.Sprint(key)
+	}
+}
+
+// KeyHash возвращает KeyBuilder, формирующий ключ хранилища из префикса и хеша SHA-256 JSON представления ключа:
+// "prefix:hash". Подходит для составных ключей, например, структур с параметрами запроса.
+func KeyHash[K any](prefix string) KeyBuilder[K] {
+	return func(key K) string {
+		data, err := json.Marshal(key)
+		if err != nil {
+			data = []byte(fmt.Sprintf("%#v", key))
+		}
+
+		sum := sha256.Sum256(data)
+
+		return prefix + ":" + hex.EncodeToString(sum[:])
+	}
+}
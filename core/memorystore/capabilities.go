@@ -0,0 +1,66 @@
+package memorystore
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// NoExpiration время жизни ключа без срока истечения, см. TTLReader.
+const NoExpiration time.Duration = -1
+
+var ErrNotInteger = errors.New("value is not an integer") // Значение ключа не является целым числом.
+
+// Необязательные возможности хранилища. Реализация MemoryStore может поддерживать любое их подмножество, поддержка
+// проверяется приведением типа:
+//
+//	if counter, ok := store.(memorystore.Counter); ok {
+//		attempts, err := counter.Increment(ctx, key, 1, time.Hour)
+//		...
+//	}
+type (
+	// Counter атомарные целочисленные счетчики. Значение счетчика хранится как десятичное число, поэтому его можно
+	// прочитать через Value.Int, но нельзя предварительно записывать через Set.
+	Counter interface {
+		// Increment атомарно увеличивает значение ключа на delta и возвращает новое значение. Отсутствующий ключ
+		// создается со значением delta и временем жизни expiration, время жизни существующего ключа не меняется.
+		Increment(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, error)
+
+		// Decrement атомарно уменьшает значение ключа на delta, аналогично Increment.
+		Decrement(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, error)
+	}
+
+	// ConditionalSetter условная запись значений.
+	ConditionalSetter interface {
+		// SetIfNotExists записывает значение, только если ключа нет. Вернет true, если значение записано.
+		SetIfNotExists(ctx context.Context, key string, value any, expiration time.Duration) (bool, error)
+	}
+
+	// CompareAndSwapper замена значения с проверкой текущего.
+	CompareAndSwapper interface {
+		// CompareAndSwap атомарно заменяет значение ключа на value, если текущее значение совпадает с old (например,
+		// полученным через Get). Вернет false, если ключ отсутствует или его значение изменилось.
+		CompareAndSwap(ctx context.Context, key string, old *Value, value any, expiration time.Duration) (bool, error)
+	}
+
+	// Expirer изменение времени жизни ключей.
+	Expirer interface {
+		// Expire устанавливает время жизни ключа, неположительное expiration (например, NoExpiration) делает ключ
+		// бессрочным. Вернет false, если ключ отсутствует.
+		Expire(ctx context.Context, key string, expiration time.Duration) (bool, error)
+	}
+
+	// TTLReader чтение оставшегося времени жизни ключей.
+	TTLReader interface {
+		// TTL возвращает оставшееся время жизни ключа или NoExpiration для бессрочного ключа. Если ключ не найден,
+		// возвращается ошибка ErrKeyNotFound.
+		TTL(ctx context.Context, key string) (time.Duration, error)
+	}
+
+	// Scanner перебор ключей.
+	Scanner interface {
+		// ScanPrefix вызывает fn для каждого ключа, начинающегося с prefix, и прекращает перебор при первой ошибке
+		// fn. Ключи, измененные во время перебора, могут быть пропущены или переданы повторно.
+		ScanPrefix(ctx context.Context, prefix string, fn func(key string) error) error
+	}
+)
@@ -0,0 +1,145 @@
+package memorystore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/wal1251/pkg/tools/serial"
+)
+
+var (
+	ErrUnsupportedValue = errors.New("unsupported value type") // Кодек не поддерживает тип значения.
+
+	_ Codec = JSONCodec{}
+	_ Codec = GobCodec{}
+	_ Codec = RawCodec{}
+	_ Codec = (*SerialCodec[any])(nil)
+)
+
+type (
+	// Codec сериализует значения хранилища. Клиенты хранилищ используют Codec при записи значений, а Value - при их
+	// чтении.
+	Codec interface {
+		// Marshal сериализует значение.
+		Marshal(value any) ([]byte, error)
+
+		// Unmarshal десериализует данные в dst, dst должен быть указателем.
+		Unmarshal(data []byte, dst any) error
+	}
+
+	// JSONCodec кодек JSON, используется клиентами хранилищ по умолчанию.
+	JSONCodec struct{}
+
+	// GobCodec кодек encoding/gob. Значение десериализуется только в указатель на конкретный тип: gob не сохраняет
+	// тип значения, поэтому Unmarshal в *any завершится ошибкой.
+	GobCodec struct{}
+
+	// RawCodec кодек без сериализации: записывает []byte и string как есть и читает их в *[]byte, *string или *any
+	// (как []byte). Другие типы значений приводят к ошибке ErrUnsupportedValue.
+	RawCodec struct{}
+
+	// SerialCodec кодек на базе кодировщика и декодировщика пакета serial для значений типа T. Unmarshal принимает
+	// *T или *any.
+	SerialCodec[T any] struct {
+		encoder serial.Encoder[T]
+		decoder serial.Decoder[T]
+	}
+)
+
+// Marshal см. Codec.
+func (JSONCodec) Marshal(value any) ([]byte, error) {
+	return serial.ToBytes(value, serial.JSONEncode[any])
+}
+
+// Unmarshal см. Codec.
+func (JSONCodec) Unmarshal(data []byte, dst any) error {
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(dst); err != nil {
+		return fmt.Errorf("can't deserialize from JSON: %w", err)
+	}
+
+	return nil
+}
+
+// Marshal см. Codec.
+func (GobCodec) Marshal(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, fmt.Errorf("can't serialize to gob: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal см. Codec.
+func (GobCodec) Unmarshal(data []byte, dst any) error {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(dst); err != nil {
+		return fmt.Errorf("can't deserialize from gob: %w", err)
+	}
+
+	return nil
+}
+
+// Marshal см. Codec.
+func (RawCodec) Marshal(value any) ([]byte, error) {
+	switch typed := value.(type) {
+	case []byte:
+		return typed, nil
+	case string:
+		return []byte(typed), nil
+	default:
+		return nil, fmt.Errorf("%w: raw codec can't marshal %T", ErrUnsupportedValue, value)
+	}
+}
+
+// Unmarshal см. Codec.
+func (RawCodec) Unmarshal(data []byte, dst any) error {
+	switch typed := dst.(type) {
+	case *[]byte:
+		*typed = append([]byte(nil), data...)
+	case *string:
+		*typed = string(data)
+	case *any:
+		*typed = append([]byte(nil), data...)
+	default:
+		return fmt.Errorf("%w: raw codec can't unmarshal to %T", ErrUnsupportedValue, dst)
+	}
+
+	return nil
+}
+
+// Marshal см. Codec.
+func (c *SerialCodec[T]) Marshal(value any) ([]byte, error) {
+	typed, ok := value.(T)
+	if !ok {
+		return nil, fmt.Errorf("%w: serial codec can't marshal %T", ErrUnsupportedValue, value)
+	}
+
+	return serial.ToBytes(typed, c.encoder)
+}
+
+// Unmarshal см. Codec.
+func (c *SerialCodec[T]) Unmarshal(data []byte, dst any) error {
+	value, err := serial.FromBytes(data, c.decoder)
+	if err != nil {
+		return err
+	}
+
+	switch typed := dst.(type) {
+	case *T:
+		*typed = value
+	case *any:
+		*typed = value
+	default:
+		return fmt.Errorf("%w: serial codec can't unmarshal to %T", ErrUnsupportedValue, dst)
+	}
+
+	return nil
+}
+
+// NewSerialCodec возвращает кодек значений типа T на базе кодировщика и декодировщика пакета serial.
+func NewSerialCodec[T any](encoder serial.Encoder[T], decoder serial.Decoder[T]) *SerialCodec[T] {
+	return &SerialCodec[T]{encoder: encoder, decoder: decoder}
+}
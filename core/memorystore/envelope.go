@@ -0,0 +1,314 @@
+package memorystore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Форматы значений EnvelopeCodec.
+const (
+	FormatJSON Format = iota + 1 // JSONCodec.
+	FormatGob                    // GobCodec.
+	FormatRaw                    // RawCodec.
+)
+
+const (
+	envelopeMagic   byte = 0xfe // Первый байт заголовка, не встречается в начале JSON и десятичных чисел.
+	envelopeVersion byte = 1    // Версия заголовка.
+	envelopeSize         = 4    // Размер заголовка: признак, версия, формат, флаги.
+
+	flagCompressed byte = 1 // Значение сжато gzip.
+	flagEncrypted  byte = 2 // Значение зашифровано AES-GCM.
+
+	DefaultMaxDecompressedSize = 64 << 20 // Максимальный размер значения после распаковки по умолчанию, 64 МиБ.
+)
+
+var (
+	ErrInvalidCodec  = errors.New("invalid codec config")          // Некорректная конфигурация кодека.
+	ErrInvalidFormat = errors.New("invalid value format")          // Значение записано в неизвестном формате.
+	ErrDecryption    = errors.New("can't decrypt stored value")    // Значение зашифровано неизвестным ключом.
+	ErrNotEncrypted  = errors.New("stored value is not encrypted") // Значение не зашифровано, см. WithRequireEncryption.
+
+	_ Codec = (*EnvelopeCodec)(nil)
+)
+
+type (
+	// Format идентификатор формата сериализации, записывается в заголовок значения.
+	Format byte
+
+	// EnvelopeCodec кодек, записывающий перед значением заголовок из 4 байт: признак, версию заголовка, формат
+	// сериализации и флаги сжатия и шифрования. Значение сериализуется кодеком выбранного формата, сжимается gzip, если
+	// его размер превышает порог, и шифруется AES-GCM, если задан ключ.
+	//
+	// Значение читается по его заголовку, а не по текущим настройкам кодека, поэтому формат, сжатие и шифрование можно
+	// менять без очистки хранилища: прежние значения останутся читаемыми. Значения без заголовка (записанные JSONCodec
+	// или счетчиками Counter) читаются как JSON.
+	//
+	// Поэтому и при включенном шифровании открытые значения читаются без ошибок: имеющий доступ на запись в хранилище
+	// может подменить зашифрованное значение открытым (downgrade). Если все значения хранилища шифруются, отклоняйте
+	// открытые значения опцией WithRequireEncryption.
+	//
+	// Шифрование аутентифицирует только заголовок и само значение, но не ключ хранилища: кодек не знает, по какому
+	// ключу записывается значение. Имеющий доступ на запись в хранилище может скопировать зашифрованное значение из
+	// одного ключа в другой, и оно будет успешно прочитано. Если это недопустимо, сохраняйте ключ внутри значения и
+	// сверяйте его при чтении.
+	EnvelopeCodec struct {
+		format            Format
+		codecs            map[Format]Codec
+		threshold         int
+		maxSize           int
+		keys              []cipher.AEAD
+		requireEncryption bool
+	}
+
+	// EnvelopeOption опция EnvelopeCodec.
+	EnvelopeOption func(*EnvelopeCodec) error
+)
+
+// Marshal см. Codec.
+func (c *EnvelopeCodec) Marshal(value any) ([]byte, error) {
+	data, err := c.codecs[c.format].Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	header := []byte{envelopeMagic, envelopeVersion, byte(c.format), 0}
+
+	if c.threshold > 0 && len(data) > c.threshold {
+		if data, err = compress(data); err != nil {
+			return nil, err
+		}
+
+		header[3] |= flagCompressed
+	}
+
+	if len(c.keys) == 0 {
+		return append(header, data...), nil
+	}
+
+	header[3] |= flagEncrypted
+	aead := c.keys[0]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("can't generate nonce: %w", err)
+	}
+
+	result := make([]byte, 0, envelopeSize+len(nonce)+len(data)+aead.Overhead())
+	result = append(append(result, header...), nonce...)
+
+	// Заголовок аутентифицируется вместе с данными, чтобы подмена флагов или формата обнаруживалась при чтении.
+	return aead.Seal(result, nonce, data, header), nil
+}
+
+// Unmarshal см. Codec.
+func (c *EnvelopeCodec) Unmarshal(data []byte, dst any) error {
+	if len(data) == 0 || data[0] != envelopeMagic {
+		if c.requireEncryption {
+			return ErrNotEncrypted
+		}
+
+		return JSONCodec{}.Unmarshal(data, dst)
+	}
+
+	if len(data) < envelopeSize || data[1] != envelopeVersion {
+		return fmt.Errorf("%w: unknown header", ErrInvalidFormat)
+	}
+
+	header, payload := data[:envelopeSize], data[envelopeSize:]
+
+	codec, ok := c.codecs[Format(header[2])]
+	if !ok {
+		return fmt.Errorf("%w: unknown format %d", ErrInvalidFormat, header[2])
+	}
+
+	var err error
+
+	switch {
+	case header[3]&flagEncrypted != 0:
+		if payload, err = c.decrypt(header, payload); err != nil {
+			return err
+		}
+	case c.requireEncryption:
+		return ErrNotEncrypted
+	}
+
+	if header[3]&flagCompressed != 0 {
+		if payload, err = decompress(payload, c.maxSize); err != nil {
+			return err
+		}
+	}
+
+	return codec.Unmarshal(payload, dst)
+}
+
+func (c *EnvelopeCodec) decrypt(header, payload []byte) ([]byte, error) {
+	for _, aead := range c.keys {
+		if len(payload) < aead.NonceSize() {
+			break
+		}
+
+		nonce, ciphertext := payload[:aead.NonceSize()], payload[aead.NonceSize():]
+		if data, err := aead.Open(nil, nonce, ciphertext, header); err == nil {
+			return data, nil
+		}
+	}
+
+	return nil, ErrDecryption
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("can't compress value: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("can't compress value: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decompress распаковывает значение размером не более maxSize байт, чтобы небольшое сжатое значение не заняло всю
+// память при распаковке.
+func decompress(data []byte, maxSize int) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("can't decompress value: %w", err)
+	}
+	defer reader.Close()
+
+	result, err := io.ReadAll(io.LimitReader(reader, int64(maxSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("can't decompress value: %w", err)
+	}
+
+	if len(result) > maxSize {
+		return nil, fmt.Errorf("%w: decompressed value exceeds %d bytes", ErrInvalidFormat, maxSize)
+	}
+
+	return result, nil
+}
+
+// WithFormat устанавливает формат записи значений, по умолчанию FormatJSON. Для собственного формата (например,
+// SerialCodec) необходимо указать codec и идентификатор, не совпадающий со встроенными форматами; для встроенных
+// форматов codec может быть nil.
+func WithFormat(format Format, codec Codec) EnvelopeOption {
+	return func(c *EnvelopeCodec) error {
+		if codec != nil {
+			c.codecs[format] = codec
+		}
+
+		if _, ok := c.codecs[format]; !ok {
+			return fmt.Errorf("%w: unknown format %d", ErrInvalidCodec, format)
+		}
+
+		c.format = format
+
+		return nil
+	}
+}
+
+// WithDecoder регистрирует кодек формата, значения которого нужно только читать, например, после смены формата
+// записи.
+func WithDecoder(format Format, codec Codec) EnvelopeOption {
+	return func(c *EnvelopeCodec) error {
+		c.codecs[format] = codec
+
+		return nil
+	}
+}
+
+// WithCompression включает сжатие gzip значений, размер которых после сериализации превышает threshold байт.
+func WithCompression(threshold int) EnvelopeOption {
+	return func(c *EnvelopeCodec) error {
+		if threshold <= 0 {
+			return fmt.Errorf("%w: compression threshold must be positive", ErrInvalidCodec)
+		}
+
+		c.threshold = threshold
+
+		return nil
+	}
+}
+
+// WithMaxDecompressedSize устанавливает максимальный размер значения после распаковки, по умолчанию
+// DefaultMaxDecompressedSize. Значения большего размера не читаются и возвращают ошибку ErrInvalidFormat.
+func WithMaxDecompressedSize(size int) EnvelopeOption {
+	return func(c *EnvelopeCodec) error {
+		if size <= 0 {
+			return fmt.Errorf("%w: max decompressed size must be positive", ErrInvalidCodec)
+		}
+
+		c.maxSize = size
+
+		return nil
+	}
+}
+
+// WithRequireEncryption запрещает чтение незашифрованных значений: значений без заголовка и значений без признака
+// шифрования. Такие значения возвращают ошибку ErrNotEncrypted. Требует WithEncryption.
+func WithRequireEncryption() EnvelopeOption {
+	return func(c *EnvelopeCodec) error {
+		c.requireEncryption = true
+
+		return nil
+	}
+}
+
+// WithEncryption включает шифрование значений AES-GCM ключом key длиной 16, 24 или 32 байта. Значения, зашифрованные
+// прежними ключами previous, остаются читаемыми, что позволяет выполнять ротацию ключей без очистки хранилища.
+func WithEncryption(key []byte, previous ...[]byte) EnvelopeOption {
+	return func(c *EnvelopeCodec) error {
+		for _, k := range append([][]byte{key}, previous...) {
+			block, err := aes.NewCipher(k)
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidCodec, err)
+			}
+
+			aead, err := cipher.NewGCM(block)
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidCodec, err)
+			}
+
+			c.keys = append(c.keys, aead)
+		}
+
+		return nil
+	}
+}
+
+// NewEnvelopeCodec возвращает новый EnvelopeCodec. Встроенные форматы FormatJSON, FormatGob и FormatRaw доступны для
+// чтения всегда.
+func NewEnvelopeCodec(opts ...EnvelopeOption) (*EnvelopeCodec, error) {
+	codec := &EnvelopeCodec{
+		format: FormatJSON,
+		codecs: map[Format]Codec{
+			FormatJSON: JSONCodec{},
+			FormatGob:  GobCodec{},
+			FormatRaw:  RawCodec{},
+		},
+		maxSize: DefaultMaxDecompressedSize,
+	}
+
+	for _, opt := range opts {
+		if err := opt(codec); err != nil {
+			return nil, err
+		}
+	}
+
+	if codec.requireEncryption && len(codec.keys) == 0 {
+		return nil, fmt.Errorf("%w: encryption is required, but no key is set", ErrInvalidCodec)
+	}
+
+	return codec, nil
+}
@@ -0,0 +1,195 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/tools/serial"
+)
+
+var (
+	_ memorystore.Counter           = (*Store)(nil)
+	_ memorystore.ConditionalSetter = (*Store)(nil)
+	_ memorystore.CompareAndSwapper = (*Store)(nil)
+	_ memorystore.Expirer           = (*Store)(nil)
+	_ memorystore.TTLReader         = (*Store)(nil)
+	_ memorystore.Scanner           = (*Store)(nil)
+)
+
+// Increment см. memorystore.Counter. Вернет ошибку memorystore.ErrNotInteger, если значение ключа не является целым
+// числом.
+func (s *Store) Increment(_ context.Context, key string, delta int64, expiration time.Duration) (int64, error) {
+	var value int64
+
+	now := s.now()
+	err := s.shard(key).update(key, now, func(current *entry) (*entry, error) {
+		e := &entry{key: key, expiresAt: s.expiresAt(now, expiration)}
+		if current != nil {
+			number, err := strconv.ParseInt(string(bytes.TrimSpace(current.value)), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("can't increment key %s: %w", key, memorystore.ErrNotInteger)
+			}
+
+			value, e.expiresAt = number, current.expiresAt
+		}
+
+		value += delta
+		e.value = strconv.AppendInt(nil, value, 10)
+
+		return e, nil
+	})
+
+	return value, err
+}
+
+// Decrement см. memorystore.Counter.
+func (s *Store) Decrement(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, error) {
+	return s.Increment(ctx, key, -delta, expiration)
+}
+
+// SetIfNotExists см. memorystore.ConditionalSetter.
+func (s *Store) SetIfNotExists(_ context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	data, err := serial.ToBytes(value, serial.JSONEncode[any])
+	if err != nil {
+		return false, err
+	}
+
+	var set bool
+
+	now := s.now()
+	err = s.shard(key).update(key, now, func(current *entry) (*entry, error) {
+		if current != nil {
+			return nil, nil
+		}
+
+		set = true
+
+		return &entry{key: key, value: data, expiresAt: s.expiresAt(now, expiration)}, nil
+	})
+
+	return set, err
+}
+
+// CompareAndSwap см. memorystore.CompareAndSwapper.
+func (s *Store) CompareAndSwap(_ context.Context, key string, old *memorystore.Value, value any, expiration time.Duration) (bool, error) {
+	data, err := serial.ToBytes(value, serial.JSONEncode[any])
+	if err != nil {
+		return false, err
+	}
+
+	var swapped bool
+
+	now := s.now()
+	err = s.shard(key).update(key, now, func(current *entry) (*entry, error) {
+		if current == nil || !bytes.Equal(current.value, old.RawBytes()) {
+			return nil, nil
+		}
+
+		swapped = true
+
+		return &entry{key: key, value: data, expiresAt: s.expiresAt(now, expiration)}, nil
+	})
+
+	return swapped, err
+}
+
+// Expire см. memorystore.Expirer.
+func (s *Store) Expire(_ context.Context, key string, expiration time.Duration) (bool, error) {
+	now := s.now()
+	sh := s.shard(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	e := sh.lookup(key, now)
+	if e == nil {
+		return false, nil
+	}
+
+	e.expiresAt = s.expiresAt(now, expiration)
+
+	return true, nil
+}
+
+// TTL см. memorystore.TTLReader.
+func (s *Store) TTL(_ context.Context, key string) (time.Duration, error) {
+	now := s.now()
+	sh := s.shard(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	e := sh.lookup(key, now)
+	if e == nil {
+		return 0, memorystore.ErrKeyNotFound
+	}
+
+	if e.expiresAt.IsZero() {
+		return memorystore.NoExpiration, nil
+	}
+
+	return e.expiresAt.Sub(now), nil
+}
+
+// ScanPrefix см. memorystore.Scanner. Функция fn вызывается вне блокировок, поэтому может обращаться к хранилищу.
+func (s *Store) ScanPrefix(ctx context.Context, prefix string, fn func(key string) error) error {
+	now := s.now()
+
+	for _, sh := range s.shards {
+		if err := ctx.Err(); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		var keys []string
+
+		sh.mu.Lock()
+		for key, e := range sh.items {
+			if strings.HasPrefix(key, prefix) && !e.expired(now) {
+				keys = append(keys, key)
+			}
+		}
+		sh.mu.Unlock()
+
+		for _, key := range keys {
+			if err := fn(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) expiresAt(now time.Time, expiration time.Duration) time.Time {
+	if expiration > 0 {
+		return now.Add(expiration)
+	}
+
+	return time.Time{}
+}
+
+// update атомарно заменяет значение ключа результатом fn, которой передается текущий действующий ключ или nil. Если
+// fn вернет nil, ключ не изменится.
+func (sh *shard) update(key string, now time.Time, fn func(current *entry) (*entry, error)) error {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	e, err := fn(sh.lookup(key, now))
+	if err != nil || e == nil {
+		return err
+	}
+
+	size := e.size()
+	if sh.maxBytes > 0 && size > sh.maxBytes {
+		return fmt.Errorf("can't set key %s of %d bytes: %w", key, size, ErrValueTooLarge)
+	}
+
+	sh.store(e, size)
+
+	return nil
+}
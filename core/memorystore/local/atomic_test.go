@@ -0,0 +1,160 @@
+package local_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/core/memorystore/local"
+)
+
+func TestStore_Counter(t *testing.T) {
+	ctx := context.Background()
+	now := &clock{now: time.Now()}
+	store := newStore(t, &local.Config{}, local.WithClock(now.Now))
+
+	value, err := store.Increment(ctx, "counter", 5, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+
+	value, err = store.Decrement(ctx, "counter", 7, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-2), value)
+
+	stored, err := store.Get(ctx, "counter")
+	require.NoError(t, err)
+	number, err := stored.Int()
+	require.NoError(t, err)
+	assert.Equal(t, -2, number)
+
+	ttl, err := store.TTL(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, ttl)
+
+	now.now = now.now.Add(time.Minute)
+	value, err = store.Increment(ctx, "counter", 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+
+	require.NoError(t, store.Set(ctx, "text", "value", 0))
+	_, err = store.Increment(ctx, "text", 1, 0)
+	assert.ErrorIs(t, err, memorystore.ErrNotInteger)
+}
+
+func TestStore_Counter_concurrent(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t, &local.Config{Shards: 4})
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := store.Increment(ctx, "counter", 1, 0)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	value, err := store.Increment(ctx, "counter", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), value)
+}
+
+func TestStore_conditional(t *testing.T) {
+	ctx := context.Background()
+	now := &clock{now: time.Now()}
+	store := newStore(t, &local.Config{}, local.WithClock(now.Now))
+
+	set, err := store.SetIfNotExists(ctx, "key", "first", time.Second)
+	require.NoError(t, err)
+	assert.True(t, set)
+
+	set, err = store.SetIfNotExists(ctx, "key", "second", 0)
+	require.NoError(t, err)
+	assert.False(t, set)
+
+	old, err := store.Get(ctx, "key")
+	require.NoError(t, err)
+
+	swapped, err := store.CompareAndSwap(ctx, "key", old, "third", 0)
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	swapped, err = store.CompareAndSwap(ctx, "key", old, "fourth", 0)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	swapped, err = store.CompareAndSwap(ctx, "missing", old, "fourth", 0)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	current, err := store.Get(ctx, "key")
+	require.NoError(t, err)
+	value, err := current.String()
+	require.NoError(t, err)
+	assert.Equal(t, "third", value)
+}
+
+func TestStore_Expire(t *testing.T) {
+	ctx := context.Background()
+	now := &clock{now: time.Now()}
+	store := newStore(t, &local.Config{}, local.WithClock(now.Now))
+
+	require.NoError(t, store.Set(ctx, "key", 1, 0))
+
+	ttl, err := store.TTL(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, memorystore.NoExpiration, ttl)
+
+	ok, err := store.Expire(ctx, "key", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	now.now = now.now.Add(time.Second)
+	ttl, err = store.TTL(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, 59*time.Second, ttl)
+
+	now.now = now.now.Add(time.Minute)
+	_, err = store.TTL(ctx, "key")
+	require.ErrorIs(t, err, memorystore.ErrKeyNotFound)
+
+	ok, err = store.Expire(ctx, "key", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestStore_ScanPrefix(t *testing.T) {
+	ctx := context.Background()
+	now := &clock{now: time.Now()}
+	store := newStore(t, &local.Config{Shards: 4}, local.WithClock(now.Now))
+
+	require.NoError(t, store.Set(ctx, "user:1", 1, 0))
+	require.NoError(t, store.Set(ctx, "user:2", 2, 0))
+	require.NoError(t, store.Set(ctx, "user:3", 3, time.Second))
+	require.NoError(t, store.Set(ctx, "order:1", 1, 0))
+
+	now.now = now.now.Add(time.Second)
+
+	var keys []string
+	require.NoError(t, store.ScanPrefix(ctx, "user:", func(key string) error {
+		keys = append(keys, key)
+
+		return nil
+	}))
+	sort.Strings(keys)
+	assert.Equal(t, []string{"user:1", "user:2"}, keys)
+
+	errStop := errors.New("stop")
+	assert.ErrorIs(t, store.ScanPrefix(ctx, "user:", func(string) error { return errStop }), errStop)
+}
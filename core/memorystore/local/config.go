@@ -0,0 +1,49 @@
+package local
+
+import (
+	"time"
+
+	"github.com/wal1251/pkg/core/cfg"
+	"github.com/wal1251/pkg/core/memorystore"
+)
+
+const (
+	CfgKeyMaxItems           cfg.Key = "MEMORYSTORE_LOCAL_MAX_ITEMS"             // Максимальное количество ключей (int).
+	CfgKeyMaxBytes           cfg.Key = "MEMORYSTORE_LOCAL_MAX_BYTES"             // Максимальный объем ключей и значений в байтах (int).
+	CfgKeyEviction           cfg.Key = "MEMORYSTORE_LOCAL_EVICTION"              // Политика вытеснения: lru или lfu (string).
+	CfgKeyShards             cfg.Key = "MEMORYSTORE_LOCAL_SHARDS"                // Количество сегментов хранилища (int).
+	CfgKeyCleanupInterval    cfg.Key = "MEMORYSTORE_LOCAL_CLEANUP_INTERVAL"      // Интервал удаления истекших ключей (duration).
+	CfgKeyMaxBulkRequestSize cfg.Key = "MEMORYSTORE_LOCAL_MAX_BULK_REQUEST_SIZE" // Максимальное число ключей в запросе (int).
+
+	EvictionLRU = "lru" // Вытесняются давно не использованные ключи.
+	EvictionLFU = "lfu" // Вытесняются редко используемые ключи.
+
+	CfgDefaultMaxItems           = 100_000                               // Максимальное количество ключей по умолчанию.
+	CfgDefaultMaxBytes           = 0                                     // Объем не ограничен по умолчанию.
+	CfgDefaultEviction           = EvictionLRU                           // Политика вытеснения по умолчанию.
+	CfgDefaultShards             = 16                                    // Количество сегментов по умолчанию.
+	CfgDefaultCleanupInterval    = time.Minute                           // Интервал удаления истекших ключей по умолчанию.
+	CfgDefaultMaxBulkRequestSize = memorystore.DefaultMaxBulkRequestSize // Максимальное число ключей в запросе по умолчанию.
+)
+
+// Config конфигурация хранилища в памяти процесса.
+type Config struct {
+	MaxItems           int           // Максимальное количество ключей, 0 - не ограничено. Делится между сегментами с округлением вниз.
+	MaxBytes           int           // Максимальный объем ключей и значений в байтах, 0 - не ограничен. Делится так же, как MaxItems.
+	Eviction           string        // Политика вытеснения при превышении ограничений: EvictionLRU или EvictionLFU.
+	Shards             int           // Количество сегментов с отдельными блокировками, округляется до степени двойки, но не больше MaxItems и MaxBytes.
+	CleanupInterval    time.Duration // Интервал удаления истекших ключей в фоне, 0 - только при обращении к ключу.
+	MaxBulkRequestSize int           // Максимальное число ключей в запросах GetList и Delete.
+}
+
+// DefaultConfig возвращает конфигурацию по умолчанию.
+func DefaultConfig() *Config {
+	return &Config{
+		MaxItems:           CfgDefaultMaxItems,
+		MaxBytes:           CfgDefaultMaxBytes,
+		Eviction:           CfgDefaultEviction,
+		Shards:             CfgDefaultShards,
+		CleanupInterval:    CfgDefaultCleanupInterval,
+		MaxBulkRequestSize: CfgDefaultMaxBulkRequestSize,
+	}
+}
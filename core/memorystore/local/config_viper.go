@@ -0,0 +1,20 @@
+package local
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/wal1251/pkg/core/cfg"
+	"github.com/wal1251/pkg/core/cfg/viperx"
+)
+
+// CfgFromViper загрузка конфига Config с помощью viper.
+func CfgFromViper(loader *viper.Viper, keyMapping ...cfg.KeyMap) *Config {
+	return &Config{
+		MaxItems:           viperx.Get(loader, CfgKeyMaxItems.Map(keyMapping...), CfgDefaultMaxItems),
+		MaxBytes:           viperx.Get(loader, CfgKeyMaxBytes.Map(keyMapping...), CfgDefaultMaxBytes),
+		Eviction:           viperx.Get(loader, CfgKeyEviction.Map(keyMapping...), CfgDefaultEviction),
+		Shards:             viperx.Get(loader, CfgKeyShards.Map(keyMapping...), CfgDefaultShards),
+		CleanupInterval:    viperx.Get(loader, CfgKeyCleanupInterval.Map(keyMapping...), CfgDefaultCleanupInterval),
+		MaxBulkRequestSize: viperx.Get(loader, CfgKeyMaxBulkRequestSize.Map(keyMapping...), CfgDefaultMaxBulkRequestSize),
+	}
+}
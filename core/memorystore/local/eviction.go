@@ -0,0 +1,107 @@
+package local
+
+import "container/list"
+
+type (
+	// evictionPolicy порядок вытеснения ключей сегмента. Методы вызываются под блокировкой сегмента.
+	evictionPolicy interface {
+		add(e *entry)
+		touch(e *entry)
+		remove(e *entry)
+		victim() *entry
+	}
+
+	// lruPolicy вытесняет ключ, к которому дольше всего не обращались.
+	lruPolicy struct {
+		order *list.List
+	}
+
+	// lfuPolicy вытесняет ключ с наименьшим числом обращений, среди них - давно не использованный. Ключи хранятся в
+	// списках по частоте обращений, поэтому все операции выполняются за O(1).
+	lfuPolicy struct {
+		buckets map[int]*list.List
+		minFreq int
+	}
+)
+
+func (p *lruPolicy) add(e *entry) {
+	e.element = p.order.PushFront(e)
+}
+
+func (p *lruPolicy) touch(e *entry) {
+	p.order.MoveToFront(e.element)
+}
+
+func (p *lruPolicy) remove(e *entry) {
+	p.order.Remove(e.element)
+}
+
+func (p *lruPolicy) victim() *entry {
+	if back := p.order.Back(); back != nil {
+		return back.Value.(*entry) //nolint:forcetypeassert
+	}
+
+	return nil
+}
+
+func (p *lfuPolicy) add(e *entry) {
+	e.freq = 1
+	e.element = p.bucket(1).PushFront(e)
+	p.minFreq = 1
+}
+
+func (p *lfuPolicy) touch(e *entry) {
+	p.detach(e)
+	e.freq++
+	e.element = p.bucket(e.freq).PushFront(e)
+}
+
+func (p *lfuPolicy) remove(e *entry) {
+	p.detach(e)
+}
+
+func (p *lfuPolicy) victim() *entry {
+	if len(p.buckets) == 0 {
+		return nil
+	}
+
+	// minFreq может устареть после удаления ключей, тогда ищем ближайшую непустую частоту.
+	for {
+		if bucket, ok := p.buckets[p.minFreq]; ok {
+			return bucket.Back().Value.(*entry) //nolint:forcetypeassert
+		}
+
+		p.minFreq++
+	}
+}
+
+func (p *lfuPolicy) detach(e *entry) {
+	bucket := p.buckets[e.freq]
+	bucket.Remove(e.element)
+
+	if bucket.Len() == 0 {
+		delete(p.buckets, e.freq)
+
+		if p.minFreq == e.freq {
+			p.minFreq++
+		}
+	}
+}
+
+func (p *lfuPolicy) bucket(freq int) *list.List {
+	bucket, ok := p.buckets[freq]
+	if !ok {
+		bucket = list.New()
+		p.buckets[freq] = bucket
+	}
+
+	return bucket
+}
+
+func newEvictionPolicy(name string) evictionPolicy {
+	if name == EvictionLFU {
+		return &lfuPolicy{buckets: make(map[int]*list.List)}
+	}
+
+	return &lruPolicy{order: list.New()}
+}
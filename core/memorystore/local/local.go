@@ -0,0 +1,359 @@
+// Package local предоставляет реализацию memorystore.Manager в памяти процесса: без внешнего сервера, с временем жизни
+// ключей и вытеснением по политике LRU или LFU при превышении ограничений на количество ключей и объем данных.
+//
+// Ключи распределяются по сегментам с отдельными блокировками, ограничения Config.MaxItems и Config.MaxBytes делятся
+// между сегментами поровну с округлением вниз и в сумме не превышают заданных. Истекшие ключи удаляются при обращении
+// к ним и периодически в фоне. Значения сериализуются в JSON так же, как в providers/redis и providers/memcached,
+// поэтому Store может заменить их в тестах или служить локальным кэшем:
+//
+//	store, err := local.NewStore(local.CfgFromViper(loader))
+//	if err != nil {
+//		return err
+//	}
+//	defer store.Close(ctx)
+package local
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/tools/serial"
+)
+
+var (
+	ErrInvalidConfig = errors.New("invalid local memory store config") // Некорректная конфигурация.
+	ErrValueTooLarge = errors.New("value is too large")                // Значение превышает ограничение объема.
+
+	_ memorystore.Manager = (*Store)(nil)
+	_ evictionPolicy      = (*lruPolicy)(nil)
+	_ evictionPolicy      = (*lfuPolicy)(nil)
+)
+
+type (
+	// Store хранилище ключей в памяти процесса, реализует memorystore.Manager.
+	Store struct {
+		shards   []*shard
+		mask     uint32
+		eviction string
+		bulkSize int
+		now      func() time.Time
+		stop     chan struct{}
+		stopOnce sync.Once
+	}
+
+	// Option опция Store.
+	Option func(*Store)
+
+	shard struct {
+		mu       sync.Mutex
+		items    map[string]*entry
+		policy   evictionPolicy
+		bytes    int
+		maxItems int
+		maxBytes int
+	}
+
+	entry struct {
+		key       string
+		value     []byte
+		expiresAt time.Time
+		element   *list.Element
+		freq      int
+	}
+)
+
+// Set см. memorystore.MemoryStore. Ключ с нулевым временем жизни expiration хранится бессрочно. Вернет ошибку
+// ErrValueTooLarge, если ключ со значением не помещается в сегмент хранилища.
+func (s *Store) Set(_ context.Context, key string, value any, expiration time.Duration) error {
+	data, err := serial.ToBytes(value, serial.JSONEncode[any])
+	if err != nil {
+		return err
+	}
+
+	return s.shard(key).set(&entry{key: key, value: data, expiresAt: s.expiresAt(s.now(), expiration)})
+}
+
+// Get см. memorystore.MemoryStore.
+func (s *Store) Get(_ context.Context, key string) (*memorystore.Value, error) {
+	data, ok := s.shard(key).get(key, s.now())
+	if !ok {
+		return nil, memorystore.ErrKeyNotFound
+	}
+
+	return memorystore.NewValue(data), nil
+}
+
+// GetList см. memorystore.MemoryStore.
+func (s *Store) GetList(_ context.Context, keys ...string) ([]*memorystore.Value, error) {
+	if len(keys) > s.bulkSize {
+		return nil, fmt.Errorf("can't get more than %d keys at once: %w", s.bulkSize, memorystore.ErrBulkRequestTooLarge)
+	}
+
+	now := s.now()
+	results := make([]*memorystore.Value, len(keys))
+
+	for idx, key := range keys {
+		if data, ok := s.shard(key).get(key, now); ok {
+			results[idx] = memorystore.NewValue(data)
+		}
+	}
+
+	return results, nil
+}
+
+// Delete см. memorystore.MemoryStore.
+func (s *Store) Delete(_ context.Context, keys ...string) (int, error) {
+	if len(keys) > s.bulkSize {
+		return 0, fmt.Errorf("can't delete more than %d keys at once: %w", s.bulkSize, memorystore.ErrBulkRequestTooLarge)
+	}
+
+	now := s.now()
+
+	var deleted int
+
+	for _, key := range keys {
+		if s.shard(key).delete(key, now) {
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// Len возвращает количество ключей в хранилище, включая истекшие, но еще не удаленные.
+func (s *Store) Len() int {
+	var count int
+
+	for _, sh := range s.shards {
+		sh.mu.Lock()
+		count += len(sh.items)
+		sh.mu.Unlock()
+	}
+
+	return count
+}
+
+// Cleanup удаляет истекшие ключи, вызывается периодически, если задан Config.CleanupInterval.
+func (s *Store) Cleanup() {
+	now := s.now()
+
+	for _, sh := range s.shards {
+		sh.cleanup(now)
+	}
+}
+
+// Close останавливает фоновое удаление истекших ключей и очищает хранилище.
+func (s *Store) Close(_ context.Context) {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+
+		for _, sh := range s.shards {
+			sh.mu.Lock()
+			sh.items = make(map[string]*entry)
+			sh.policy = newEvictionPolicy(s.eviction)
+			sh.bytes = 0
+			sh.mu.Unlock()
+		}
+	})
+}
+
+func (s *Store) shard(key string) *shard {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+
+	return s.shards[hash.Sum32()&s.mask]
+}
+
+func (s *Store) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Cleanup()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (sh *shard) set(e *entry) error {
+	size := e.size()
+	if sh.maxBytes > 0 && size > sh.maxBytes {
+		return fmt.Errorf("can't set key %s of %d bytes: %w", e.key, size, ErrValueTooLarge)
+	}
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	sh.store(e, size)
+
+	return nil
+}
+
+// store сохраняет ключ, вызывается под блокировкой сегмента после проверки размера ключа.
+func (sh *shard) store(e *entry, size int) {
+	if existing, ok := sh.items[e.key]; ok {
+		sh.remove(existing)
+	}
+
+	// Вытесняем ключи до вставки, чтобы политика LFU не вытеснила новый ключ с единственным обращением.
+	for len(sh.items) > 0 && sh.full(size) {
+		sh.remove(sh.policy.victim())
+	}
+
+	sh.items[e.key] = e
+	sh.bytes += size
+	sh.policy.add(e)
+}
+
+func (sh *shard) get(key string, now time.Time) ([]byte, bool) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	e := sh.lookup(key, now)
+	if e == nil {
+		return nil, false
+	}
+
+	sh.policy.touch(e)
+
+	return append([]byte(nil), e.value...), true
+}
+
+// lookup возвращает действующий ключ или nil, удаляя истекший; вызывается под блокировкой сегмента.
+func (sh *shard) lookup(key string, now time.Time) *entry {
+	e, ok := sh.items[key]
+	if !ok {
+		return nil
+	}
+
+	if e.expired(now) {
+		sh.remove(e)
+
+		return nil
+	}
+
+	return e
+}
+
+func (sh *shard) delete(key string, now time.Time) bool {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	e, ok := sh.items[key]
+	if !ok {
+		return false
+	}
+
+	sh.remove(e)
+
+	return !e.expired(now)
+}
+
+func (sh *shard) cleanup(now time.Time) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	for _, e := range sh.items {
+		if e.expired(now) {
+			sh.remove(e)
+		}
+	}
+}
+
+func (sh *shard) full(size int) bool {
+	return (sh.maxItems > 0 && len(sh.items) >= sh.maxItems) || (sh.maxBytes > 0 && sh.bytes+size > sh.maxBytes)
+}
+
+func (sh *shard) remove(e *entry) {
+	delete(sh.items, e.key)
+	sh.bytes -= e.size()
+	sh.policy.remove(e)
+}
+
+func (e *entry) size() int {
+	return len(e.key) + len(e.value)
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// WithClock устанавливает источник текущего времени.
+func WithClock(now func() time.Time) Option {
+	return func(s *Store) {
+		s.now = now
+	}
+}
+
+// NewStore возвращает новый Store. Если задан Config.CleanupInterval, запускает фоновое удаление истекших ключей,
+// которое останавливается вызовом Close.
+func NewStore(config *Config, opts ...Option) (*Store, error) {
+	switch config.Eviction {
+	case "", EvictionLRU, EvictionLFU:
+	default:
+		return nil, fmt.Errorf("%w: unknown eviction policy %q", ErrInvalidConfig, config.Eviction)
+	}
+
+	if config.MaxItems < 0 || config.MaxBytes < 0 {
+		return nil, fmt.Errorf("%w: limits must not be negative", ErrInvalidConfig)
+	}
+
+	count := 1
+	for count < config.Shards {
+		count <<= 1
+	}
+
+	// Каждому сегменту должно достаться не менее одного ключа и байта, иначе сегмент будет без ограничения
+	for _, limit := range []int{config.MaxItems, config.MaxBytes} {
+		for limit > 0 && count > limit {
+			count >>= 1
+		}
+	}
+
+	store := &Store{
+		shards:   make([]*shard, count),
+		mask:     uint32(count - 1), //nolint:gosec
+		eviction: config.Eviction,
+		bulkSize: config.MaxBulkRequestSize,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+	}
+
+	if store.bulkSize <= 0 {
+		store.bulkSize = CfgDefaultMaxBulkRequestSize
+	}
+
+	for i := range store.shards {
+		store.shards[i] = &shard{
+			items:    make(map[string]*entry),
+			policy:   newEvictionPolicy(config.Eviction),
+			maxItems: perShard(config.MaxItems, count),
+			maxBytes: perShard(config.MaxBytes, count),
+		}
+	}
+
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	if config.CleanupInterval > 0 {
+		go store.janitor(config.CleanupInterval)
+	}
+
+	return store, nil
+}
+
+// perShard возвращает долю ограничения limit на один из count сегментов с округлением вниз, чтобы сумма долей не
+// превышала limit.
+func perShard(limit, count int) int {
+	return limit / count
+}
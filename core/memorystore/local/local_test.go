@@ -0,0 +1,222 @@
+package local_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/core/memorystore/local"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newStore(t *testing.T, config *local.Config, opts ...local.Option) *local.Store {
+	t.Helper()
+
+	store, err := local.NewStore(config, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close(context.Background()) })
+
+	return store
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	now := &clock{now: time.Now()}
+	store := newStore(t, &local.Config{Shards: 4, MaxBulkRequestSize: 3}, local.WithClock(now.Now))
+
+	require.NoError(t, store.Set(ctx, "counter", 42, 0))
+	require.NoError(t, store.Set(ctx, "session", map[string]string{"user": "john"}, time.Minute))
+
+	value, err := store.Get(ctx, "counter")
+	require.NoError(t, err)
+	counter, err := value.Int()
+	require.NoError(t, err)
+	assert.Equal(t, 42, counter)
+
+	var session map[string]string
+	value, err = store.Get(ctx, "session")
+	require.NoError(t, err)
+	require.NoError(t, value.Struct(&session))
+	assert.Equal(t, map[string]string{"user": "john"}, session)
+
+	_, err = store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, memorystore.ErrKeyNotFound)
+
+	values, err := store.GetList(ctx, "counter", "missing", "session")
+	require.NoError(t, err)
+	require.Len(t, values, 3)
+	assert.NotNil(t, values[0])
+	assert.Nil(t, values[1])
+	assert.NotNil(t, values[2])
+
+	_, err = store.GetList(ctx, "a", "b", "c", "d")
+	assert.ErrorIs(t, err, memorystore.ErrBulkRequestTooLarge)
+
+	_, err = store.Delete(ctx, "a", "b", "c", "d")
+	assert.ErrorIs(t, err, memorystore.ErrBulkRequestTooLarge)
+
+	now.now = now.now.Add(time.Minute)
+
+	_, err = store.Get(ctx, "session")
+	assert.ErrorIs(t, err, memorystore.ErrKeyNotFound)
+
+	deleted, err := store.Delete(ctx, "counter", "missing")
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestStore_Cleanup(t *testing.T) {
+	ctx := context.Background()
+	now := &clock{now: time.Now()}
+	store := newStore(t, &local.Config{}, local.WithClock(now.Now))
+
+	require.NoError(t, store.Set(ctx, "short", 1, time.Second))
+	require.NoError(t, store.Set(ctx, "long", 1, time.Hour))
+	require.NoError(t, store.Set(ctx, "forever", 1, 0))
+
+	now.now = now.now.Add(time.Minute)
+	store.Cleanup()
+
+	assert.Equal(t, 2, store.Len())
+}
+
+func TestStore_eviction(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		config   *local.Config
+		access   []string
+		set      string
+		wantKeys []string
+	}{
+		{
+			name:     "LRU by items",
+			config:   &local.Config{MaxItems: 3, Shards: 1, Eviction: local.EvictionLRU},
+			access:   []string{"a", "b", "a", "c"},
+			set:      "d",
+			wantKeys: []string{"a", "c", "d"},
+		},
+		{
+			name:     "LFU by items",
+			config:   &local.Config{MaxItems: 3, Shards: 1, Eviction: local.EvictionLFU},
+			access:   []string{"a", "a", "b", "b", "c"},
+			set:      "d",
+			wantKeys: []string{"a", "b", "d"},
+		},
+		{
+			name:     "LRU by bytes",
+			config:   &local.Config{MaxBytes: 9, Shards: 1},
+			access:   []string{"a", "c"},
+			set:      "d",
+			wantKeys: []string{"a", "c", "d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newStore(t, tt.config)
+
+			// Каждый ключ со значением занимает 3 байта: ключ, значение и перевод строки JSON.
+			for _, key := range []string{"a", "b", "c"} {
+				require.NoError(t, store.Set(ctx, key, 1, 0))
+			}
+
+			for _, key := range tt.access {
+				_, err := store.Get(ctx, key)
+				require.NoError(t, err)
+			}
+
+			require.NoError(t, store.Set(ctx, tt.set, 1, 0))
+
+			values, err := store.GetList(ctx, "a", "b", "c", "d")
+			require.NoError(t, err)
+
+			var keys []string
+			for i, key := range []string{"a", "b", "c", "d"} {
+				if values[i] != nil {
+					keys = append(keys, key)
+				}
+			}
+
+			assert.Equal(t, tt.wantKeys, keys)
+		})
+	}
+}
+
+func TestStore_maxItemsAcrossShards(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		config *local.Config
+	}{
+		{name: "Fewer items than shards", config: &local.Config{MaxItems: 3, Shards: 16}},
+		{name: "Items not divisible by shards", config: &local.Config{MaxItems: 10, Shards: 4}},
+		{name: "Default shards", config: &local.Config{MaxItems: 100, Shards: local.CfgDefaultShards}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newStore(t, tt.config)
+
+			for i := 0; i < 10*tt.config.MaxItems; i++ {
+				require.NoError(t, store.Set(ctx, fmt.Sprintf("key-%d", i), i, 0))
+			}
+
+			assert.LessOrEqual(t, store.Len(), tt.config.MaxItems)
+			assert.Positive(t, store.Len())
+		})
+	}
+}
+
+func TestStore_valueTooLarge(t *testing.T) {
+	store := newStore(t, &local.Config{MaxBytes: 8, Shards: 1})
+
+	assert.ErrorIs(t, store.Set(context.Background(), "key", "value", 0), local.ErrValueTooLarge)
+}
+
+func TestNewStore_invalid(t *testing.T) {
+	_, err := local.NewStore(&local.Config{Eviction: "fifo"})
+	assert.ErrorIs(t, err, local.ErrInvalidConfig)
+}
+
+func TestStore_concurrent(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t, &local.Config{MaxItems: 64, Shards: 8, Eviction: local.EvictionLFU, CleanupInterval: time.Millisecond})
+
+	var wg sync.WaitGroup
+
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+
+		go func(worker int) {
+			defer wg.Done()
+
+			for i := 0; i < 1000; i++ {
+				key := fmt.Sprintf("key-%d", (worker*i)%100)
+				_ = store.Set(ctx, key, i, time.Millisecond*time.Duration(i%5))
+				_, _ = store.Get(ctx, key)
+				_, _ = store.Delete(ctx, key)
+			}
+		}(worker)
+	}
+
+	wg.Wait()
+
+	assert.LessOrEqual(t, store.Len(), 64)
+}
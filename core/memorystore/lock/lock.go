@@ -0,0 +1,255 @@
+// Package lock предоставляет распределенные блокировки с арендой (lease) поверх хранилищ memorystore: только один
+// экземпляр приложения владеет блокировкой ключа, пока не освободит ее или не истечет срок аренды.
+//
+// Каждая захваченная блокировка получает токен ограждения (fencing token), монотонно возрастающий для ключа. Ресурс,
+// защищаемый блокировкой, может отклонять операции с токеном меньше уже виденного, чтобы владелец с истекшей арендой
+// (например, после долгой паузы GC) не испортил данные. Счетчик токенов хранится не дольше FenceTTL с последнего
+// захвата, после чего токены ключа начинаются заново: к этому времени аренды с прежними токенами давно истекли.
+//
+// Хранилище блокировок задается Backend: providers/redis, providers/memcached или MemoryBackend для тестов.
+//
+//	locker := lock.NewLocker(redisClient.LockBackend())
+//	err := locker.Do(ctx, "reindex", time.Minute, func(ctx context.Context, lease *lock.Lease) error {
+//		return reindex(ctx)
+//	})
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wal1251/pkg/core/logs"
+)
+
+const (
+	DefaultKeyPrefix  = "lock:"               // Префикс ключей блокировок по умолчанию.
+	DefaultMinBackoff = 50 * time.Millisecond // Начальная задержка между попытками захвата по умолчанию.
+	DefaultMaxBackoff = time.Second           // Максимальная задержка между попытками захвата по умолчанию.
+	DefaultFenceTTL   = 7 * 24 * time.Hour    // Время хранения счетчика токенов ограждения по умолчанию, см. FenceTTL.
+	refreshDivider    = 3                     // Аренда продлевается трижды за время аренды.
+)
+
+var (
+	ErrNotAcquired = errors.New("lock is held by another owner") // Блокировка захвачена другим владельцем.
+	ErrLeaseLost   = errors.New("lock lease is lost")            // Аренда истекла или блокировка захвачена другим.
+)
+
+type (
+	// Backend хранилище блокировок. Операции должны выполняться атомарно.
+	Backend interface {
+		// Acquire захватывает блокировку ключа key для владельца owner на время ttl, если она свободна, и возвращает
+		// новый токен ограждения. Срок хранения счетчика токенов продлевается на FenceTTL(ttl).
+		Acquire(ctx context.Context, key, owner string, ttl time.Duration) (token int64, acquired bool, err error)
+		// Refresh продлевает аренду на время ttl, если блокировкой владеет owner.
+		Refresh(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+		// Release освобождает блокировку, если ею владеет owner.
+		Release(ctx context.Context, key, owner string) (bool, error)
+	}
+
+	// Locker захватывает блокировки в Backend.
+	Locker struct {
+		backend    Backend
+		prefix     string
+		minBackoff time.Duration
+		maxBackoff time.Duration
+		random     func() float64
+	}
+
+	// Option опция Locker.
+	Option func(*Locker)
+
+	// Lease аренда захваченной блокировки.
+	Lease struct {
+		backend Backend
+		key     string
+		owner   string
+		token   int64
+	}
+)
+
+// TryLock захватывает блокировку ключа key на время ttl. Если блокировка захвачена другим владельцем, сразу вернет
+// ошибку ErrNotAcquired.
+func (l *Locker) TryLock(ctx context.Context, key string, ttl time.Duration) (*Lease, error) {
+	lease := &Lease{
+		backend: l.backend,
+		key:     l.prefix + key,
+		owner:   uuid.NewString(),
+	}
+
+	token, acquired, err := l.backend.Acquire(ctx, lease.key, lease.owner, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("can't acquire lock %s: %w", key, err)
+	}
+
+	if !acquired {
+		return nil, fmt.Errorf("%w: %s", ErrNotAcquired, key)
+	}
+
+	lease.token = token
+
+	return lease, nil
+}
+
+// Lock захватывает блокировку ключа key на время ttl, ожидая ее освобождения. Попытки повторяются с экспоненциально
+// растущей задержкой до отмены контекста ctx.
+func (l *Locker) Lock(ctx context.Context, key string, ttl time.Duration) (*Lease, error) {
+	for attempt := 0; ; attempt++ {
+		lease, err := l.TryLock(ctx, key, ttl)
+		if !errors.Is(err, ErrNotAcquired) {
+			return lease, err
+		}
+
+		timer := time.NewTimer(l.backoff(attempt))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, fmt.Errorf("can't acquire lock %s: %w", key, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// Do захватывает блокировку (см. Lock) и выполняет fn, продлевая аренду в фоне. Если аренду продлить не удалось,
+// контекст fn отменяется с причиной ErrLeaseLost. После выполнения fn блокировка освобождается.
+func (l *Locker) Do(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context, lease *Lease) error) error {
+	lease, err := l.Lock(ctx, key, ttl)
+	if err != nil {
+		return err
+	}
+
+	fnCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(ttl / refreshDivider)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := lease.Refresh(fnCtx, ttl); err != nil {
+					cancel(err)
+
+					return
+				}
+			}
+		}
+	}()
+
+	err = fn(fnCtx, lease)
+
+	close(done)
+	wg.Wait()
+
+	if releaseErr := lease.Release(context.WithoutCancel(ctx)); releaseErr != nil {
+		logs.FromContext(ctx).Warn().Err(releaseErr).Str("key", key).Msg("failed to release lock")
+	}
+
+	return err
+}
+
+func (l *Locker) backoff(attempt int) time.Duration {
+	delay := l.minBackoff
+	for i := 0; i < attempt && delay < l.maxBackoff; i++ {
+		delay *= 2
+	}
+
+	delay = min(delay, l.maxBackoff)
+
+	// Случайная составляющая разводит по времени попытки экземпляров, ожидающих одну блокировку.
+	return delay/2 + time.Duration(l.random()*float64(delay/2))
+}
+
+// FenceTTL возвращает время хранения счетчика токенов ограждения после захвата блокировки с арендой ttl: не меньше
+// DefaultFenceTTL и не меньше самой аренды.
+func FenceTTL(ttl time.Duration) time.Duration {
+	return max(DefaultFenceTTL, ttl)
+}
+
+// Key возвращает ключ блокировки в хранилище.
+func (l *Lease) Key() string {
+	return l.key
+}
+
+// Token возвращает токен ограждения: токен каждой следующей блокировки ключа больше предыдущего.
+func (l *Lease) Token() int64 {
+	return l.token
+}
+
+// Refresh продлевает аренду на время ttl. Вернет ошибку ErrLeaseLost, если аренда истекла.
+func (l *Lease) Refresh(ctx context.Context, ttl time.Duration) error {
+	refreshed, err := l.backend.Refresh(ctx, l.key, l.owner, ttl)
+	if err != nil {
+		return fmt.Errorf("can't refresh lock %s: %w", l.key, err)
+	}
+
+	if !refreshed {
+		return fmt.Errorf("%w: %s", ErrLeaseLost, l.key)
+	}
+
+	return nil
+}
+
+// Release освобождает блокировку. Вернет ошибку ErrLeaseLost, если аренда истекла до освобождения.
+func (l *Lease) Release(ctx context.Context) error {
+	released, err := l.backend.Release(ctx, l.key, l.owner)
+	if err != nil {
+		return fmt.Errorf("can't release lock %s: %w", l.key, err)
+	}
+
+	if !released {
+		return fmt.Errorf("%w: %s", ErrLeaseLost, l.key)
+	}
+
+	return nil
+}
+
+// WithKeyPrefix устанавливает префикс ключей блокировок, по умолчанию DefaultKeyPrefix.
+func WithKeyPrefix(prefix string) Option {
+	return func(l *Locker) {
+		l.prefix = prefix
+	}
+}
+
+// WithBackoff устанавливает начальную и максимальную задержку между попытками захвата блокировки в Lock.
+func WithBackoff(minBackoff, maxBackoff time.Duration) Option {
+	return func(l *Locker) {
+		l.minBackoff = minBackoff
+		l.maxBackoff = max(minBackoff, maxBackoff)
+	}
+}
+
+// NewLocker возвращает новый Locker.
+func NewLocker(backend Backend, opts ...Option) *Locker {
+	locker := &Locker{
+		backend:    backend,
+		prefix:     DefaultKeyPrefix,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+		random:     rand.Float64, //nolint:gosec
+	}
+
+	for _, opt := range opts {
+		opt(locker)
+	}
+
+	return locker
+}
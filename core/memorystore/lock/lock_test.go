@@ -0,0 +1,118 @@
+package lock_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/memorystore/lock"
+)
+
+func TestLocker_TryLock(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	locker := lock.NewLocker(lock.NewMemoryBackend(func() time.Time { return now }))
+
+	first, err := locker.TryLock(ctx, "job", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), first.Token())
+	assert.Equal(t, "lock:job", first.Key())
+
+	_, err = locker.TryLock(ctx, "job", time.Minute)
+	assert.ErrorIs(t, err, lock.ErrNotAcquired)
+
+	require.NoError(t, first.Refresh(ctx, time.Minute))
+
+	// Аренда истекла: блокировку захватывает другой владелец с большим токеном.
+	now = now.Add(2 * time.Minute)
+
+	second, err := locker.TryLock(ctx, "job", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), second.Token())
+
+	assert.ErrorIs(t, first.Refresh(ctx, time.Minute), lock.ErrLeaseLost)
+	assert.ErrorIs(t, first.Release(ctx), lock.ErrLeaseLost)
+
+	require.NoError(t, second.Release(ctx))
+
+	third, err := locker.TryLock(ctx, "job", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), third.Token())
+	require.NoError(t, third.Release(ctx))
+
+	// Счетчик токенов хранится FenceTTL с последнего захвата.
+	now = now.Add(lock.DefaultFenceTTL)
+
+	fourth, err := locker.TryLock(ctx, "job", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), fourth.Token())
+}
+
+func TestLocker_Lock(t *testing.T) {
+	ctx := context.Background()
+	locker := lock.NewLocker(lock.NewMemoryBackend(nil), lock.WithBackoff(time.Millisecond, 5*time.Millisecond))
+
+	held, err := locker.TryLock(ctx, "job", time.Minute)
+	require.NoError(t, err)
+
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+
+	_, err = locker.Lock(timeout, "job", time.Minute)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = held.Release(ctx)
+	}()
+
+	lease, err := locker.Lock(ctx, "job", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), lease.Token())
+}
+
+func TestLocker_Do(t *testing.T) {
+	ctx := context.Background()
+	locker := lock.NewLocker(lock.NewMemoryBackend(nil), lock.WithBackoff(time.Millisecond, 5*time.Millisecond))
+
+	var (
+		wg      sync.WaitGroup
+		running atomic.Int32
+		counter int
+	)
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := locker.Do(ctx, "counter", 30*time.Millisecond, func(context.Context, *lock.Lease) error {
+				assert.Equal(t, int32(1), running.Add(1))
+				defer running.Add(-1)
+
+				// Выполнение дольше аренды: аренда продлевается в фоне.
+				time.Sleep(40 * time.Millisecond)
+				counter++
+
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, 5, counter)
+
+	failure := errors.New("job failed")
+	assert.ErrorIs(t, locker.Do(ctx, "counter", time.Minute, func(context.Context, *lock.Lease) error { return failure }), failure)
+
+	_, err := locker.TryLock(ctx, "counter", time.Minute)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,98 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Backend = (*MemoryBackend)(nil)
+
+type (
+	// MemoryBackend хранилище блокировок в памяти процесса, подходит для тестов и приложений из одного экземпляра.
+	MemoryBackend struct {
+		mu     sync.Mutex
+		locks  map[string]memoryLock
+		tokens map[string]memoryFence
+		now    func() time.Time
+	}
+
+	memoryLock struct {
+		owner     string
+		expiresAt time.Time
+	}
+
+	memoryFence struct {
+		token     int64
+		expiresAt time.Time
+	}
+)
+
+// Acquire см. Backend.
+func (b *MemoryBackend) Acquire(_ context.Context, key, owner string, ttl time.Duration) (int64, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if current, ok := b.locks[key]; ok && now.Before(current.expiresAt) {
+		return 0, false, nil
+	}
+
+	b.locks[key] = memoryLock{owner: owner, expiresAt: now.Add(ttl)}
+
+	fence := b.tokens[key]
+	if !now.Before(fence.expiresAt) {
+		fence.token = 0
+	}
+
+	fence.token++
+	fence.expiresAt = now.Add(FenceTTL(ttl))
+	b.tokens[key] = fence
+
+	return fence.token, true, nil
+}
+
+// Refresh см. Backend.
+func (b *MemoryBackend) Refresh(_ context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+
+	current, ok := b.locks[key]
+	if !ok || current.owner != owner || !now.Before(current.expiresAt) {
+		return false, nil
+	}
+
+	b.locks[key] = memoryLock{owner: owner, expiresAt: now.Add(ttl)}
+
+	return true, nil
+}
+
+// Release см. Backend.
+func (b *MemoryBackend) Release(_ context.Context, key, owner string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	current, ok := b.locks[key]
+	if !ok || current.owner != owner || !b.now().Before(current.expiresAt) {
+		return false, nil
+	}
+
+	delete(b.locks, key)
+
+	return true, nil
+}
+
+// NewMemoryBackend возвращает новый MemoryBackend. Необязательный now задает источник текущего времени.
+func NewMemoryBackend(now func() time.Time) *MemoryBackend {
+	if now == nil {
+		now = time.Now
+	}
+
+	return &MemoryBackend{
+		locks:  make(map[string]memoryLock),
+		tokens: make(map[string]memoryFence),
+		now:    now,
+	}
+}
@@ -1,21 +1,24 @@
 package memorystore
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
-
-	"github.com/wal1251/pkg/tools/serial"
 )
 
 // Value представляет собой обертку для значений получаемых из MemoryStore.
 type Value struct {
-	val []byte
+	val   []byte
+	codec Codec
 }
 
-// NewValue создает новый экземпляр Value.
+// NewValue создает новый экземпляр Value со значением в формате JSON.
 func NewValue(val []byte) *Value {
-	return &Value{val: val}
+	return &Value{val: val, codec: JSONCodec{}}
+}
+
+// NewCodecValue создает новый экземпляр Value со значением, сериализованным кодеком codec.
+func NewCodecValue(val []byte, codec Codec) *Value {
+	return &Value{val: val, codec: codec}
 }
 
 // RawBytes возвращает исходное значение в виде среза байт.
@@ -23,62 +26,91 @@ func (v *Value) RawBytes() []byte {
 	return v.val
 }
 
-// Bytes возвращает значение в виде среза байт.
-// Предварительно используя десериализацию из JSON.
+// Bytes возвращает значение в виде среза байт: строку или срез байт, записанный кодеком, сохраняющим тип значения
+// (GobCodec, RawCodec). Для остальных значений вернет строковое представление, см. String.
 func (v *Value) Bytes() ([]byte, error) {
+	var str string
+	if err := v.codec.Unmarshal(v.val, &str); err == nil {
+		return []byte(str), nil
+	}
+
+	var data []byte
+	if err := v.codec.Unmarshal(v.val, &data); err == nil {
+		return data, nil
+	}
+
 	val, err := v.String()
 
 	return []byte(val), err
 }
 
-// String возвращает строковое представление значения.
+// String возвращает строковое представление значения. Строковые значения возвращаются без изменений, остальные
+// форматируются fmt.Sprint.
 func (v *Value) String() (string, error) {
-	val, err := serial.FromBytes(v.val, serial.JSONDecode[any])
+	var str string
+	if err := v.codec.Unmarshal(v.val, &str); err == nil {
+		return str, nil
+	}
 
-	return fmt.Sprint(val), err
+	var val any
+	if err := v.codec.Unmarshal(v.val, &val); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprint(val), nil
 }
 
 // Int преобразует значение в целое число.
 func (v *Value) Int() (int, error) {
-	return serial.FromBytes(v.val, serial.JSONDecode[int])
+	return decode[int](v)
 }
 
 // Int64 преобразует значение в целое число типа int64.
 func (v *Value) Int64() (int64, error) {
-	return serial.FromBytes(v.val, serial.JSONDecode[int64])
+	return decode[int64](v)
 }
 
 // Uint64 преобразует значение в беззнаковое целое число типа uint64.
 func (v *Value) Uint64() (uint64, error) {
-	return serial.FromBytes(v.val, serial.JSONDecode[uint64])
+	return decode[uint64](v)
 }
 
 // Float32 преобразует значение в число с плавающей точкой типа float32.
 func (v *Value) Float32() (float32, error) {
-	return serial.FromBytes(v.val, serial.JSONDecode[float32])
+	return decode[float32](v)
 }
 
 // Float64 преобразует значение в число с плавающей точкой типа float64.
 func (v *Value) Float64() (float64, error) {
-	return serial.FromBytes(v.val, serial.JSONDecode[float64])
+	return decode[float64](v)
 }
 
 // Bool преобразует значение в булевый тип.
 func (v *Value) Bool() (bool, error) {
-	return serial.FromBytes(v.val, serial.JSONDecode[bool])
+	return decode[bool](v)
 }
 
 // Time преобразует значение в тип time.Time.
 func (v *Value) Time() (time.Time, error) {
-	return serial.FromBytes(v.val, serial.JSONDecode[time.Time])
+	return decode[time.Time](v)
 }
 
-// Struct преобразует значение в структуру, используя JSON-декодирование.
+// Struct преобразует значение в структуру, используя кодек значения.
 func (v *Value) Struct(dst any) error {
-	err := json.Unmarshal(v.val, dst)
-	if err != nil {
+	if err := v.codec.Unmarshal(v.val, dst); err != nil {
 		return fmt.Errorf("can't unmarshal value: %w", err)
 	}
 
 	return nil
 }
+
+func decode[T any](v *Value) (T, error) {
+	var result T
+	if err := v.codec.Unmarshal(v.val, &result); err != nil {
+		var blank T
+
+		return blank, err
+	}
+
+	return result, nil
+}
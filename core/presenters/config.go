@@ -5,6 +5,7 @@ import "github.com/wal1251/pkg/core/cfg"
 const (
 	CfgKeySecuredKeywords cfg.Key = "VIEWS_OPTIONS_SECURED_KEYWORDS"
 	CfgKeyMaxStringLength cfg.Key = "VIEWS_OPTIONS_MAX_STRING_LENGTH"
+	CfgKeyMaskPII         cfg.Key = "VIEWS_OPTIONS_MASK_PII"
 
 	CfgDefaultKeySecuredKeyword = ""
 	CfgDefaultMaxStringLength   = 5
@@ -14,5 +15,6 @@ type (
 	Config struct {
 		SecuredKeywords []string
 		MaxStringLength int
+		MaskPII         bool // Маскировать персональные данные (телефоны, email) в представлениях.
 	}
 )
@@ -13,5 +13,6 @@ func CfgFromViper(loader *viper.Viper, keyMapping ...cfg.KeyMap) *Config {
 	return &Config{
 		SecuredKeywords: loader.GetStringSlice(CfgKeySecuredKeywords.String()),
 		MaxStringLength: viperx.Get(loader, CfgKeyMaxStringLength.Map(keyMapping...), CfgDefaultMaxStringLength),
+		MaskPII:         viperx.Get(loader, CfgKeyMaskPII.Map(keyMapping...), false),
 	}
 }
@@ -0,0 +1,177 @@
+// Package filter предоставляет язык выражений фильтрации коллекций для параметров запросов списков. Выражение
+// состоит из условий, разделенных ";", и выбирает элементы, удовлетворяющие всем условиям:
+//
+//	status:in:new,open;created:gte:2024-01-01;name:like:foo
+//
+// Каждое условие записывается как поле:оператор:значение. Значения операторов in и nin перечисляются через ",".
+// Символы ";", "," и "\" в значениях экранируются обратной косой чертой, например name:eq:a\;b.
+//
+// Выражение разбирается в дерево (см. Parse), затем проверяется по схеме допустимых полей и их типов (см. Schema),
+// после чего может быть преобразовано в запрос к хранилищу адаптерами: predicates.Filter (ent), mongo.Filter.Expression
+// и search.NewQueryBooleanFromFilter (Elasticsearch).
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wal1251/pkg/core/errs"
+)
+
+const (
+	OpEq    Operator = "eq"   // Равно.
+	OpNe    Operator = "ne"   // Не равно.
+	OpGt    Operator = "gt"   // Больше.
+	OpGte   Operator = "gte"  // Больше или равно.
+	OpLt    Operator = "lt"   // Меньше.
+	OpLte   Operator = "lte"  // Меньше или равно.
+	OpIn    Operator = "in"   // Равно одному из значений.
+	OpNin   Operator = "nin"  // Не равно ни одному из значений.
+	OpLike  Operator = "like" // Содержит подстроку без учета регистра.
+	OpRegex Operator = "re"   // Соответствует регулярному выражению без учета регистра.
+	OpNull  Operator = "null" // Значение пустое (true) или не пустое (false).
+
+	MaxConditions = 32  // Максимальное количество условий выражения.
+	MaxValues     = 100 // Максимальное количество значений операторов in и nin.
+
+	FieldExpression = "filter" // Поле ошибки валидации, не относящейся к конкретному полю выражения.
+)
+
+type (
+	// Operator оператор условия.
+	Operator string
+
+	// Expression выражение фильтрации: конъюнкция условий.
+	Expression struct {
+		Conditions []Condition
+	}
+
+	// Condition условие выражения.
+	Condition struct {
+		Field    string   // Поле, как оно записано в выражении.
+		Column   string   // Поле хранилища, заполняется при проверке схемой.
+		Operator Operator // Оператор условия.
+		Raw      []string // Значения, как они записаны в выражении.
+		Values   []any    // Значения, приведенные к типу поля, заполняются при проверке схемой.
+	}
+)
+
+// IsEmpty вернет true, если выражение не содержит условий.
+func (e *Expression) IsEmpty() bool {
+	return e == nil || len(e.Conditions) == 0
+}
+
+// Value возвращает первое значение условия.
+func (c *Condition) Value() any {
+	if len(c.Values) == 0 {
+		return nil
+	}
+
+	return c.Values[0]
+}
+
+// IsMulti вернет true, если оператор условия принимает список значений.
+func (o Operator) IsMulti() bool {
+	return o == OpIn || o == OpNin
+}
+
+// Parse разбирает выражение raw без проверки полей и значений, см. Schema.Parse. Пустое выражение не содержит
+// условий. Синтаксические ошибки возвращаются как ошибки валидации поля FieldExpression или поля условия, см.
+// errs.WrapFields.
+func Parse(raw string) (*Expression, error) {
+	expression := &Expression{}
+
+	clauses, err := split(raw, ';', -1, false)
+	if err != nil {
+		return nil, validationError(FieldExpression, "%v", err)
+	}
+
+	for _, clause := range clauses {
+		if strings.TrimSpace(clause) == "" {
+			continue
+		}
+
+		if len(expression.Conditions) == MaxConditions {
+			return nil, validationError(FieldExpression, "too many conditions, max %d", MaxConditions)
+		}
+
+		condition, err := parseCondition(clause)
+		if err != nil {
+			return nil, err
+		}
+
+		expression.Conditions = append(expression.Conditions, condition)
+	}
+
+	return expression, nil
+}
+
+func parseCondition(clause string) (Condition, error) {
+	parts := strings.SplitN(clause, ":", 3) //nolint:gomnd
+	if len(parts) != 3 || parts[0] == "" {  //nolint:gomnd
+		return Condition{}, validationError(FieldExpression, "condition %q must be field:operator:value", clause)
+	}
+
+	condition := Condition{Field: strings.TrimSpace(parts[0]), Operator: Operator(strings.ToLower(parts[1]))}
+
+	limit := 1
+	if condition.Operator.IsMulti() {
+		limit = MaxValues + 1
+	}
+
+	values, err := split(parts[2], ',', limit, true)
+	if err != nil {
+		return Condition{}, validationError(condition.Field, "%v", err)
+	}
+
+	if len(values) > MaxValues {
+		return Condition{}, validationError(condition.Field, "too many values, max %d", MaxValues)
+	}
+
+	condition.Raw = values
+
+	return condition, nil
+}
+
+// split разделяет строку по неэкранированному разделителю sep не более чем на limit частей (все части при limit < 0).
+// Если unescape, из частей удаляется экранирование.
+func split(raw string, sep rune, limit int, unescape bool) ([]string, error) {
+	var (
+		parts   []string
+		current strings.Builder
+		escaped bool
+	)
+
+	for _, char := range raw {
+		switch {
+		case escaped:
+			current.WriteRune(char)
+			escaped = false
+		case char == '\\':
+			escaped = true
+
+			if !unescape {
+				current.WriteRune(char)
+			}
+		case char == sep && (limit < 0 || len(parts) < limit-1):
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(char)
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("unterminated escape sequence in %q", raw)
+	}
+
+	return append(parts, current.String()), nil
+}
+
+func validationError(field, format string, args ...any) error {
+	message := fmt.Sprintf(format, args...)
+	err := errs.WrapFields(errs.ErrIllegalArgument, message, field)
+	err.Message = field + ": " + message
+
+	return err
+}
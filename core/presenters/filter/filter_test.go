@@ -0,0 +1,181 @@
+package filter_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/presenters/filter"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		want      []filter.Condition
+		wantField string
+	}{
+		{
+			name: "Пустое выражение",
+			raw:  "",
+		},
+		{
+			name: "Несколько условий",
+			raw:  "status:in:new,open;created:gte:2024-01-01T10:00:00Z;name:LIKE:foo;",
+			want: []filter.Condition{
+				{Field: "status", Operator: filter.OpIn, Raw: []string{"new", "open"}},
+				{Field: "created", Operator: filter.OpGte, Raw: []string{"2024-01-01T10:00:00Z"}},
+				{Field: "name", Operator: filter.OpLike, Raw: []string{"foo"}},
+			},
+		},
+		{
+			name: "Экранирование",
+			raw:  `name:eq:a\;b,c;tag:in:x\,y,z\\`,
+			want: []filter.Condition{
+				{Field: "name", Operator: filter.OpEq, Raw: []string{"a;b,c"}},
+				{Field: "tag", Operator: filter.OpIn, Raw: []string{"x,y", `z\`}},
+			},
+		},
+		{
+			name:      "Нет оператора",
+			raw:       "name:foo",
+			wantField: filter.FieldExpression,
+		},
+		{
+			name:      "Незавершенное экранирование",
+			raw:       `name:eq:foo\`,
+			wantField: filter.FieldExpression,
+		},
+		{
+			name:      "Слишком много условий",
+			raw:       strings.Repeat("name:eq:foo;", filter.MaxConditions+1),
+			wantField: filter.FieldExpression,
+		},
+		{
+			name:      "Слишком много значений",
+			raw:       "name:in:" + strings.Repeat("a,", filter.MaxValues) + "a",
+			wantField: "name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, err := filter.Parse(tt.raw)
+			if tt.wantField != "" {
+				requireValidationError(t, err, tt.wantField)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, expression.Conditions)
+		})
+	}
+}
+
+func TestSchema_Parse(t *testing.T) {
+	id := uuid.New()
+	schema := filter.NewSchema(
+		filter.Field{Name: "status", Type: filter.TypeString},
+		filter.Field{Name: "name", Type: filter.TypeString, Operators: []filter.Operator{filter.OpLike, filter.OpRegex}},
+		filter.Field{Name: "created", Column: "created_at", Type: filter.TypeTime},
+		filter.Field{Name: "count", Type: filter.TypeInt},
+		filter.Field{Name: "price", Type: filter.TypeFloat},
+		filter.Field{Name: "active", Type: filter.TypeBool},
+		filter.Field{Name: "owner", Type: filter.TypeUUID},
+	)
+
+	tests := []struct {
+		name      string
+		raw       string
+		want      []filter.Condition
+		wantField string
+	}{
+		{
+			name: "Приведение значений",
+			raw:  "status:in:new,open;created:gte:2024-01-01;count:lt:10;price:gt:1.5;active:eq:true;owner:eq:" + id.String(),
+			want: []filter.Condition{
+				{Field: "status", Column: "status", Operator: filter.OpIn, Raw: []string{"new", "open"}, Values: []any{"new", "open"}},
+				{Field: "created", Column: "created_at", Operator: filter.OpGte, Raw: []string{"2024-01-01"}, Values: []any{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}},
+				{Field: "count", Column: "count", Operator: filter.OpLt, Raw: []string{"10"}, Values: []any{int64(10)}},
+				{Field: "price", Column: "price", Operator: filter.OpGt, Raw: []string{"1.5"}, Values: []any{1.5}},
+				{Field: "active", Column: "active", Operator: filter.OpEq, Raw: []string{"true"}, Values: []any{true}},
+				{Field: "owner", Column: "owner", Operator: filter.OpEq, Raw: []string{id.String()}, Values: []any{id}},
+			},
+		},
+		{
+			name: "Пустое значение и регулярное выражение",
+			raw:  `count:null:true;name:re:^foo\\d+`,
+			want: []filter.Condition{
+				{Field: "count", Column: "count", Operator: filter.OpNull, Raw: []string{"true"}, Values: []any{true}},
+				{Field: "name", Column: "name", Operator: filter.OpRegex, Raw: []string{`^foo\d+`}, Values: []any{`^foo\d+`}},
+			},
+		},
+		{
+			name:      "Неизвестное поле",
+			raw:       "secret:eq:1",
+			wantField: "secret",
+		},
+		{
+			name:      "Недопустимый оператор",
+			raw:       "active:gt:true",
+			wantField: "active",
+		},
+		{
+			name:      "Регулярное выражение не разрешено",
+			raw:       "status:re:.*",
+			wantField: "status",
+		},
+		{
+			name:      "Неверное регулярное выражение",
+			raw:       "name:re:(",
+			wantField: "name",
+		},
+		{
+			name:      "Неверный тип значения",
+			raw:       "count:eq:ten",
+			wantField: "count",
+		},
+		{
+			name:      "Неверное время",
+			raw:       "created:lt:yesterday",
+			wantField: "created",
+		},
+		{
+			name:      "Пустая подстрока",
+			raw:       "name:like:",
+			wantField: "name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, err := schema.Parse(tt.raw)
+			if tt.wantField != "" {
+				requireValidationError(t, err, tt.wantField)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, expression.Conditions)
+		})
+	}
+}
+
+func requireValidationError(t *testing.T, err error, field string) {
+	t.Helper()
+
+	require.Error(t, err)
+	require.ErrorIs(t, err, errs.ErrIllegalArgument)
+
+	var wrapping *errs.WrappingError
+	require.True(t, errors.As(err, &wrapping))
+	assert.Contains(t, wrapping.Fields, field)
+}
@@ -0,0 +1,191 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TypeString FieldType = "string" // Строка.
+	TypeInt    FieldType = "int"    // Целое число, int64.
+	TypeFloat  FieldType = "float"  // Число с плавающей точкой, float64.
+	TypeBool   FieldType = "bool"   // Логическое значение.
+	TypeTime   FieldType = "time"   // Время в формате RFC3339 или дата в формате 2006-01-02, time.Time.
+	TypeUUID   FieldType = "uuid"   // Идентификатор, uuid.UUID.
+)
+
+type (
+	// FieldType тип поля выражения.
+	FieldType string
+
+	// Field описание поля, допустимого в выражении.
+	Field struct {
+		Name      string     // Имя поля в выражении.
+		Column    string     // Поле хранилища, по умолчанию совпадает с Name.
+		Type      FieldType  // Тип значений поля.
+		Operators []Operator // Допустимые операторы, по умолчанию DefaultOperators(Type).
+	}
+
+	// Schema белый список полей выражения.
+	Schema struct {
+		fields map[string]Field
+	}
+)
+
+// DefaultOperators возвращает операторы, допустимые по умолчанию для полей типа fieldType. Оператор OpRegex не
+// допускается по умолчанию: сложность регулярного выражения из запроса не ограничена, поэтому его нужно разрешить
+// для поля явно.
+func DefaultOperators(fieldType FieldType) []Operator {
+	switch fieldType {
+	case TypeString:
+		return []Operator{OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn, OpNin, OpLike, OpNull}
+	case TypeInt, TypeFloat, TypeTime:
+		return []Operator{OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn, OpNin, OpNull}
+	case TypeBool:
+		return []Operator{OpEq, OpNe, OpNull}
+	case TypeUUID:
+		return []Operator{OpEq, OpNe, OpIn, OpNin, OpNull}
+	default:
+		return nil
+	}
+}
+
+// NewSchema создает схему из описаний полей.
+func NewSchema(fields ...Field) *Schema {
+	schema := &Schema{fields: make(map[string]Field, len(fields))}
+	for _, field := range fields {
+		if field.Column == "" {
+			field.Column = field.Name
+		}
+
+		if field.Operators == nil {
+			field.Operators = DefaultOperators(field.Type)
+		}
+
+		schema.fields[field.Name] = field
+	}
+
+	return schema
+}
+
+// Parse разбирает выражение raw и проверяет его по схеме, см. Validate.
+func (s *Schema) Parse(raw string) (*Expression, error) {
+	expression, err := Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.Validate(expression); err != nil {
+		return nil, err
+	}
+
+	return expression, nil
+}
+
+// Validate проверяет, что условия выражения ссылаются на поля схемы и используют допустимые операторы, приводит
+// значения условий к типам полей и заполняет поля хранилища. Ошибки возвращаются как ошибки валидации поля условия,
+// см. errs.WrapFields.
+func (s *Schema) Validate(expression *Expression) error {
+	if expression == nil {
+		return nil
+	}
+
+	for i := range expression.Conditions {
+		condition := &expression.Conditions[i]
+
+		field, ok := s.fields[condition.Field]
+		if !ok {
+			return validationError(condition.Field, "unknown field")
+		}
+
+		if !slices.Contains(field.Operators, condition.Operator) {
+			return validationError(condition.Field, "operator %q is not supported", condition.Operator)
+		}
+
+		values, err := field.convert(condition.Operator, condition.Raw)
+		if err != nil {
+			return validationError(condition.Field, "%v", err)
+		}
+
+		condition.Column = field.Column
+		condition.Values = values
+	}
+
+	return nil
+}
+
+func (f Field) convert(operator Operator, raw []string) ([]any, error) {
+	if !operator.IsMulti() && len(raw) != 1 {
+		return nil, fmt.Errorf("operator %q requires a single value", operator)
+	}
+
+	values := make([]any, 0, len(raw))
+	for _, item := range raw {
+		var (
+			value any
+			err   error
+		)
+
+		switch operator {
+		case OpNull:
+			value, err = strconv.ParseBool(item)
+		case OpLike:
+			value, err = item, nonEmpty(item)
+		case OpRegex:
+			_, err = regexp.Compile(item)
+			value = item
+		default:
+			value, err = f.parse(item)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q: %w", item, err)
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func (f Field) parse(raw string) (any, error) {
+	switch f.Type {
+	case TypeString:
+		return raw, nil
+	case TypeInt:
+		return strconv.ParseInt(raw, 10, 64)
+	case TypeFloat:
+		return strconv.ParseFloat(raw, 64)
+	case TypeBool:
+		return strconv.ParseBool(raw)
+	case TypeTime:
+		return parseTime(raw)
+	case TypeUUID:
+		return uuid.Parse(raw)
+	default:
+		return nil, fmt.Errorf("unsupported field type %q", f.Type)
+	}
+}
+
+func parseTime(raw string) (time.Time, error) {
+	if !strings.Contains(raw, "T") {
+		return time.Parse(time.DateOnly, raw)
+	}
+
+	return time.Parse(time.RFC3339Nano, raw)
+}
+
+func nonEmpty(raw string) error {
+	if raw == "" {
+		return errors.New("empty value")
+	}
+
+	return nil
+}
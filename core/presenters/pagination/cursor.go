@@ -0,0 +1,412 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wal1251/pkg/tools/collections"
+	"github.com/wal1251/pkg/tools/crypto"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor") // Задан некорректный или поддельный курсор.
+
+type (
+	// CursorQuery хранит параметры курсорной (keyset) постраничной выдачи. В отличие от PageParams, выборка следующей
+	// страницы не зависит от количества предшествующих элементов и не смещается при вставке новых.
+	//
+	// Курсор непрозрачен для клиента: он содержит значения ключей сортировки граничного элемента страницы и
+	// подписывается CursorSigner, поэтому клиент не может его подменить.
+	CursorQuery struct {
+		Cursor string `json:"cursor,omitempty"` // Курсор из CursorPage.Next или CursorPage.Previous, пустой для первой страницы.
+		Limit  int    `json:"limit"`
+	}
+
+	// CursorSigner подписывает и проверяет курсоры. Курсор привязан к набору ключей сортировки, курсор, выданный для
+	// другой сортировки, считается некорректным.
+	CursorSigner struct {
+		hmac *crypto.Config
+	}
+
+	// Keyset параметры выборки страницы курсорной пагинации, полученные из CursorQuery. Адаптеры хранилищ строят по
+	// нему условие (см. Terms) и порядок выборки (см. Order).
+	Keyset struct {
+		Keys     []SortKey // Ключи сортировки выдачи.
+		Values   []any     // Значения ключей граничного элемента, nil для первой страницы.
+		Backward bool      // Выборка предыдущей страницы: элементы выбираются в обратном порядке от граничного.
+		Limit    int       // Размер страницы.
+	}
+
+	// KeysetTerm одно из условий, объединяемых через ИЛИ: значения полей Equal равны значениям курсора, а значение
+	// поля Field строго больше (Greater) или меньше значения Value.
+	KeysetTerm struct {
+		Equal   []KeyValue
+		Field   string
+		Value   any
+		Greater bool
+	}
+
+	// KeyValue значение поля.
+	KeyValue struct {
+		Field string
+		Value any
+	}
+
+	// CursorPage представляет страницу курсорной выдачи.
+	CursorPage[T any] struct {
+		Content  []*T   `json:"content"`
+		Next     string `json:"next,omitempty"`     // Курсор следующей страницы, пустой для последней страницы.
+		Previous string `json:"previous,omitempty"` // Курсор предыдущей страницы, пустой для первой страницы.
+		Limit    int    `json:"limit"`
+	}
+
+	cursorPayload struct {
+		Sort     string        `json:"s"`
+		Values   []cursorValue `json:"v"`
+		Backward bool          `json:"b,omitempty"`
+	}
+
+	// cursorValue значение ключа с типом, позволяющим восстановить его при чтении курсора.
+	cursorValue struct {
+		Type  string          `json:"t,omitempty"`
+		Value json.RawMessage `json:"v,omitempty"`
+	}
+)
+
+// GetLimit возвращает максимальный размер выдачи результата.
+func (q *CursorQuery) GetLimit() int {
+	return q.Limit
+}
+
+// Validate выполнит проверку заданных параметров.
+func (q *CursorQuery) Validate() error {
+	if q.Limit < 0 {
+		return fmt.Errorf("%w: is negative", ErrInvalidLimit)
+	}
+
+	return nil
+}
+
+// WithLimit устанавливает максимальное количество элементов, возвращаемых в выборке.
+func (q *CursorQuery) WithLimit(limit *int) *CursorQuery {
+	q.Limit = DefaultLimit
+	if limit != nil {
+		q.Limit = *limit
+	}
+
+	return q
+}
+
+// WithCursor устанавливает курсор страницы.
+func (q *CursorQuery) WithCursor(cursor *string) *CursorQuery {
+	q.Cursor = ""
+	if cursor != nil {
+		q.Cursor = *cursor
+	}
+
+	return q
+}
+
+// Keyset проверяет курсор и возвращает параметры выборки страницы с ключами сортировки keys. Вернет ошибку
+// ErrInvalidCursor, если курсор поврежден, подписан другим ключом или выдан для другой сортировки.
+func (q *CursorQuery) Keyset(signer *CursorSigner, keys []SortKey) (*Keyset, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	keyset := &Keyset{Keys: keys, Limit: q.Limit}
+	if q.Cursor == "" {
+		return keyset, nil
+	}
+
+	payload, err := signer.decode(keys, q.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	keyset.Backward = payload.Backward
+	keyset.Values = make([]any, len(payload.Values))
+
+	for i, value := range payload.Values {
+		if keyset.Values[i], err = value.decode(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+		}
+	}
+
+	return keyset, nil
+}
+
+// Order возвращает порядок выборки: ключи сортировки, а для предыдущей страницы - ключи с обратным направлением.
+func (k *Keyset) Order() []SortKey {
+	if !k.Backward {
+		return k.Keys
+	}
+
+	order := make([]SortKey, len(k.Keys))
+	for i, key := range k.Keys {
+		order[i] = SortKey{Field: key.Field, Direction: key.Direction.Reverse(), Nulls: key.Nulls.Reverse()}
+	}
+
+	return order
+}
+
+// Terms возвращает условия выборки элементов после граничного элемента в порядке Order, условия объединяются через
+// ИЛИ:
+//
+//	(k1 > v1) OR (k1 = v1 AND k2 > v2) OR (k1 = v1 AND k2 = v2 AND k3 > v3) ...
+//
+// Для первой страницы вернет nil.
+func (k *Keyset) Terms() []KeysetTerm {
+	if k.Values == nil {
+		return nil
+	}
+
+	order := k.Order()
+	terms := make([]KeysetTerm, len(order))
+
+	for i, key := range order {
+		equal := make([]KeyValue, i)
+		for j := range equal {
+			equal[j] = KeyValue{Field: order[j].Field, Value: k.Values[j]}
+		}
+
+		terms[i] = KeysetTerm{Equal: equal, Field: key.Field, Value: k.Values[i], Greater: key.Direction != SortDesc}
+	}
+
+	return terms
+}
+
+// FetchLimit возвращает количество элементов, которое необходимо выбрать из хранилища: на один больше размера
+// страницы, чтобы определить наличие следующей страницы.
+func (k *Keyset) FetchLimit() int {
+	return k.Limit + 1
+}
+
+// Encode возвращает подписанный курсор для значений ключей сортировки values. Значения-указатели разыменовываются.
+// Пустые значения (nil) не поддерживаются, см. SortKeys: вернет ошибку ErrInvalidCursor.
+func (s *CursorSigner) Encode(keys []SortKey, values []any, backward bool) (string, error) {
+	if len(values) != len(keys) {
+		return "", fmt.Errorf("%w: expected %d values, got %d", ErrInvalidCursor, len(keys), len(values))
+	}
+
+	payload := cursorPayload{Sort: sortFingerprint(keys), Values: make([]cursorValue, len(values)), Backward: backward}
+
+	for i, value := range values {
+		encoded, err := encodeCursorValue(value)
+		if err != nil {
+			return "", fmt.Errorf("key %s: %w", keys[i].Field, err)
+		}
+
+		payload.Values[i] = encoded
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("can't encode cursor: %w", err)
+	}
+
+	message := base64.RawURLEncoding.EncodeToString(data)
+
+	return message + "." + s.hmac.Sign(message), nil
+}
+
+func (s *CursorSigner) decode(keys []SortKey, cursor string) (*cursorPayload, error) {
+	message, signature, ok := strings.Cut(cursor, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.hmac.Sign(message))) {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidCursor)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(message)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	var payload cursorPayload
+	if err = json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if payload.Sort != sortFingerprint(keys) || len(payload.Values) != len(keys) {
+		return nil, fmt.Errorf("%w: sorting has changed", ErrInvalidCursor)
+	}
+
+	return &payload, nil
+}
+
+// NewCursorSigner возвращает новый CursorSigner с секретным ключом подписи secret.
+func NewCursorSigner(secret string) *CursorSigner {
+	return &CursorSigner{hmac: crypto.NewHMAC(secret)}
+}
+
+// NewCursorPage формирует страницу из элементов items, выбранных в порядке keyset.Order() с ограничением
+// keyset.FetchLimit(). Функция valuesOf возвращает значения ключей сортировки элемента в порядке keyset.Keys.
+func NewCursorPage[T any](items []*T, keyset *Keyset, signer *CursorSigner, valuesOf func(*T) []any) (*CursorPage[T], error) {
+	more := len(items) > keyset.Limit
+	if more {
+		items = items[:keyset.Limit]
+	}
+
+	content := slices.Clone(items)
+	if keyset.Backward {
+		slices.Reverse(content)
+	}
+
+	page := &CursorPage[T]{Content: content, Limit: keyset.Limit}
+	if len(content) == 0 {
+		return page, nil
+	}
+
+	var (
+		err         error
+		hasNext     = more || keyset.Backward
+		hasPrevious = (more && keyset.Backward) || (!keyset.Backward && keyset.Values != nil)
+	)
+
+	// Если выбиралась предыдущая страница, следующая за ней заведомо есть, и наоборот.
+	if hasNext {
+		if page.Next, err = signer.Encode(keyset.Keys, valuesOf(content[len(content)-1]), false); err != nil {
+			return nil, err
+		}
+	}
+
+	if hasPrevious {
+		if page.Previous, err = signer.Encode(keyset.Keys, valuesOf(content[0]), true); err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// SortKeys возвращает ключи сортировки для курсорной пагинации по параметрам сортировки sort, с направлением
+// каждого поля, если sort поддерживает SortKeysQuery. Поля tieBreakers (например, первичный ключ) добавляются в конец
+// с направлением последнего ключа, если их нет среди полей сортировки: порядок выдачи должен быть однозначным, иначе
+// элементы с равными значениями ключей могут быть пропущены.
+//
+// Курсорная пагинация не поддерживает пустые значения ключей сортировки: условия выборки следующей страницы
+// сравнивают значения строго, а пустое значение не равно и не больше никакого другого. Поэтому CursorSigner.Encode
+// возвращает ошибку ErrInvalidCursor для пустых значений, а поля, допускающие пустые значения, не следует использовать
+// как ключи курсорной пагинации (или нужно заменять пустые значения в запросе, например, COALESCE).
+func SortKeys(sort SortQuery, tieBreakers ...string) []SortKey {
+	var keys []SortKey
+
+	if keysQuery, ok := sort.(SortKeysQuery); ok {
+		keys = keysQuery.GetKeys()
+	} else {
+		keys = collections.Map(sort.GetFields(), func(field string) SortKey {
+			return SortKey{Field: field, Direction: sort.GetDirection()}
+		})
+	}
+
+	direction := sort.GetDirection()
+	if len(keys) > 0 {
+		direction = keys[len(keys)-1].Direction
+	}
+
+	for _, field := range tieBreakers {
+		if !slices.ContainsFunc(keys, func(key SortKey) bool { return key.Field == field }) {
+			keys = append(keys, SortKey{Field: field, Direction: direction})
+		}
+	}
+
+	return keys
+}
+
+func sortFingerprint(keys []SortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key.Field + ":" + string(key.Direction) + ":" + string(key.Nulls)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func encodeCursorValue(value any) (cursorValue, error) {
+	var typ string
+
+	if reflected := reflect.ValueOf(value); reflected.Kind() == reflect.Pointer && !reflected.IsNil() {
+		return encodeCursorValue(reflected.Elem().Interface())
+	}
+
+	switch typed := value.(type) {
+	case nil:
+		return cursorValue{}, fmt.Errorf("%w: empty value is not supported", ErrInvalidCursor)
+	case string:
+		typ = "s"
+	case int, int8, int16, int32, int64:
+		typ = "i"
+	case uint, uint8, uint16, uint32, uint64:
+		typ = "u"
+	case float32, float64:
+		typ = "f"
+	case bool:
+		typ = "b"
+	case time.Time:
+		typ, value = "t", typed.Format(time.RFC3339Nano)
+	case uuid.UUID:
+		typ, value = "id", typed.String()
+	default:
+		if reflected := reflect.ValueOf(value); reflected.Kind() == reflect.Pointer && reflected.IsNil() {
+			return cursorValue{}, fmt.Errorf("%w: empty value is not supported", ErrInvalidCursor)
+		}
+
+		typ = "j"
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return cursorValue{}, fmt.Errorf("can't encode cursor value %v: %w", value, err)
+	}
+
+	return cursorValue{Type: typ, Value: data}, nil
+}
+
+func (v cursorValue) decode() (any, error) {
+	switch v.Type {
+	case "s":
+		return unmarshalCursorValue[string](v.Value)
+	case "i":
+		return unmarshalCursorValue[int64](v.Value)
+	case "u":
+		return unmarshalCursorValue[uint64](v.Value)
+	case "f":
+		return unmarshalCursorValue[float64](v.Value)
+	case "b":
+		return unmarshalCursorValue[bool](v.Value)
+	case "t":
+		text, err := unmarshalCursorValue[string](v.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return time.Parse(time.RFC3339Nano, text) //nolint:wrapcheck
+	case "id":
+		text, err := unmarshalCursorValue[string](v.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return uuid.Parse(text) //nolint:wrapcheck
+	case "j":
+		return unmarshalCursorValue[any](v.Value)
+	default:
+		return nil, fmt.Errorf("unknown value type %q", v.Type)
+	}
+}
+
+func unmarshalCursorValue[T any](data json.RawMessage) (T, error) {
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("can't decode cursor value: %w", err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,228 @@
+package pagination_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/presenters/pagination"
+)
+
+type cursorItem struct {
+	ID      int64
+	Created time.Time
+}
+
+func cursorValues(item *cursorItem) []any {
+	return []any{item.Created, item.ID}
+}
+
+// fetch имитирует выборку из хранилища: фильтрует и упорядочивает элементы по keyset.
+func fetch(t *testing.T, items []*cursorItem, keyset *pagination.Keyset) []*cursorItem {
+	t.Helper()
+
+	var result []*cursorItem
+
+	for _, item := range items {
+		if keyset.Values == nil || after(item, keyset) {
+			result = append(result, item)
+		}
+	}
+
+	if keyset.Backward {
+		reversed := make([]*cursorItem, 0, len(result))
+		for i := len(result) - 1; i >= 0; i-- {
+			reversed = append(reversed, result[i])
+		}
+		result = reversed
+	}
+
+	return result[:min(len(result), keyset.FetchLimit())]
+}
+
+func after(item *cursorItem, keyset *pagination.Keyset) bool {
+	created, ok := keyset.Values[0].(time.Time)
+	if !ok {
+		panic("unexpected cursor value")
+	}
+
+	id, ok := keyset.Values[1].(int64)
+	if !ok {
+		panic("unexpected cursor value")
+	}
+
+	cmp := item.Created.Compare(created)
+	if cmp == 0 {
+		cmp = int(item.ID - id)
+	}
+
+	if keyset.Backward {
+		return cmp < 0
+	}
+
+	return cmp > 0
+}
+
+func TestCursorPage(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	items := make([]*cursorItem, 7)
+
+	for i := range items {
+		// Элементы с равным временем создания упорядочиваются по идентификатору.
+		items[i] = &cursorItem{ID: int64(i + 1), Created: base.Add(time.Duration(i/2) * time.Hour)}
+	}
+
+	signer := pagination.NewCursorSigner("secret")
+	sorting := pagination.NewSorting([]string{"created"}, nil).WithAcceptableFields("created")
+	keys := pagination.SortKeys(sorting, "id")
+
+	require.Equal(t, []pagination.SortKey{
+		{Field: "created", Direction: pagination.SortAsc},
+		{Field: "id", Direction: pagination.SortAsc},
+	}, keys)
+
+	limit := 3
+	query := (&pagination.CursorQuery{}).WithLimit(&limit)
+
+	var pages [][]int64
+
+	var lastPage *pagination.CursorPage[cursorItem]
+
+	for {
+		keyset, err := query.Keyset(signer, keys)
+		require.NoError(t, err)
+
+		page, err := pagination.NewCursorPage(fetch(t, items, keyset), keyset, signer, cursorValues)
+		require.NoError(t, err)
+
+		var ids []int64
+		for _, item := range page.Content {
+			ids = append(ids, item.ID)
+		}
+		pages = append(pages, ids)
+
+		if len(pages) > 1 {
+			assert.NotEmpty(t, page.Previous)
+		}
+
+		lastPage = page
+		if page.Next == "" {
+			break
+		}
+
+		query.Cursor = page.Next
+	}
+
+	assert.Equal(t, [][]int64{{1, 2, 3}, {4, 5, 6}, {7}}, pages)
+
+	// Возврат на предыдущие страницы.
+	query.Cursor = lastPage.Previous
+	keyset, err := query.Keyset(signer, keys)
+	require.NoError(t, err)
+
+	page, err := pagination.NewCursorPage(fetch(t, items, keyset), keyset, signer, cursorValues)
+	require.NoError(t, err)
+	assert.Equal(t, []*cursorItem{items[3], items[4], items[5]}, page.Content)
+	assert.NotEmpty(t, page.Next)
+
+	query.Cursor = page.Previous
+	keyset, err = query.Keyset(signer, keys)
+	require.NoError(t, err)
+
+	page, err = pagination.NewCursorPage(fetch(t, items, keyset), keyset, signer, cursorValues)
+	require.NoError(t, err)
+	assert.Equal(t, []*cursorItem{items[0], items[1], items[2]}, page.Content)
+	assert.Empty(t, page.Previous)
+}
+
+func TestCursorQuery_Keyset(t *testing.T) {
+	signer := pagination.NewCursorSigner("secret")
+	keys := []pagination.SortKey{{Field: "name", Direction: pagination.SortDesc}, {Field: "id", Direction: pagination.SortAsc}}
+	id := uuid.New()
+
+	cursor, err := signer.Encode(keys, []any{"foo", id}, false)
+	require.NoError(t, err)
+
+	keyset, err := (&pagination.CursorQuery{Cursor: cursor, Limit: 10}).Keyset(signer, keys)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"foo", id}, keyset.Values)
+	assert.Equal(t, []pagination.KeysetTerm{
+		{Equal: []pagination.KeyValue{}, Field: "name", Value: "foo", Greater: false},
+		{Equal: []pagination.KeyValue{{Field: "name", Value: "foo"}}, Field: "id", Value: id, Greater: true},
+	}, keyset.Terms())
+
+	tests := []struct {
+		name   string
+		signer *pagination.CursorSigner
+		keys   []pagination.SortKey
+		cursor string
+	}{
+		{
+			name:   "Другой ключ подписи",
+			signer: pagination.NewCursorSigner("other"),
+			keys:   keys,
+			cursor: cursor,
+		},
+		{
+			name:   "Другая сортировка",
+			signer: signer,
+			keys:   []pagination.SortKey{{Field: "name", Direction: pagination.SortAsc}, {Field: "id", Direction: pagination.SortAsc}},
+			cursor: cursor,
+		},
+		{
+			name:   "Поврежденный курсор",
+			signer: signer,
+			keys:   keys,
+			cursor: "x" + cursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&pagination.CursorQuery{Cursor: tt.cursor}).Keyset(tt.signer, tt.keys)
+			assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+		})
+	}
+}
+
+func TestCursorSigner_Encode_nullable(t *testing.T) {
+	signer := pagination.NewCursorSigner("secret")
+	keys := []pagination.SortKey{
+		{Field: "deleted", Direction: pagination.SortAsc, Nulls: pagination.NullsLast},
+		{Field: "id", Direction: pagination.SortAsc},
+	}
+	deleted := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   any
+		want    any
+		wantErr bool
+	}{
+		{name: "Значение", value: deleted, want: deleted},
+		{name: "Указатель на значение", value: &deleted, want: deleted},
+		{name: "Пустое значение", value: nil, wantErr: true},
+		{name: "Пустой указатель", value: (*time.Time)(nil), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := signer.Encode(keys, []any{tt.value, int64(1)}, false)
+			if tt.wantErr {
+				require.ErrorIs(t, err, pagination.ErrInvalidCursor)
+				assert.Contains(t, err.Error(), "deleted")
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			keyset, err := (&pagination.CursorQuery{Cursor: cursor, Limit: 10}).Keyset(signer, keys)
+			require.NoError(t, err)
+			assert.Equal(t, []any{tt.want, int64(1)}, keyset.Values)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package pagination
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/wal1251/pkg/tools/collections"
@@ -13,19 +14,30 @@ const (
 	SortDesc SortDirection = "desc" // Сортировка элементов по убыванию.
 
 	DefaultSortDirection = SortAsc // Порядок сортировки по умолчанию.
+
+	NullsDefault NullsOrder = ""      // Положение пустых значений определяется хранилищем.
+	NullsFirst   NullsOrder = "first" // Пустые значения в начале выдачи.
+	NullsLast    NullsOrder = "last"  // Пустые значения в конце выдачи.
 )
 
 var (
 	ErrInvalidSortDirection = errors.New("invalid sorting Direction")   // Некорректный параметр направления сортировки.
 	ErrInvalidSortField     = errors.New("invalid sorting mappedField") // Некорректное поле сортировки.
+	ErrInvalidSortNulls     = errors.New("invalid sorting nulls order") // Некорректный параметр положения пустых значений.
 )
 
-var _ SortQuery = (*SortParams)(nil)
+var (
+	_ SortQuery     = (*SortParams)(nil)
+	_ SortKeysQuery = (*SortParams)(nil)
+)
 
 type (
 	// SortDirection порядок сортировки выдачи.
 	SortDirection string
 
+	// NullsOrder положение пустых значений в выдаче.
+	NullsOrder string
+
 	// SortQuery запрос порядка выдачи элементов в представлении коллекции.
 	SortQuery interface {
 		GetDirection() SortDirection // Вернет порядок сортировки элементов.
@@ -33,12 +45,29 @@ type (
 		Validate() error             // Выполнит проверку заданного параметров.
 	}
 
-	// SortParams хранит параметры сортировки выдачи представления коллекции. Поддерживает интерфейс SortQuery.
+	// SortKey поле сортировки с направлением и положением пустых значений. Последовательность ключей задает порядок
+	// выдачи, см. SortKeysQuery и SortKeys.
+	SortKey struct {
+		Field     string
+		Direction SortDirection
+		Nulls     NullsOrder
+	}
+
+	// SortKeysQuery запрос порядка выдачи с направлением сортировки для каждого поля.
+	SortKeysQuery interface {
+		SortQuery
+		GetKeys() []SortKey // Вернет поля сортировки с направлениями.
+	}
+
+	// SortParams хранит параметры сортировки выдачи представления коллекции. Поддерживает интерфейсы SortQuery и
+	// SortKeysQuery.
 	SortParams struct {
 		Direction        SortDirection
 		Fields           []string
 		FieldsAcceptable collections.Set[string]
 		FieldsMapping    map[string]string
+		Directions       map[string]SortDirection // Направления сортировки отдельных полей, по умолчанию Direction.
+		Nulls            NullsOrder               // Положение пустых значений.
 	}
 )
 
@@ -51,6 +80,36 @@ func (d SortDirection) Validate() error {
 	return fmt.Errorf("%w: %s", ErrInvalidSortDirection, d)
 }
 
+// Reverse возвращает обратный порядок сортировки.
+func (d SortDirection) Reverse() SortDirection {
+	if d == SortDesc {
+		return SortAsc
+	}
+
+	return SortDesc
+}
+
+// Validate вернет ошибку, если значение положения пустых значений является некорректным значением.
+func (n NullsOrder) Validate() error {
+	if n == NullsDefault || n == NullsFirst || n == NullsLast {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrInvalidSortNulls, n)
+}
+
+// Reverse возвращает положение пустых значений для обратного порядка сортировки.
+func (n NullsOrder) Reverse() NullsOrder {
+	switch n {
+	case NullsFirst:
+		return NullsLast
+	case NullsLast:
+		return NullsFirst
+	default:
+		return n
+	}
+}
+
 // GetDirection см. SortQuery.GetDirection().
 func (s *SortParams) GetDirection() SortDirection {
 	return s.Direction
@@ -61,12 +120,29 @@ func (s *SortParams) GetFields() []string {
 	return collections.Map(s.Fields, s.mappedField)
 }
 
+// GetKeys см. SortKeysQuery.GetKeys().
+func (s *SortParams) GetKeys() []SortKey {
+	return collections.Map(s.Fields, func(field string) SortKey {
+		return SortKey{Field: s.mappedField(field), Direction: s.fieldDirection(field), Nulls: s.Nulls}
+	})
+}
+
 // Validate см. SortQuery.Validate().
 func (s *SortParams) Validate() error {
 	if err := s.Direction.Validate(); err != nil {
 		return err
 	}
 
+	for _, direction := range s.Directions {
+		if err := direction.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.Nulls.Validate(); err != nil {
+		return err
+	}
+
 	if !s.FieldsAcceptable.Contains(s.Fields...) {
 		return ErrInvalidSortField
 	}
@@ -97,6 +173,32 @@ func (s *SortParams) WithDefaultFields(fields ...string) *SortParams {
 	return s
 }
 
+// WithFieldDirection устанавливает направление сортировки поля.
+func (s *SortParams) WithFieldDirection(field string, direction SortDirection) *SortParams {
+	if s.Directions == nil {
+		s.Directions = make(map[string]SortDirection)
+	}
+
+	s.Directions[field] = direction
+
+	return s
+}
+
+// WithNulls устанавливает положение пустых значений.
+func (s *SortParams) WithNulls(nulls NullsOrder) *SortParams {
+	s.Nulls = nulls
+
+	return s
+}
+
+func (s *SortParams) fieldDirection(field string) SortDirection {
+	if direction, ok := s.Directions[field]; ok {
+		return direction
+	}
+
+	return s.Direction
+}
+
 func (s *SortParams) mappedField(field string) string {
 	if m, ok := s.FieldsMapping[field]; ok {
 		return m
@@ -119,3 +221,69 @@ func NewSorting(fields []string, direction *string) *SortParams {
 		FieldsMapping:    make(map[string]string),
 	}
 }
+
+// ParseSorting создает объект с параметрами сортировки из значений параметра запроса sort. Поддерживаются записи
+// через запятую или несколькими значениями:
+//
+//	sort=-priority,created         // "-" - по убыванию, "+" или без префикса - по возрастанию
+//	sort=priority:desc,created:asc
+//
+// Допустимые поля и их маппинг задаются после разбора, см. WithAcceptableFields и WithMapping. Вернет ошибку
+// ErrInvalidSortField или ErrInvalidSortDirection, если значение записано некорректно.
+func ParseSorting(values ...string) (*SortParams, error) {
+	sorting := NewSorting(nil, nil)
+
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+
+			field, direction, err := parseSortItem(item)
+			if err != nil {
+				return nil, err
+			}
+
+			if slices.Contains(sorting.Fields, field) {
+				return nil, fmt.Errorf("%w: %s is repeated", ErrInvalidSortField, field)
+			}
+
+			sorting.Fields = append(sorting.Fields, field)
+			sorting.WithFieldDirection(field, direction)
+		}
+	}
+
+	return sorting, nil
+}
+
+func parseSortItem(item string) (string, SortDirection, error) {
+	direction := DefaultSortDirection
+	prefix := item[0] == '-' || item[0] == '+'
+
+	if prefix {
+		if item[0] == '-' {
+			direction = SortDesc
+		}
+
+		item = item[1:]
+	}
+
+	field, suffix, found := strings.Cut(item, ":")
+	if field == "" || strings.ContainsAny(field, "+-: ") {
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidSortField, item)
+	}
+
+	if found {
+		if prefix {
+			// Направление задано дважды, например, "-priority:asc".
+			return "", "", fmt.Errorf("%w: %s", ErrInvalidSortDirection, item)
+		}
+
+		direction = SortDirection(strings.ToLower(suffix))
+		if err := direction.Validate(); err != nil {
+			return "", "", err
+		}
+	}
+
+	return field, direction, nil
+}
@@ -0,0 +1,83 @@
+package pagination_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/presenters/pagination"
+)
+
+func TestParseSorting(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    []pagination.SortKey
+		wantErr error
+	}{
+		{
+			name:   "Префиксы направления",
+			values: []string{"-priority,created,+name"},
+			want: []pagination.SortKey{
+				{Field: "priority", Direction: pagination.SortDesc},
+				{Field: "created_at", Direction: pagination.SortAsc},
+				{Field: "name", Direction: pagination.SortAsc},
+			},
+		},
+		{
+			name:   "Суффиксы направления в нескольких значениях",
+			values: []string{"priority:DESC", "created:asc"},
+			want: []pagination.SortKey{
+				{Field: "priority", Direction: pagination.SortDesc},
+				{Field: "created_at", Direction: pagination.SortAsc},
+			},
+		},
+		{
+			name:   "Без сортировки",
+			values: []string{""},
+			want:   []pagination.SortKey{},
+		},
+		{
+			name:    "Некорректное направление",
+			values:  []string{"priority:up"},
+			wantErr: pagination.ErrInvalidSortDirection,
+		},
+		{
+			name:    "Противоречивое направление",
+			values:  []string{"-priority:asc"},
+			wantErr: pagination.ErrInvalidSortDirection,
+		},
+		{
+			name:    "Повтор поля",
+			values:  []string{"priority,-priority"},
+			wantErr: pagination.ErrInvalidSortField,
+		},
+		{
+			name:    "Недопустимое поле",
+			values:  []string{"secret"},
+			wantErr: pagination.ErrInvalidSortField,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorting, err := pagination.ParseSorting(tt.values...)
+			if err == nil {
+				err = sorting.
+					WithAcceptableFields("priority", "created", "name").
+					WithMapping(map[string]string{"created": "created_at"}).
+					Validate()
+			}
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, sorting.GetKeys())
+		})
+	}
+}
@@ -3,9 +3,7 @@ package presenters
 import (
 	"bytes"
 	"context"
-	"fmt"
 	"reflect"
-	"regexp"
 	"strings"
 
 	"github.com/wal1251/pkg/tools/serial"
@@ -41,14 +39,8 @@ func ParameterView(param any, view ViewType, options ViewOptions) string {
 		}
 	}
 
-	var buffer StringPresenterWriter
-	if options.MaxStringLength == 0 {
-		buffer = bytes.NewBufferString("")
-	} else {
-		buffer = NewCropper(options.MaxStringLength)
-	}
-
-	if err := serial.JSONEncode(buffer, param); err != nil {
+	raw, err := serial.ToBytes(param, serial.JSONEncode[any])
+	if err != nil {
 		typ := reflect.TypeOf(param)
 		if typ == nil {
 			builder.WriteString("***FAILED TO REPRESENT***")
@@ -59,7 +51,20 @@ func ParameterView(param any, view ViewType, options ViewOptions) string {
 		return builder.String()
 	}
 
-	builder.WriteString(JSONString(strings.TrimSuffix(buffer.String(), "\n"), view, options))
+	raw = bytes.TrimSuffix(raw, []byte("\n"))
+	if view == ViewLogs || view == ViewPublic {
+		raw = NewRedactor(options).WithTagsOf(param).RedactJSON(raw)
+	}
+
+	if options.MaxStringLength == 0 {
+		builder.Write(raw)
+
+		return builder.String()
+	}
+
+	cropper := NewCropper(options.MaxStringLength)
+	_, _ = cropper.Write(raw)
+	builder.WriteString(cropper.String())
 
 	return builder.String()
 }
@@ -91,11 +96,7 @@ func JSONString(value string, view ViewType, options ViewOptions) string {
 	return value
 }
 
+// JSONHideCredentials скрывает в JSON строке значения атрибутов, перечисленных в options.SecuredKeywords, см. Redactor.
 func JSONHideCredentials(value string, options ViewOptions) string {
-	for _, keyword := range options.SecuredKeywords {
-		re := regexp.MustCompile(fmt.Sprintf(`(?mi)("%s"\s*:\s*").*?(")`, keyword))
-		value = re.ReplaceAllString(value, fmt.Sprintf(`$1%s$2`, DefaultCredentialsPlaceholder))
-	}
-
-	return value
+	return string(NewRedactor(options).RedactJSON([]byte(value)))
 }
@@ -23,8 +23,9 @@ type (
 
 	// ViewOptions дополнительные параметры формирования представления объекта.
 	ViewOptions struct {
-		SecuredKeywords []string // Атрибуты с этими полями необходимо скрывать от пользователей.
-		MaxStringLength int      // Максимальная длина представления объекта. Накладно выводить большие объекты целиком.
+		SecuredKeywords []string   // Атрибуты с этими полями необходимо скрывать от пользователей.
+		MaxStringLength int        // Максимальная длина представления объекта. Накладно выводить большие объекты целиком.
+		Detectors       []Detector // Детекторы чувствительных данных в строковых значениях, см. Redactor.
 	}
 
 	// StringViewer объект реализующий интерфейс осуществляет поддержку механизма формирования строчных представлений
@@ -50,10 +51,16 @@ type (
 )
 
 func NewViewOptions(cfg *Config) ViewOptions {
-	return ViewOptions{
+	options := ViewOptions{
 		SecuredKeywords: cfg.SecuredKeywords,
 		MaxStringLength: cfg.MaxStringLength,
 	}
+
+	if cfg.MaskPII {
+		options.Detectors = PIIDetectors()
+	}
+
+	return options
 }
 
 func DefaultViewOptions() ViewOptions {
@@ -0,0 +1,526 @@
+package presenters
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	LogTag       = "log"    // Тэг поля структуры, определяющий представление поля в логах.
+	LogTagSecret = "secret" // Значение поля скрывается целиком.
+	LogTagMask   = "mask"   // Значение поля маскируется частично, см. MaskString.
+
+	maskChar = '*'
+)
+
+var (
+	emailPattern = regexp.MustCompile(`^[^@\s"]+@[^@\s"]+\.[^@\s"]+$`)
+	phonePattern = regexp.MustCompile(`^\+?[\d\s\-()]{10,20}$`)
+
+	taggedKeysCache sync.Map //nolint:gochecknoglobals
+)
+
+type (
+	// Detector проверяет строковое значение и, если значение содержит чувствительные данные, возвращает его
+	// замаскированное представление и true.
+	Detector func(value string) (string, bool)
+
+	// Redactor скрывает чувствительные данные в JSON, разбирая его структуру: значения атрибутов, названия которых
+	// совпадают с SecuredKeywords (без учета регистра), заменяются на DefaultCredentialsPlaceholder, независимо от типа
+	// значения (строка, число, массив, объект). Поля структур, помеченные тэгом `log:"secret"` или `log:"mask"`,
+	// соответственно скрываются или маскируются. Строковые значения проверяются детекторами (см. Detector).
+	// Форматирование исходного JSON сохраняется.
+	Redactor struct {
+		secrets   []string
+		masks     []string
+		detectors []Detector
+	}
+
+	taggedKeys struct {
+		secrets []string
+		masks   []string
+	}
+
+	redactMode int
+
+	redactScanner struct {
+		redactor *Redactor
+		src      []byte
+		dst      []byte
+		pos      int
+	}
+)
+
+const (
+	redactNone redactMode = iota
+	redactMask
+)
+
+// NewRedactor возвращает новый экземпляр Redactor с параметрами из options.
+func NewRedactor(options ViewOptions) *Redactor {
+	return &Redactor{
+		secrets:   options.SecuredKeywords,
+		detectors: options.Detectors,
+	}
+}
+
+// WithTagsOf возвращает Redactor, который дополнительно скрывает и маскирует атрибуты, соответствующие полям типа
+// value, помеченным тэгом log.
+func (r *Redactor) WithTagsOf(value any) *Redactor {
+	if value == nil {
+		return r
+	}
+
+	keys := tagsOf(reflect.TypeOf(value))
+	if len(keys.secrets) == 0 && len(keys.masks) == 0 {
+		return r
+	}
+
+	return &Redactor{
+		secrets:   append(append(make([]string, 0, len(r.secrets)+len(keys.secrets)), r.secrets...), keys.secrets...),
+		masks:     append(append(make([]string, 0, len(r.masks)+len(keys.masks)), r.masks...), keys.masks...),
+		detectors: r.detectors,
+	}
+}
+
+// IsSecret вернет true, если значение атрибута с названием key необходимо скрыть.
+func (r *Redactor) IsSecret(key string) bool {
+	return matchKey(r.secrets, key)
+}
+
+// Redact возвращает JSON представление value со скрытыми чувствительными данными.
+func (r *Redactor) Redact(value any) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return r.WithTagsOf(value).RedactJSON(data), nil
+}
+
+// RedactJSON возвращает копию data со скрытыми чувствительными данными. Если data не является корректным JSON
+// (например, обрезан), то обрабатывается корректная часть, остаток копируется как есть. Обрезанное скрываемое значение
+// отбрасывается целиком.
+func (r *Redactor) RedactJSON(data []byte) []byte {
+	scanner := redactScanner{redactor: r, src: data, dst: make([]byte, 0, len(data))}
+
+	return scanner.scan()
+}
+
+func (r *Redactor) detect(value string) (string, bool) {
+	for _, detector := range r.detectors {
+		if masked, ok := detector(value); ok {
+			return masked, true
+		}
+	}
+
+	return value, false
+}
+
+// MaskString частично маскирует строку: оставляет видимыми по четверти символов в начале и конце строки. Строки не
+// длиннее 4 символов маскируются целиком.
+func MaskString(value string) string {
+	length := utf8.RuneCountInString(value)
+	keep := length / 4 //nolint:gomnd
+
+	if length <= 4 { //nolint:gomnd
+		keep = 0
+	}
+
+	var builder strings.Builder
+
+	idx := 0
+	for _, char := range value {
+		if idx < keep || idx >= length-keep {
+			builder.WriteRune(char)
+		} else {
+			builder.WriteRune(maskChar)
+		}
+		idx++
+	}
+
+	return builder.String()
+}
+
+// DetectEmail детектор адресов электронной почты, маскирует имя ящика.
+func DetectEmail(value string) (string, bool) {
+	if !emailPattern.MatchString(value) {
+		return value, false
+	}
+
+	at := strings.LastIndexByte(value, '@')
+
+	return MaskString(value[:at]) + value[at:], true
+}
+
+// DetectPhone детектор номеров телефонов, маскирует середину номера.
+func DetectPhone(value string) (string, bool) {
+	if !phonePattern.MatchString(value) {
+		return value, false
+	}
+
+	digits := 0
+	for _, char := range value {
+		if char >= '0' && char <= '9' {
+			digits++
+		}
+	}
+
+	if digits < 10 { //nolint:gomnd
+		return value, false
+	}
+
+	return MaskString(value), true
+}
+
+// PIIDetectors возвращает детекторы персональных данных: номеров телефонов и адресов электронной почты.
+func PIIDetectors() []Detector {
+	return []Detector{DetectEmail, DetectPhone}
+}
+
+func (s *redactScanner) scan() []byte {
+	for s.pos < len(s.src) {
+		s.whitespace()
+
+		if s.pos >= len(s.src) {
+			break
+		}
+
+		if !s.value(redactNone) {
+			s.dst = append(s.dst, s.src[s.pos:]...)
+
+			break
+		}
+	}
+
+	return s.dst
+}
+
+func (s *redactScanner) value(mode redactMode) bool {
+	if s.pos >= len(s.src) {
+		return false
+	}
+
+	switch s.src[s.pos] {
+	case '{':
+		return s.object(mode)
+	case '[':
+		return s.array(mode)
+	case '"':
+		return s.string(mode)
+	default:
+		return s.literal(mode)
+	}
+}
+
+func (s *redactScanner) object(mode redactMode) bool {
+	s.dst = append(s.dst, '{')
+	s.pos++
+
+	s.whitespace()
+	if s.consume('}') {
+		return true
+	}
+
+	for {
+		s.whitespace()
+
+		if s.pos >= len(s.src) || s.src[s.pos] != '"' {
+			return false
+		}
+
+		end, ok := s.stringEnd()
+		if !ok {
+			return false
+		}
+
+		key := unquote(s.src[s.pos:end])
+		s.dst = append(s.dst, s.src[s.pos:end]...)
+		s.pos = end
+
+		s.whitespace()
+		if !s.consume(':') {
+			return false
+		}
+		s.whitespace()
+
+		switch {
+		case matchKey(s.redactor.secrets, key):
+			s.dst = append(s.dst, `"`+DefaultCredentialsPlaceholder+`"`...)
+			if !s.skipValue() {
+				return false
+			}
+		case matchKey(s.redactor.masks, key):
+			if !s.value(redactMask) {
+				return false
+			}
+		default:
+			if !s.value(mode) {
+				return false
+			}
+		}
+
+		s.whitespace()
+		if s.consume('}') {
+			return true
+		}
+
+		if !s.consume(',') {
+			return false
+		}
+	}
+}
+
+func (s *redactScanner) array(mode redactMode) bool {
+	s.dst = append(s.dst, '[')
+	s.pos++
+
+	s.whitespace()
+	if s.consume(']') {
+		return true
+	}
+
+	for {
+		s.whitespace()
+		if !s.value(mode) {
+			return false
+		}
+
+		s.whitespace()
+		if s.consume(']') {
+			return true
+		}
+
+		if !s.consume(',') {
+			return false
+		}
+	}
+}
+
+func (s *redactScanner) string(mode redactMode) bool {
+	end, ok := s.stringEnd()
+	if !ok {
+		return false
+	}
+
+	raw := s.src[s.pos:end]
+	s.pos = end
+
+	if mode == redactNone && len(s.redactor.detectors) == 0 {
+		s.dst = append(s.dst, raw...)
+
+		return true
+	}
+
+	value := unquote(raw)
+
+	if mode == redactMask {
+		return s.appendString(MaskString(value))
+	}
+
+	if masked, ok := s.redactor.detect(value); ok {
+		return s.appendString(masked)
+	}
+
+	s.dst = append(s.dst, raw...)
+
+	return true
+}
+
+func (s *redactScanner) literal(mode redactMode) bool {
+	start := s.pos
+	for s.pos < len(s.src) && !isDelimiter(s.src[s.pos]) {
+		s.pos++
+	}
+
+	if s.pos == start {
+		return false
+	}
+
+	raw := s.src[start:s.pos]
+	if mode == redactMask && (raw[0] == '-' || (raw[0] >= '0' && raw[0] <= '9')) {
+		return s.appendString(MaskString(string(raw)))
+	}
+
+	s.dst = append(s.dst, raw...)
+
+	return true
+}
+
+// skipValue пропускает значение, не копируя его. Вернет false, если значение не завершено.
+func (s *redactScanner) skipValue() bool {
+	depth := 0
+
+	for s.pos < len(s.src) {
+		switch s.src[s.pos] {
+		case '"':
+			end, ok := s.stringEnd()
+			s.pos = end
+
+			if !ok {
+				return false
+			}
+
+			if depth == 0 {
+				return true
+			}
+
+			continue
+		case '{', '[':
+			depth++
+		case '}', ']':
+			if depth == 0 {
+				return true
+			}
+
+			depth--
+			if depth == 0 {
+				s.pos++
+
+				return true
+			}
+		case ',', ' ', '\t', '\n', '\r':
+			if depth == 0 {
+				return true
+			}
+		}
+
+		s.pos++
+	}
+
+	return depth == 0
+}
+
+// stringEnd возвращает позицию, следующую за строкой, начинающейся с текущей позиции.
+func (s *redactScanner) stringEnd() (int, bool) {
+	for idx := s.pos + 1; idx < len(s.src); idx++ {
+		switch s.src[idx] {
+		case '\\':
+			idx++
+		case '"':
+			return idx + 1, true
+		}
+	}
+
+	return len(s.src), false
+}
+
+func (s *redactScanner) appendString(value string) bool {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+
+	s.dst = append(s.dst, encoded...)
+
+	return true
+}
+
+func (s *redactScanner) whitespace() {
+	for s.pos < len(s.src) && isSpace(s.src[s.pos]) {
+		s.dst = append(s.dst, s.src[s.pos])
+		s.pos++
+	}
+}
+
+func (s *redactScanner) consume(char byte) bool {
+	if s.pos < len(s.src) && s.src[s.pos] == char {
+		s.dst = append(s.dst, char)
+		s.pos++
+
+		return true
+	}
+
+	return false
+}
+
+func isSpace(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\n' || char == '\r'
+}
+
+func isDelimiter(char byte) bool {
+	return isSpace(char) || char == ',' || char == ':' || char == '}' || char == ']' || char == '{' || char == '[' || char == '"'
+}
+
+func unquote(raw []byte) string {
+	if len(raw) < 2 { //nolint:gomnd
+		return ""
+	}
+
+	for _, char := range raw {
+		if char == '\\' {
+			var value string
+			if err := json.Unmarshal(raw, &value); err == nil {
+				return value
+			}
+
+			break
+		}
+	}
+
+	return string(raw[1 : len(raw)-1])
+}
+
+func matchKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func tagsOf(typ reflect.Type) taggedKeys {
+	if cached, ok := taggedKeysCache.Load(typ); ok {
+		return cached.(taggedKeys) //nolint:forcetypeassert
+	}
+
+	var keys taggedKeys
+	collectTaggedKeys(typ, &keys, make(map[reflect.Type]bool))
+	taggedKeysCache.Store(typ, keys)
+
+	return keys
+}
+
+func collectTaggedKeys(typ reflect.Type, keys *taggedKeys, visited map[reflect.Type]bool) {
+	for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array ||
+		typ.Kind() == reflect.Map {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct || visited[typ] {
+		return
+	}
+
+	visited[typ] = true
+
+	for idx := 0; idx < typ.NumField(); idx++ {
+		field := typ.Field(idx)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tagName, _, _ := strings.Cut(tag, ","); tagName == "-" {
+				continue
+			} else if tagName != "" {
+				name = tagName
+			}
+		}
+
+		switch field.Tag.Get(LogTag) {
+		case LogTagSecret:
+			keys.secrets = append(keys.secrets, name)
+		case LogTagMask:
+			keys.masks = append(keys.masks, name)
+		}
+
+		collectTaggedKeys(field.Type, keys, visited)
+	}
+}
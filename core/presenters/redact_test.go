@@ -0,0 +1,188 @@
+package presenters_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/presenters"
+)
+
+func TestRedactor_RedactJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		options presenters.ViewOptions
+		s       string
+		want    string
+	}{
+		{
+			name:    "Форматирование сохраняется",
+			options: presenters.ViewOptions{SecuredKeywords: []string{"password"}},
+			s:       `{"name": "John", "password": "123456"}`,
+			want:    `{"name": "John", "password": "{hidden}"}`,
+		},
+		{
+			name:    "Вложенные числа, массивы и объекты",
+			options: presenters.ViewOptions{SecuredKeywords: []string{"pin", "codes", "card"}},
+			s:       `{"user":{"pin":1234,"codes":[1,2,3],"card":{"number":"4111"}},"ok":true}`,
+			want:    `{"user":{"pin":"{hidden}","codes":"{hidden}","card":"{hidden}"},"ok":true}`,
+		},
+		{
+			name:    "Объекты в массиве",
+			options: presenters.ViewOptions{SecuredKeywords: []string{"token"}},
+			s:       `[{"token":"a"},{"Token":"b","id":1}]`,
+			want:    `[{"token":"{hidden}"},{"Token":"{hidden}","id":1}]`,
+		},
+		{
+			name:    "Экранированные символы",
+			options: presenters.ViewOptions{SecuredKeywords: []string{"password"}},
+			s:       `{"password":"x\"y","note":"a\"b"}`,
+			want:    `{"password":"{hidden}","note":"a\"b"}`,
+		},
+		{
+			name:    "Обрезанный JSON не раскрывает секрет",
+			options: presenters.ViewOptions{SecuredKeywords: []string{"password"}},
+			s:       `{"name":"John","password":"1234`,
+			want:    `{"name":"John","password":"{hidden}"`,
+		},
+		{
+			name:    "Не JSON",
+			options: presenters.ViewOptions{SecuredKeywords: []string{"password"}},
+			s:       `Hello, World!`,
+			want:    `Hello, World!`,
+		},
+		{
+			name:    "Детекторы персональных данных",
+			options: presenters.ViewOptions{Detectors: presenters.PIIDetectors()},
+			s:       `{"email":"max.ivanov@example.com","phone":"+77778987788","date":"2024-01-01"}`,
+			want:    `{"email":"ma******ov@example.com","phone":"+77******788","date":"2024-01-01"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(presenters.NewRedactor(tt.options).RedactJSON([]byte(tt.s))))
+		})
+	}
+}
+
+func TestRedactor_Redact(t *testing.T) {
+	type Card struct {
+		Number string `json:"number" log:"mask"`
+		CVV    int    `json:"cvv" log:"secret"`
+	}
+
+	type Customer struct {
+		Name  string `json:"name"`
+		Phone string `json:"phone" log:"mask"`
+		Cards []Card `json:"cards"`
+	}
+
+	redacted, err := presenters.NewRedactor(presenters.ViewOptions{}).Redact(Customer{
+		Name:  "John",
+		Phone: "+77778987788",
+		Cards: []Card{{Number: "4111111111111111", CVV: 123}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		`{"name":"John","phone":"+77******788","cards":[{"number":"4111********1111","cvv":"{hidden}"}]}`,
+		string(redacted))
+}
+
+func TestParameterView_tags(t *testing.T) {
+	type Request struct {
+		Login    string `json:"login"`
+		Password string `json:"password" log:"secret"`
+	}
+
+	tests := []struct {
+		name  string
+		view  presenters.ViewType
+		param any
+		want  string
+	}{
+		{
+			name:  "Представление для логов",
+			view:  presenters.ViewLogs,
+			param: Request{Login: "john", Password: "secret"},
+			want:  `{"login":"john","password":"{hidden}"}`,
+		},
+		{
+			name:  "Указатель на структуру",
+			view:  presenters.ViewLogs,
+			param: &Request{Login: "john", Password: "secret"},
+			want:  `{"login":"john","password":"{hidden}"}`,
+		},
+		{
+			name:  "Представление как есть",
+			view:  presenters.ViewIdentity,
+			param: Request{Login: "john", Password: "secret"},
+			want:  `{"login":"john","password":"secret"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, presenters.ParameterView(tt.param, tt.view, presenters.ViewOptions{}))
+		})
+	}
+}
+
+func TestMaskString(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "1234", want: "****"},
+		{value: "12345678", want: "12****78"},
+		{value: "Иван Иванов", want: "Ив*******ов"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, presenters.MaskString(tt.value))
+		})
+	}
+}
+
+// regexpHideCredentials прежняя реализация скрытия чувствительных данных, используется для сравнения в бенчмарках.
+func regexpHideCredentials(value string, options presenters.ViewOptions) string {
+	for _, keyword := range options.SecuredKeywords {
+		re := regexp.MustCompile(fmt.Sprintf(`(?mi)("%s"\s*:\s*").*?(")`, keyword))
+		value = re.ReplaceAllString(value, fmt.Sprintf(`$1%s$2`, presenters.DefaultCredentialsPlaceholder))
+	}
+
+	return value
+}
+
+var benchmarkJSON = `{"email": "max.ivanov@example.com", "password": "Ku1RjM5iexD", "token": "1234lldnk3333",
+	"profile": {"name": "Max", "phone_number": "+77778987788", "tags": ["a", "b", "c"], "age": 33},
+	"items": [{"id": 1, "title": "foo"}, {"id": 2, "title": "bar"}], "passwordCurrent": ""}`
+
+var benchmarkOptions = presenters.ViewOptions{SecuredKeywords: []string{"password", "token", "passwordCurrent", "secret"}}
+
+func BenchmarkJSONHideCredentials_regexp(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = regexpHideCredentials(benchmarkJSON, benchmarkOptions)
+	}
+}
+
+func BenchmarkJSONHideCredentials_redactor(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = presenters.JSONHideCredentials(benchmarkJSON, benchmarkOptions)
+	}
+}
+
+func BenchmarkJSONHideCredentials_redactorPII(b *testing.B) {
+	options := benchmarkOptions
+	options.Detectors = presenters.PIIDetectors()
+
+	for i := 0; i < b.N; i++ {
+		_ = presenters.JSONHideCredentials(benchmarkJSON, options)
+	}
+}
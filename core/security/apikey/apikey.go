@@ -0,0 +1,294 @@
+// Package apikey предоставляет стратегию аутентификации по ключам API для межсервисного взаимодействия.
+//
+// Ключ имеет вид "<префикс>_<идентификатор>_<секрет>": по идентификатору ключ находится в хранилище за O(1), секрет
+// хранится только в виде хеша (см. HMACEncryptor или passwords.Encryptor). Каждый ключ несет собственные полномочия,
+// срок действия и время последнего использования.
+//
+//	manager, err := apikey.NewManager(apikey.NewMemoryStorage(), apikey.NewHMACEncryptor(secret))
+//	plain, key, err := manager.Create(ctx, apikey.Spec{Name: "billing", Authorities: security.Authorities{"billing"}})
+//	// plain передается клиенту один раз, key.ID используется для ротации и отзыва.
+//	auth, err := manager.Authenticate(ctx, plain)
+package apikey
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/tools/crypto"
+	"github.com/wal1251/pkg/tools/passwords"
+)
+
+const (
+	DefaultPrefix        = "key"       // Префикс ключей по умолчанию.
+	DefaultTouchInterval = time.Minute // Минимальный интервал обновления времени последнего использования ключа.
+
+	secretLength = 32 // Длина секрета ключа в байтах.
+	separator    = "_"
+)
+
+var (
+	ErrKeyNotFound = errors.New("api key not found") // Ключ не найден в хранилище.
+	ErrInvalidKey  = errors.New("invalid api key")   // Некорректный формат ключа.
+
+	_ security.AuthenticationProvider[security.APIKey] = (*Manager)(nil)
+	_ passwords.Encryptor                              = HMACEncryptor{}
+)
+
+type (
+	// Key сохраненный ключ API. Секрет ключа не хранится, только его хеш.
+	Key struct {
+		ID          uuid.UUID            // Идентификатор ключа.
+		Name        string               // Название ключа (например, имя сервиса-клиента).
+		Hash        string               // Хеш секрета ключа.
+		Owner       security.User        // Владелец ключа.
+		Authorities security.Authorities // Полномочия ключа.
+		CreatedAt   time.Time            // Время создания.
+		ExpiresAt   time.Time            // Годен до, нулевое значение - бессрочный.
+		LastUsedAt  time.Time            // Время последнего использования.
+		RevokedAt   time.Time            // Время отзыва, нулевое значение - не отозван.
+	}
+
+	// Spec параметры создания ключа.
+	Spec struct {
+		Name        string               // Название ключа.
+		Owner       security.User        // Владелец ключа.
+		Authorities security.Authorities // Полномочия ключа.
+		TTL         time.Duration        // Срок действия, 0 - бессрочный.
+	}
+
+	// Storage хранилище ключей API.
+	Storage interface {
+		// Save создает или обновляет ключ.
+		Save(ctx context.Context, key Key) error
+		// Get возвращает ключ по идентификатору или ErrKeyNotFound.
+		Get(ctx context.Context, id uuid.UUID) (Key, error)
+		// Touch обновляет время последнего использования ключа.
+		Touch(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+	}
+
+	// Manager управляет ключами API (создание, ротация, отзыв) и аутентифицирует по ним, реализует
+	// security.AuthenticationProvider.
+	Manager struct {
+		storage       Storage
+		encryptor     passwords.Encryptor
+		prefix        string
+		dummyHash     string
+		touchInterval time.Duration
+		now           func() time.Time
+	}
+
+	// Option опция Manager.
+	Option func(*Manager)
+
+	// HMACEncryptor хеширует секреты ключей с помощью HMAC-SHA256, реализует passwords.Encryptor. В отличие от
+	// bcrypt достаточно быстр для проверки на каждом запросе, так как секрет ключа имеет высокую энтропию.
+	HMACEncryptor struct {
+		hmac *crypto.Config
+	}
+)
+
+// IsActive вернет true, если ключ не отозван и не просрочен на момент now.
+func (k Key) IsActive(now time.Time) bool {
+	return k.RevokedAt.IsZero() && (k.ExpiresAt.IsZero() || now.Before(k.ExpiresAt))
+}
+
+// Create создает новый ключ и возвращает его строковое представление, которое нужно передать клиенту: в хранилище
+// остается только хеш секрета.
+func (m *Manager) Create(ctx context.Context, spec Spec) (security.APIKey, Key, error) {
+	now := m.now()
+
+	key := Key{
+		ID:          uuid.New(),
+		Name:        spec.Name,
+		Owner:       spec.Owner,
+		Authorities: spec.Authorities,
+		CreatedAt:   now,
+	}
+
+	if spec.TTL > 0 {
+		key.ExpiresAt = now.Add(spec.TTL)
+	}
+
+	return m.issue(ctx, key)
+}
+
+// Rotate выпускает для ключа новый секрет, прежний секрет перестает действовать. Остальные атрибуты ключа сохраняются.
+func (m *Manager) Rotate(ctx context.Context, id uuid.UUID) (security.APIKey, Key, error) {
+	key, err := m.storage.Get(ctx, id)
+	if err != nil {
+		return "", Key{}, err
+	}
+
+	if !key.RevokedAt.IsZero() {
+		return "", Key{}, errs.Wrapf(errs.ErrIllegalArgument, "api key %s is revoked", id)
+	}
+
+	return m.issue(ctx, key)
+}
+
+// Revoke отзывает ключ.
+func (m *Manager) Revoke(ctx context.Context, id uuid.UUID) error {
+	key, err := m.storage.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if key.RevokedAt.IsZero() {
+		key.RevokedAt = m.now()
+	}
+
+	return m.storage.Save(ctx, key)
+}
+
+// Authenticate см. security.AuthenticationProvider.
+func (m *Manager) Authenticate(ctx context.Context, apiKey security.APIKey) (security.Authentication, error) {
+	id, secret, err := m.Parse(apiKey)
+	if err != nil {
+		return security.Authentication{}, errs.With(errs.ErrAuthFailure, err)
+	}
+
+	key, err := m.storage.Get(ctx, id)
+	if err != nil {
+		// Сравниваем с фиктивным хешем, чтобы время ответа не раскрывало существование ключа.
+		m.encryptor.Verify(secret, m.dummyHash)
+
+		return security.Authentication{}, errs.With(errs.ErrAuthFailure, err)
+	}
+
+	now := m.now()
+
+	if !m.encryptor.Verify(secret, key.Hash) || !key.IsActive(now) {
+		return security.Authentication{}, errs.Wrapf(errs.ErrAuthFailure, "api key is not valid")
+	}
+
+	if now.Sub(key.LastUsedAt) >= m.touchInterval {
+		if err = m.storage.Touch(ctx, key.ID, now); err != nil {
+			logs.FromContext(ctx).Warn().Err(err).Msgf("failed to update last usage of api key %s", key.ID)
+		}
+	}
+
+	return security.Authentication{
+		User:        key.Owner,
+		TokenID:     key.ID,
+		Authorities: key.Authorities,
+	}, nil
+}
+
+// Parse разбирает строковое представление ключа на идентификатор и секрет.
+func (m *Manager) Parse(apiKey security.APIKey) (uuid.UUID, string, error) {
+	parts := strings.SplitN(string(apiKey), separator, 3) //nolint:gomnd
+	if len(parts) != 3 || parts[0] != m.prefix || parts[2] == "" {
+		return uuid.Nil, "", ErrInvalidKey
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	return id, parts[2], nil
+}
+
+func (m *Manager) issue(ctx context.Context, key Key) (security.APIKey, Key, error) {
+	raw := make([]byte, secretLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", Key{}, fmt.Errorf("can't generate api key secret: %w", err)
+	}
+
+	secret := hex.EncodeToString(raw)
+
+	hash, err := m.encryptor.Encrypt(secret)
+	if err != nil {
+		return "", Key{}, err //nolint:wrapcheck
+	}
+
+	key.Hash = hash
+
+	if err = m.storage.Save(ctx, key); err != nil {
+		return "", Key{}, err
+	}
+
+	plain := strings.Join([]string{m.prefix, hex.EncodeToString(key.ID[:]), secret}, separator)
+
+	return security.APIKey(plain), key, nil
+}
+
+// Encrypt см. passwords.Encryptor.
+func (e HMACEncryptor) Encrypt(secret string) (string, error) {
+	return e.hmac.Sign(secret), nil
+}
+
+// Verify см. passwords.Encryptor. Сравнение выполняется за постоянное время.
+func (e HMACEncryptor) Verify(secret, hash string) bool {
+	return hmac.Equal([]byte(e.hmac.Sign(secret)), []byte(hash))
+}
+
+// NewHMACEncryptor возвращает HMACEncryptor с секретом secret.
+func NewHMACEncryptor(secret string) HMACEncryptor {
+	return HMACEncryptor{hmac: crypto.NewHMAC(secret)}
+}
+
+// WithPrefix устанавливает префикс ключей. Префикс не должен быть пустым и содержать символ "_", иначе NewManager
+// вернет ошибку.
+func WithPrefix(prefix string) Option {
+	return func(m *Manager) {
+		m.prefix = prefix
+	}
+}
+
+// WithTouchInterval устанавливает минимальный интервал обновления времени последнего использования ключа, чтобы не
+// писать в хранилище на каждом запросе.
+func WithTouchInterval(interval time.Duration) Option {
+	return func(m *Manager) {
+		m.touchInterval = interval
+	}
+}
+
+// WithClock устанавливает источник текущего времени.
+func WithClock(now func() time.Time) Option {
+	return func(m *Manager) {
+		m.now = now
+	}
+}
+
+// NewManager возвращает новый Manager. Вернет ошибку errs.ErrIllegalArgument, если префикс ключей некорректен, см.
+// WithPrefix.
+func NewManager(storage Storage, encryptor passwords.Encryptor, opts ...Option) (*Manager, error) {
+	manager := &Manager{
+		storage:       storage,
+		encryptor:     encryptor,
+		prefix:        DefaultPrefix,
+		touchInterval: DefaultTouchInterval,
+		now:           time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(manager)
+	}
+
+	if manager.prefix == "" || strings.Contains(manager.prefix, separator) {
+		return nil, errs.Wrapf(errs.ErrIllegalArgument, "api key prefix %q must be non-empty and must not contain %q",
+			manager.prefix, separator)
+	}
+
+	// Фиктивный хеш секрета того же формата, что и у выпускаемых ключей.
+	dummyHash, err := encryptor.Encrypt(hex.EncodeToString(make([]byte, secretLength)))
+	if err != nil {
+		return nil, fmt.Errorf("can't create api key manager: %w", err)
+	}
+
+	manager.dummyHash = dummyHash
+
+	return manager, nil
+}
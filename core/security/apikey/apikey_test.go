@@ -0,0 +1,119 @@
+package apikey_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/apikey"
+)
+
+func TestManager_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	storage := apikey.NewMemoryStorage()
+	manager, err := apikey.NewManager(storage, apikey.NewHMACEncryptor("secret"),
+		apikey.WithPrefix("svc"),
+		apikey.WithClock(func() time.Time { return now }),
+	)
+	require.NoError(t, err)
+
+	owner := security.User{ID: uuid.New(), Name: "billing"}
+
+	plain, key, err := manager.Create(ctx, apikey.Spec{
+		Name:        "billing",
+		Owner:       owner,
+		Authorities: security.Authorities{"payments.read"},
+		TTL:         time.Hour,
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(plain), "svc_"))
+	assert.NotContains(t, key.Hash, strings.Split(string(plain), "_")[2])
+
+	expired, _, err := manager.Create(ctx, apikey.Spec{Name: "expired", TTL: time.Nanosecond})
+	require.NoError(t, err)
+
+	revoked, revokedKey, err := manager.Create(ctx, apikey.Spec{Name: "revoked"})
+	require.NoError(t, err)
+	require.NoError(t, manager.Revoke(ctx, revokedKey.ID))
+
+	now = now.Add(time.Minute)
+
+	tests := []struct {
+		name    string
+		key     security.APIKey
+		want    security.Authentication
+		wantErr bool
+	}{
+		{
+			name: "Valid key",
+			key:  plain,
+			want: security.Authentication{User: owner, TokenID: key.ID, Authorities: security.Authorities{"payments.read"}},
+		},
+		{name: "Wrong secret", key: plain[:len(plain)-1] + "x", wantErr: true},
+		{name: "Wrong prefix", key: "key" + plain[3:], wantErr: true},
+		{name: "Unknown key", key: security.APIKey("svc_" + strings.ReplaceAll(uuid.NewString(), "-", "") + "_foo"), wantErr: true},
+		{name: "Malformed key", key: "foo", wantErr: true},
+		{name: "Expired key", key: expired, wantErr: true},
+		{name: "Revoked key", key: revoked, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := manager.Authenticate(ctx, tt.key)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errs.ErrAuthFailure)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	stored, err := storage.Get(ctx, key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, now, stored.LastUsedAt)
+}
+
+func TestManager_Rotate(t *testing.T) {
+	ctx := context.Background()
+	manager, err := apikey.NewManager(apikey.NewMemoryStorage(), apikey.NewHMACEncryptor("secret"))
+	require.NoError(t, err)
+
+	plain, key, err := manager.Create(ctx, apikey.Spec{Name: "foo", Authorities: security.Authorities{"foo"}})
+	require.NoError(t, err)
+
+	rotated, rotatedKey, err := manager.Rotate(ctx, key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, rotatedKey.ID)
+	assert.NotEqual(t, plain, rotated)
+
+	_, err = manager.Authenticate(ctx, plain)
+	assert.ErrorIs(t, err, errs.ErrAuthFailure)
+
+	auth, err := manager.Authenticate(ctx, rotated)
+	require.NoError(t, err)
+	assert.Equal(t, security.Authorities{"foo"}, auth.Authorities)
+
+	require.NoError(t, manager.Revoke(ctx, key.ID))
+
+	_, _, err = manager.Rotate(ctx, key.ID)
+	assert.ErrorIs(t, err, errs.ErrIllegalArgument)
+}
+
+func TestNewManager_prefix(t *testing.T) {
+	for _, prefix := range []string{"", "my_svc"} {
+		_, err := apikey.NewManager(apikey.NewMemoryStorage(), apikey.NewHMACEncryptor("secret"), apikey.WithPrefix(prefix))
+		assert.ErrorIs(t, err, errs.ErrIllegalArgument, prefix)
+	}
+}
@@ -0,0 +1,62 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var _ Storage = (*MemoryStorage)(nil)
+
+// MemoryStorage хранилище ключей API в памяти процесса. Подходит для тестов и статически сконфигурированных ключей.
+type MemoryStorage struct {
+	mu   sync.RWMutex
+	keys map[uuid.UUID]Key
+}
+
+// Save см. Storage.
+func (s *MemoryStorage) Save(_ context.Context, key Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.ID] = key
+
+	return nil
+}
+
+// Get см. Storage.
+func (s *MemoryStorage) Get(_ context.Context, id uuid.UUID) (Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return Key{}, fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+
+	return key, nil
+}
+
+// Touch см. Storage.
+func (s *MemoryStorage) Touch(_ context.Context, id uuid.UUID, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+
+	key.LastUsedAt = usedAt
+	s.keys[id] = key
+
+	return nil
+}
+
+// NewMemoryStorage возвращает новое пустое MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{keys: make(map[uuid.UUID]Key)}
+}
@@ -0,0 +1,99 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wal1251/pkg/core/bus"
+	"github.com/wal1251/pkg/core/ctxs"
+	"github.com/wal1251/pkg/core/logs"
+)
+
+const (
+	ClientIPContextKey = "AUTH-ClientIP" // Ключ хранения IP адреса клиента.
+
+	DefaultEventsTopic = "security" // Топик событий безопасности по умолчанию.
+
+	EventAuthSuccess  EventType = "auth_success"  // Успешная аутентификация.
+	EventAuthFailure  EventType = "auth_failure"  // Неуспешная попытка аутентификации.
+	EventLockout      EventType = "lockout"       // Попытка отклонена: пользователь или адрес заблокированы.
+	EventTokenRevoked EventType = "token_revoked" // Предъявлен отозванный токен.
+
+	AuthMethodPassword = "password" // Аутентификация по логину и паролю.
+	AuthMethodOTP      = "otp"      // Аутентификация по одноразовому паролю.
+	AuthMethodToken    = "token"    // Аутентификация по токену на предъявителя.
+	AuthMethodAPIKey   = "api_key"  // Аутентификация по ключу API.
+	AuthMethodSession  = "session"  // Аутентификация по серверной сессии.
+)
+
+// ErrTokenRevoked предъявленный токен отозван. Провайдеры аутентификации возвращают ее вместе с errs.ErrAuthFailure,
+// чтобы отличить отозванный токен от недействительного.
+var ErrTokenRevoked = errors.New("token is revoked")
+
+type (
+	// EventType тип события безопасности.
+	EventType string
+
+	// Event событие безопасности: исход попытки аутентификации.
+	Event struct {
+		Type       EventType     `json:"type"`
+		Method     string        `json:"method,omitempty"`      // Способ аутентификации, например AuthMethodPassword.
+		Login      string        `json:"login,omitempty"`       // Логин или иной идентификатор, указанный клиентом.
+		UserID     uuid.UUID     `json:"user_id,omitempty"`     //nolint:tagliatelle // Идентификатор пользователя.
+		TokenID    uuid.UUID     `json:"token_id,omitempty"`    //nolint:tagliatelle // Идентификатор токена.
+		IP         string        `json:"ip,omitempty"`          // IP адрес клиента.
+		Reason     string        `json:"reason,omitempty"`      // Причина неуспеха.
+		RetryAfter time.Duration `json:"retry_after,omitempty"` //nolint:tagliatelle // Оставшееся время блокировки.
+		Time       time.Time     `json:"time"`
+	}
+
+	// EventNotifier публикует события безопасности в топик событийной шины. Ошибки публикации записываются в журнал
+	// и не прерывают аутентификацию. Нулевой *EventNotifier ничего не публикует.
+	EventNotifier struct {
+		bus   bus.EventBus[Event]
+		topic string
+		now   func() time.Time
+	}
+)
+
+// Notify публикует событие, заполняет время события, если оно не указано.
+func (n *EventNotifier) Notify(ctx context.Context, event Event) {
+	if n == nil || n.bus == nil {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = n.now()
+	}
+
+	if err := n.bus.Notify(ctx, n.topic, event); err != nil {
+		logs.FromContext(ctx).Warn().Err(err).Str("event", string(event.Type)).Msg("failed to publish security event")
+	}
+}
+
+// NewEventNotifier возвращает новый EventNotifier, публикующий события в топик topic (по умолчанию
+// DefaultEventsTopic).
+func NewEventNotifier(eventBus bus.EventBus[Event], topic string) *EventNotifier {
+	if topic == "" {
+		topic = DefaultEventsTopic
+	}
+
+	return &EventNotifier{
+		bus:   eventBus,
+		topic: topic,
+		now:   time.Now,
+	}
+}
+
+// WithClientIP возвращает контекст с IP адресом клиента, используется для учета неуспешных попыток аутентификации.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return ctxs.ValuePut(ctx, ClientIPContextKey, ip)
+}
+
+// ClientIP возвращает IP адрес клиента из контекста, см. WithClientIP.
+func ClientIP(ctx context.Context) string {
+	return ctxs.ValueGet[string](ctx, ClientIPContextKey)
+}
@@ -17,6 +17,9 @@ const (
 	CfgAudience           cfg.Key = "JWT_CLAIM_AUDIENCE"    // Значение поля claim audience
 	CfgIssuer             cfg.Key = "JWT_CLAIM_ISSUER"      // Значение поля claim issuer
 	CfgSigningMethodName  cfg.Key = "JWT_SIGNING_METHOD_NAME"
+	CfgKeyKeyID           cfg.Key = "JWT_KEY_ID"                // Идентификатор ключа подписи (заголовок kid).
+	CfgKeyJWKSURL         cfg.Key = "JWT_JWKS_URL"              // Адрес JWKS для проверки токенов других сервисов.
+	CfgKeyJWKSRefresh     cfg.Key = "JWT_JWKS_REFRESH_INTERVAL" // Интервал обновления JWKS (duration).
 
 	CfgDefaultAccessTokenTTL  = 7 * 24 * time.Hour           // Время жизни токена доступа по умолчанию.
 	CfgDefaultRefreshTokenTTL = 4 * CfgDefaultAccessTokenTTL // Время жизни токена продления по умолчанию.
@@ -25,12 +28,20 @@ const (
 // ManagerConfig конфигурация менеджера JWT токенов.
 type ManagerConfig struct {
 	SigningMethod  jwt.SigningMethod
-	PrivateKeyPath string // Путь до приватного ключа, которым подписываются JWT токены.
-	PublicKeyPath  string // Путь до публичного ключа, для проверки JWT токенов.
-	Secret         []byte // Секрет для подписывания токенов JWT.
+	PrivateKeyPath string  // Путь до приватного ключа, которым подписываются JWT токены.
+	PublicKeyPath  string  // Путь до публичного ключа, для проверки JWT токенов.
+	Secret         []byte  // Секрет для подписывания токенов JWT.
+	KeyID          string  // Идентификатор ключа подписи (заголовок kid).
+	Keys           *KeySet // Набор ключей подписи, если задан, ключи из PEM и Secret не используются.
 
 	Audience        string        // Claim Audience - для кого токен выпущен
 	Issuer          string        // Claim Issuer - для кого токен выпущен
 	AccessTokenTTL  time.Duration // Время жизни токена доступа.
 	RefreshTokenTTL time.Duration // Время жизни токена продления.
 }
+
+// JWKSConfig конфигурация проверки токенов по ключам, опубликованным в формате JWKS.
+type JWKSConfig struct {
+	URL             string        // Адрес JWKS.
+	RefreshInterval time.Duration // Интервал обновления ключей.
+}
@@ -17,6 +17,7 @@ func ManagerCfgFromViper(v *viper.Viper, keyMapping ...cfg.KeyMap) *ManagerConfi
 		Secret:          []byte(viperx.Get(v, CfgKeySecret.Map(keyMapping...), "")),
 		PrivateKeyPath:  viperx.Get(v, CfgKeyPrivateKeyPath.Map(keyMapping...), ""),
 		PublicKeyPath:   viperx.Get(v, CfgKeyPublicKeyPath.Map(keyMapping...), ""),
+		KeyID:           viperx.Get(v, CfgKeyKeyID.Map(keyMapping...), ""),
 		Audience:        viperx.Get(v, CfgAudience.Map(keyMapping...), ""),
 		Issuer:          viperx.Get(v, CfgIssuer.Map(keyMapping...), ""),
 		AccessTokenTTL:  viperx.Get(v, CfgKeyAccessTokenTTL.Map(keyMapping...), CfgDefaultAccessTokenTTL),
@@ -24,6 +25,14 @@ func ManagerCfgFromViper(v *viper.Viper, keyMapping ...cfg.KeyMap) *ManagerConfi
 	}
 }
 
+// JWKSCfgFromViper загрузка конфига JWKSConfig с помощью viper.
+func JWKSCfgFromViper(v *viper.Viper, keyMapping ...cfg.KeyMap) *JWKSConfig {
+	return &JWKSConfig{
+		URL:             viperx.Get(v, CfgKeyJWKSURL.Map(keyMapping...), ""),
+		RefreshInterval: viperx.Get(v, CfgKeyJWKSRefresh.Map(keyMapping...), DefaultJWKSRefreshInterval),
+	}
+}
+
 func getSingingMethodByName(v *viper.Viper) jwt.SigningMethod {
 	singingMethodName := v.GetString(CfgSigningMethodName.String())
 	switch singingMethodName {
@@ -37,8 +46,12 @@ func getSingingMethodByName(v *viper.Viper) jwt.SigningMethod {
 		return jwt.SigningMethodHS512
 	case "ES256":
 		return jwt.SigningMethodES256
+	case "ES384":
+		return jwt.SigningMethodES384
 	case "ES512":
 		return jwt.SigningMethodES512
+	case "EdDSA":
+		return jwt.SigningMethodEdDSA
 	default:
 		return jwt.SigningMethodHS256
 	}
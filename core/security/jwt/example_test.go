@@ -17,7 +17,7 @@ import (
 	"github.com/wal1251/pkg/tools/collections"
 )
 
-func ExampleHS256Manager_accessToken() {
+func ExampleTokenManager_hs256AccessToken() {
 	// Новый менеджер JWT, который выпускает практически бессрочные токены.
 	manager, err := jwt.NewTokenManager(
 		&jwt.ManagerConfig{
@@ -57,7 +57,7 @@ func ExampleHS256Manager_accessToken() {
 	// authorities: [user]
 }
 
-func ExampleHS256Manager_refreshToken() {
+func ExampleTokenManager_hs256RefreshToken() {
 	manager, err := jwt.NewTokenManager(
 		&jwt.ManagerConfig{
 			RefreshTokenTTL: 1<<63 - 1,
@@ -88,7 +88,7 @@ func ExampleHS256Manager_refreshToken() {
 	// subject id: 09b5fdac-ca0d-4fa2-bbcb-b9a9958d9904
 }
 
-func ExampleRS256Manager_accessToken() {
+func ExampleTokenManager_rs256AccessToken() {
 	// Новый менеджер JWT, который выпускает практически бессрочные токены.
 	manager, err := jwt.NewTokenManager(&jwt.ManagerConfig{
 		AccessTokenTTL: 1<<63 - 1,
@@ -125,7 +125,7 @@ func ExampleRS256Manager_accessToken() {
 	// authorities: [user]
 }
 
-func ExampleRS256Manager_refreshToken() {
+func ExampleTokenManager_rs256RefreshToken() {
 	// Новый менеджер JWT, который выпускает практически бессрочные токены.
 	manager, err := jwt.NewTokenManager(&jwt.ManagerConfig{
 		RefreshTokenTTL: 1<<63 - 1,
@@ -198,7 +198,7 @@ func (r *AppRichToken) ForAccess(auth security.Authentication, ttl time.Duration
 	}
 }
 
-func ExampleManagerWithCustomClaims() {
+func ExampleTokenManager_customClaims() {
 	// Пример при использовании полей jwt, отличных от дефолтных, и дополнительных полей
 
 	// Менеджер JWT: выпускает и парсит токены доступа.
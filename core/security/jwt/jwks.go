@@ -0,0 +1,218 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	DefaultJWKSMaxAge = 5 * time.Minute // Время кеширования JWKS клиентами по умолчанию.
+
+	KeyTypeRSA = "RSA" // Тип ключа RSA.
+	KeyTypeEC  = "EC"  // Тип ключа ECDSA.
+	KeyTypeOKP = "OKP" // Тип ключа Ed25519.
+
+	keyUseSignature = "sig"
+	curveEd25519    = "Ed25519"
+)
+
+type (
+	// JWK открытый ключ в формате JSON Web Key (RFC 7517).
+	JWK struct {
+		Kty string `json:"kty"`           // Тип ключа.
+		Kid string `json:"kid,omitempty"` // Идентификатор ключа.
+		Use string `json:"use,omitempty"` // Назначение ключа.
+		Alg string `json:"alg,omitempty"` // Алгоритм подписи.
+		N   string `json:"n,omitempty"`   // Модуль RSA.
+		E   string `json:"e,omitempty"`   // Экспонента RSA.
+		Crv string `json:"crv,omitempty"` // Кривая EC или OKP.
+		X   string `json:"x,omitempty"`   // Координата X EC или открытый ключ OKP.
+		Y   string `json:"y,omitempty"`   // Координата Y EC.
+	}
+
+	// JWKS набор открытых ключей в формате JSON Web Key Set.
+	JWKS struct {
+		Keys []JWK `json:"keys"`
+	}
+)
+
+// Key возвращает ключ проверки подписи, соответствующий JWK.
+func (j JWK) Key() (Key, error) {
+	var method jwt.SigningMethod
+	if j.Alg != "" {
+		if method = jwt.GetSigningMethod(j.Alg); method == nil {
+			return Key{}, fmt.Errorf("%w: unsupported algorithm %s", ErrInvalidKey, j.Alg)
+		}
+	}
+
+	publicKey, err := j.publicKey()
+	if err != nil {
+		return Key{}, err
+	}
+
+	if method != nil && !methodFits(method, publicKey) {
+		return Key{}, fmt.Errorf("%w: key %s does not fit %s", ErrInvalidKey, j.Kid, j.Alg)
+	}
+
+	return Key{ID: j.Kid, Method: method, PublicKey: publicKey}, nil
+}
+
+// Thumbprint возвращает отпечаток ключа по RFC 7638, может использоваться в качестве идентификатора ключа.
+func (j JWK) Thumbprint() string {
+	var members string
+
+	switch j.Kty {
+	case KeyTypeRSA:
+		members = fmt.Sprintf(`{"e":%q,"kty":%q,"n":%q}`, j.E, j.Kty, j.N)
+	case KeyTypeEC:
+		members = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, j.Crv, j.Kty, j.X, j.Y)
+	default:
+		members = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, j.Crv, j.Kty, j.X)
+	}
+
+	sum := sha256.Sum256([]byte(members))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (j JWK) publicKey() (any, error) {
+	switch j.Kty {
+	case KeyTypeRSA:
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case KeyTypeEC:
+		curve, err := curveByName(j.Crv)
+		if err != nil {
+			return nil, err
+		}
+
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) { //nolint:staticcheck
+			return nil, fmt.Errorf("%w: point is not on curve %s", ErrInvalidKey, j.Crv)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case KeyTypeOKP:
+		if j.Crv != curveEd25519 {
+			return nil, fmt.Errorf("%w: unsupported curve %s", ErrInvalidKey, j.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: invalid Ed25519 key", ErrInvalidKey)
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type %s", ErrInvalidKey, j.Kty)
+	}
+}
+
+// Key возвращает ключ с идентификатором kid.
+func (s JWKS) Key(kid string) (JWK, bool) {
+	for _, key := range s.Keys {
+		if key.Kid == kid {
+			return key, true
+		}
+	}
+
+	return JWK{}, false
+}
+
+// NewJWK возвращает представление открытой части ключа в формате JWK.
+func NewJWK(key Key) (JWK, error) {
+	jwk := JWK{Kid: key.ID, Use: keyUseSignature}
+	if key.Method != nil {
+		jwk.Alg = key.Method.Alg()
+	}
+
+	switch k := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = KeyTypeRSA
+		jwk.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8 //nolint:gomnd
+		jwk.Kty = KeyTypeEC
+		jwk.Crv = k.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = KeyTypeOKP
+		jwk.Crv = curveEd25519
+		jwk.X = base64.RawURLEncoding.EncodeToString(k)
+	default:
+		return JWK{}, fmt.Errorf("%w: key type %T can't be published", ErrInvalidKey, key.PublicKey)
+	}
+
+	return jwk, nil
+}
+
+// JWKSHandler возвращает обработчик, публикующий открытые ключи набора keys в формате JWKS (как правило по адресу
+// /.well-known/jwks.json). Клиентам разрешается кешировать ответ на время maxAge.
+func JWKSHandler(keys *KeySet, maxAge time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		body, err := json.Marshal(keys.JWKS())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+		_, _ = w.Write(body)
+	})
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) == 0 {
+		return nil, fmt.Errorf("%w: invalid key parameter", ErrInvalidKey)
+	}
+
+	return new(big.Int).SetBytes(raw), nil
+}
+
+func curveByName(name string) (elliptic.Curve, error) {
+	switch name {
+	case elliptic.P256().Params().Name:
+		return elliptic.P256(), nil
+	case elliptic.P384().Params().Name:
+		return elliptic.P384(), nil
+	case elliptic.P521().Params().Name:
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported curve %s", ErrInvalidKey, name)
+	}
+}
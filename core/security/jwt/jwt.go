@@ -4,6 +4,7 @@ package jwt
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -13,7 +14,10 @@ import (
 	"github.com/wal1251/pkg/core/security"
 )
 
-var _ security.AuthenticationProvider[security.BearerToken] = (*AuthenticationProvider)(nil)
+var (
+	_ security.AuthenticationProvider[security.BearerToken] = (*AuthenticationProvider)(nil)
+	_ TenantToken                                           = (*DefaultRichToken)(nil)
+)
 
 var (
 	ErrInvalidToken       = errors.New("invalid token")        // Некорректный токен.
@@ -52,6 +56,12 @@ type (
 		GetClaims() jwt.Claims
 	}
 
+	// TenantToken токен, содержащий идентификатор арендатора пользователя. Если RichToken реализует TenantToken,
+	// арендатор переносится в security.Authentication.
+	TenantToken interface {
+		GetTenantID() string // Идентификатор арендатора.
+	}
+
 	// TokenIssuer отвечает за выпуск токенов.
 	TokenIssuer interface {
 		GetSigningSecret() any                                                           // Получение секрета для подписи токена
@@ -72,13 +82,15 @@ type (
 	}
 
 	// AuthenticationProvider обертка для использования TokenParser в качестве провайдера аутентификации.
+	// Если задан Revocations, отозванные токены отклоняются.
 	AuthenticationProvider struct {
 		TokenParser
+		Revocations RevocationChecker
 	}
 )
 
 // Authenticate см. AuthenticationProvider.Authenticate().
-func (p *AuthenticationProvider) Authenticate(_ context.Context, t security.BearerToken) (security.Authentication, error) {
+func (p *AuthenticationProvider) Authenticate(ctx context.Context, t security.BearerToken) (security.Authentication, error) {
 	token, err := p.TokenParser.ParseToken(t)
 	if err != nil {
 		return security.Authentication{}, errs.With(errs.ErrAuthFailure, err)
@@ -87,6 +99,18 @@ func (p *AuthenticationProvider) Authenticate(_ context.Context, t security.Bear
 	if token.GetName() == "" || token.GetSubjectID() == uuid.Nil {
 		return security.Authentication{}, errs.Wrapf(errs.ErrAuthFailure, "token has no authentication data")
 	}
+
+	if p.Revocations != nil {
+		revoked, err := p.Revocations.IsRevoked(ctx, token)
+		if err != nil {
+			return security.Authentication{}, errs.With(errs.ErrAuthFailure, err)
+		}
+
+		if revoked {
+			return security.Authentication{}, fmt.Errorf("%w: %w", errs.ErrAuthFailure, security.ErrTokenRevoked)
+		}
+	}
+
 	auth := security.Authentication{
 		User: security.User{
 			ID:          token.GetSubjectID(),
@@ -98,10 +122,19 @@ func (p *AuthenticationProvider) Authenticate(_ context.Context, t security.Bear
 		SessionID:   token.GetSessionID(),
 	}
 
+	if tenantToken, ok := token.(TenantToken); ok {
+		auth.TenantID = tenantToken.GetTenantID()
+	}
+
 	return auth, nil
 }
 
 // NewAuthenticationProvider провайдер аутентификации на базе Manager.
 func NewAuthenticationProvider(m Manager) *AuthenticationProvider {
-	return &AuthenticationProvider{m}
+	return &AuthenticationProvider{TokenParser: m}
+}
+
+// NewRevocableAuthenticationProvider провайдер аутентификации на базе Manager, отклоняющий отозванные токены.
+func NewRevocableAuthenticationProvider(m Manager, revocations RevocationChecker) *AuthenticationProvider {
+	return &AuthenticationProvider{TokenParser: m, Revocations: revocations}
 }
@@ -0,0 +1,338 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const HeaderKeyID = "kid" // Заголовок JWT с идентификатором ключа подписи.
+
+var (
+	ErrKeyNotFound = errors.New("signing key not found") // Ключ подписи не найден.
+	ErrInvalidKey  = errors.New("invalid signing key")   // Ключ не подходит для алгоритма подписи.
+)
+
+type (
+	// Key ключ подписи токенов. Ключ без закрытой части используется только для проверки подписи.
+	Key struct {
+		ID         string            // Идентификатор ключа (kid).
+		Method     jwt.SigningMethod // Алгоритм подписи, nil - любой алгоритм, подходящий для типа ключа.
+		PrivateKey any               // Закрытый ключ: []byte, *rsa.PrivateKey, *ecdsa.PrivateKey или ed25519.PrivateKey.
+		PublicKey  any               // Открытый ключ: []byte, *rsa.PublicKey, *ecdsa.PublicKey или ed25519.PublicKey.
+	}
+
+	// KeySet набор ключей подписи, выбираемых по заголовку kid. Токены подписываются текущим ключом подписи, а
+	// проверяются любым ключом набора, что позволяет менять ключи без простоя:
+	//  1. новый ключ добавляется в набор (Add) и публикуется в JWKS (см. JWKSHandler);
+	//  2. после истечения времени кеширования JWKS у клиентов новый ключ назначается ключом подписи (SetSigningKey);
+	//  3. после истечения времени жизни выпущенных старым ключом токенов старый ключ удаляется (Remove).
+	KeySet struct {
+		mu      sync.RWMutex
+		keys    map[string]Key
+		order   []string
+		signing string
+	}
+)
+
+// CanSign вернет true, если ключ содержит закрытую часть и алгоритм подписи.
+func (k Key) CanSign() bool {
+	return k.PrivateKey != nil && k.Method != nil
+}
+
+// Fits вернет true, если ключ подходит для алгоритма method.
+func (k Key) Fits(method jwt.SigningMethod) bool {
+	if k.Method != nil && k.Method.Alg() != method.Alg() {
+		return false
+	}
+
+	return methodFits(method, k.PublicKey)
+}
+
+// Add добавляет ключ в набор или заменяет ключ с тем же идентификатором. Первый добавленный ключ, способный подписывать
+// токены, становится ключом подписи.
+func (s *KeySet) Add(key Key) error {
+	if key.ID == "" {
+		return fmt.Errorf("%w: key id is empty", ErrInvalidKey)
+	}
+
+	if key.Method != nil && !methodFits(key.Method, key.PublicKey) {
+		return fmt.Errorf("%w: key %s does not fit %s", ErrInvalidKey, key.ID, key.Method.Alg())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[key.ID]; !ok {
+		s.order = append(s.order, key.ID)
+	}
+
+	s.keys[key.ID] = key
+
+	if s.signing == "" && key.CanSign() {
+		s.signing = key.ID
+	}
+
+	return nil
+}
+
+// Remove удаляет ключ из набора. Текущий ключ подписи удалить нельзя.
+func (s *KeySet) Remove(kid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if kid == s.signing {
+		return fmt.Errorf("%w: key %s is used for signing", ErrInvalidKey, kid)
+	}
+
+	delete(s.keys, kid)
+
+	for i, id := range s.order {
+		if id == kid {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+
+			break
+		}
+	}
+
+	return nil
+}
+
+// SetSigningKey назначает ключ kid ключом подписи.
+func (s *KeySet) SetSigningKey(kid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+	}
+
+	if !key.CanSign() {
+		return fmt.Errorf("%w: key %s can't sign tokens", ErrInvalidKey, kid)
+	}
+
+	s.signing = kid
+
+	return nil
+}
+
+// SigningKey возвращает текущий ключ подписи.
+func (s *KeySet) SigningKey() (Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[s.signing]
+	if !ok {
+		return Key{}, fmt.Errorf("%w: no signing key", ErrKeyNotFound)
+	}
+
+	return key, nil
+}
+
+// Key возвращает ключ по идентификатору.
+func (s *KeySet) Key(kid string) (Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[kid]
+
+	return key, ok
+}
+
+// Keys возвращает ключи набора в порядке добавления.
+func (s *KeySet) Keys() []Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]Key, 0, len(s.order))
+	for _, id := range s.order {
+		keys = append(keys, s.keys[id])
+	}
+
+	return keys
+}
+
+// Keyfunc возвращает ключ проверки подписи токена по заголовку kid, см. jwt.Keyfunc. Токены без kid проверяются
+// текущим ключом подписи. Алгоритм токена должен подходить ключу.
+func (s *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header[HeaderKeyID].(string)
+
+	var (
+		key Key
+		ok  bool
+	)
+
+	if kid == "" {
+		s.mu.RLock()
+		key, ok = s.keys[s.signing]
+		s.mu.RUnlock()
+	} else {
+		key, ok = s.Key(kid)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+	}
+
+	return verificationKey(key, token.Method)
+}
+
+// JWKS возвращает открытые ключи набора в формате JWKS. Симметричные ключи не публикуются.
+func (s *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0)}
+
+	for _, key := range s.Keys() {
+		if jwk, err := NewJWK(key); err == nil {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+
+	return jwks
+}
+
+// NewKeySet возвращает набор ключей keys.
+func NewKeySet(keys ...Key) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]Key, len(keys))}
+
+	for _, key := range keys {
+		if err := set.Add(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return set, nil
+}
+
+// NewSigningKey возвращает ключ подписи с идентификатором id. Открытая часть ключа вычисляется из закрытой.
+func NewSigningKey(id string, method jwt.SigningMethod, privateKey any) (Key, error) {
+	var publicKey any
+
+	switch k := privateKey.(type) {
+	case []byte:
+		publicKey = k
+	case crypto.Signer:
+		publicKey = k.Public()
+	default:
+		return Key{}, fmt.Errorf("%w: unsupported key type %T", ErrInvalidKey, privateKey)
+	}
+
+	if !methodFits(method, publicKey) {
+		return Key{}, fmt.Errorf("%w: key %s does not fit %s", ErrInvalidKey, id, method.Alg())
+	}
+
+	return Key{ID: id, Method: method, PrivateKey: privateKey, PublicKey: publicKey}, nil
+}
+
+// ParsePrivateKeyFromPEM разбирает закрытый ключ RSA, ECDSA или Ed25519 в формате PEM.
+func ParsePrivateKeyFromPEM(data []byte) (crypto.Signer, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	if key, err := jwt.ParseECPrivateKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	key, err := jwt.ParseEdPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported private key format", ErrInvalidKey)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported private key format", ErrInvalidKey)
+	}
+
+	return signer, nil
+}
+
+// ParsePublicKeyFromPEM разбирает открытый ключ RSA, ECDSA или Ed25519 в формате PEM.
+func ParsePublicKeyFromPEM(data []byte) (crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	if key, err := jwt.ParseECPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	key, err := jwt.ParseEdPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported public key format", ErrInvalidKey)
+	}
+
+	return key, nil
+}
+
+// LoadKeyFromPEM загружает ключ из файлов PEM. Если задан путь до закрытого ключа, открытая часть вычисляется из него,
+// иначе загружается ключ только для проверки подписи.
+func LoadKeyFromPEM(id string, method jwt.SigningMethod, privateKeyPath, publicKeyPath string) (Key, error) {
+	if privateKeyPath != "" {
+		data, err := os.ReadFile(privateKeyPath)
+		if err != nil {
+			return Key{}, fmt.Errorf("failed to read private key from path: %s. %w", privateKeyPath, err)
+		}
+
+		privateKey, err := ParsePrivateKeyFromPEM(data)
+		if err != nil {
+			return Key{}, fmt.Errorf("failed to parse private key: %w", err)
+		}
+
+		return NewSigningKey(id, method, privateKey)
+	}
+
+	data, err := os.ReadFile(publicKeyPath)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to read public key from path: %s. %w", publicKeyPath, err)
+	}
+
+	publicKey, err := ParsePublicKeyFromPEM(data)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	if !methodFits(method, publicKey) {
+		return Key{}, fmt.Errorf("%w: key %s does not fit %s", ErrInvalidKey, id, method.Alg())
+	}
+
+	return Key{ID: id, Method: method, PublicKey: publicKey}, nil
+}
+
+func verificationKey(key Key, method jwt.SigningMethod) (any, error) {
+	if !key.Fits(method) {
+		return nil, fmt.Errorf("%w: key %s does not fit %s", ErrInvalidKey, key.ID, method.Alg())
+	}
+
+	return key.PublicKey, nil
+}
+
+func methodFits(method jwt.SigningMethod, publicKey any) bool {
+	switch m := method.(type) {
+	case *jwt.SigningMethodHMAC:
+		_, ok := publicKey.([]byte)
+
+		return ok
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := publicKey.(*rsa.PublicKey)
+
+		return ok
+	case *jwt.SigningMethodECDSA:
+		key, ok := publicKey.(*ecdsa.PublicKey)
+
+		return ok && key.Curve.Params().BitSize == m.CurveBits
+	case *jwt.SigningMethodEd25519:
+		_, ok := publicKey.(ed25519.PublicKey)
+
+		return ok
+	default:
+		return false
+	}
+}
@@ -0,0 +1,262 @@
+package jwt_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/jwt"
+)
+
+func newRichToken() jwt.RichToken {
+	return &jwt.DefaultRichToken{Claims: &jwt.DefaultClaims{}}
+}
+
+func newKey(t *testing.T, id string, method gojwt.SigningMethod) jwt.Key {
+	t.Helper()
+
+	var (
+		privateKey crypto.Signer
+		err        error
+	)
+
+	switch method.(type) {
+	case *gojwt.SigningMethodRSA:
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case *gojwt.SigningMethodECDSA:
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	}
+	require.NoError(t, err)
+
+	key, err := jwt.NewSigningKey(id, method, privateKey)
+	require.NoError(t, err)
+
+	return key
+}
+
+func newKeySetManager(t *testing.T, keys *jwt.KeySet) *jwt.TokenManager {
+	t.Helper()
+
+	manager, err := jwt.NewTokenManager(&jwt.ManagerConfig{
+		Keys:            keys,
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: 24 * time.Hour,
+	}, newRichToken)
+	require.NoError(t, err)
+
+	return manager
+}
+
+func TestTokenManager_keySet(t *testing.T) {
+	auth := security.Authentication{User: security.User{ID: uuid.New(), Name: "user"}}
+
+	tests := []struct {
+		name   string
+		method gojwt.SigningMethod
+	}{
+		{name: "RS256", method: gojwt.SigningMethodRS256},
+		{name: "ES256", method: gojwt.SigningMethodES256},
+		{name: "EdDSA", method: gojwt.SigningMethodEdDSA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := jwt.NewKeySet(newKey(t, "key-1", tt.method))
+			require.NoError(t, err)
+
+			manager := newKeySetManager(t, keys)
+
+			token, err := manager.CreateAccessToken(auth)
+			require.NoError(t, err)
+
+			parsed, err := gojwt.Parse(string(token.GetBearerToken()), keys.Keyfunc)
+			require.NoError(t, err)
+			assert.Equal(t, "key-1", parsed.Header[jwt.HeaderKeyID])
+			assert.Equal(t, tt.method.Alg(), parsed.Method.Alg())
+
+			result, err := manager.ParseToken(token.GetBearerToken())
+			require.NoError(t, err)
+			assert.Equal(t, auth.User.ID, result.GetSubjectID())
+		})
+	}
+}
+
+func TestKeySet_rotation(t *testing.T) {
+	auth := security.Authentication{User: security.User{ID: uuid.New(), Name: "user"}}
+
+	keys, err := jwt.NewKeySet(newKey(t, "old", gojwt.SigningMethodES256))
+	require.NoError(t, err)
+
+	manager := newKeySetManager(t, keys)
+
+	oldToken, err := manager.CreateAccessToken(auth)
+	require.NoError(t, err)
+
+	require.NoError(t, keys.Add(newKey(t, "new", gojwt.SigningMethodEdDSA)))
+	require.NoError(t, keys.SetSigningKey("new"))
+	assert.Error(t, keys.Remove("new"))
+
+	newToken, err := manager.CreateAccessToken(auth)
+	require.NoError(t, err)
+
+	_, err = manager.ParseToken(oldToken.GetBearerToken())
+	require.NoError(t, err)
+
+	_, err = manager.ParseToken(newToken.GetBearerToken())
+	require.NoError(t, err)
+
+	require.NoError(t, keys.Remove("old"))
+
+	_, err = manager.ParseToken(oldToken.GetBearerToken())
+	assert.ErrorIs(t, err, jwt.ErrKeyNotFound)
+
+	_, err = manager.ParseToken(newToken.GetBearerToken())
+	assert.NoError(t, err)
+}
+
+func TestKeySet_algorithmMismatch(t *testing.T) {
+	rsaKey := newKey(t, "key", gojwt.SigningMethodRS256)
+
+	keys, err := jwt.NewKeySet(jwt.Key{ID: "key", Method: gojwt.SigningMethodRS256, PublicKey: rsaKey.PublicKey})
+	require.NoError(t, err)
+
+	// Токен подписан тем же ключом, но другим алгоритмом.
+	token := gojwt.NewWithClaims(gojwt.SigningMethodPS256, gojwt.MapClaims{"sub": "user"})
+	token.Header[jwt.HeaderKeyID] = "key"
+
+	signed, err := token.SignedString(rsaKey.PrivateKey)
+	require.NoError(t, err)
+
+	_, err = gojwt.Parse(signed, keys.Keyfunc)
+	assert.ErrorIs(t, err, jwt.ErrInvalidKey)
+
+	_, err = jwt.NewSigningKey("key", gojwt.SigningMethodES384, newKey(t, "ec", gojwt.SigningMethodES256).PrivateKey)
+	assert.ErrorIs(t, err, jwt.ErrInvalidKey)
+}
+
+func TestJWK(t *testing.T) {
+	for _, method := range []gojwt.SigningMethod{gojwt.SigningMethodRS256, gojwt.SigningMethodES256, gojwt.SigningMethodEdDSA} {
+		t.Run(method.Alg(), func(t *testing.T) {
+			key := newKey(t, "key", method)
+
+			jwk, err := jwt.NewJWK(key)
+			require.NoError(t, err)
+			assert.Equal(t, method.Alg(), jwk.Alg)
+			assert.NotEmpty(t, jwk.Thumbprint())
+
+			decoded, err := jwk.Key()
+			require.NoError(t, err)
+			assert.Equal(t, key.PublicKey, decoded.PublicKey)
+			assert.Equal(t, key.ID, decoded.ID)
+		})
+	}
+
+	_, err := jwt.NewJWK(jwt.Key{ID: "hmac", Method: gojwt.SigningMethodHS256, PublicKey: []byte("secret")})
+	assert.ErrorIs(t, err, jwt.ErrInvalidKey)
+}
+
+func TestJWKSTokenParser(t *testing.T) {
+	auth := security.Authentication{User: security.User{ID: uuid.New(), Name: "user"}}
+
+	keys, err := jwt.NewKeySet(newKey(t, "key-1", gojwt.SigningMethodRS256))
+	require.NoError(t, err)
+
+	var requests int
+
+	handler := jwt.JWKSHandler(keys, jwt.DefaultJWKSMaxAge)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	manager := newKeySetManager(t, keys)
+	parser := jwt.NewJWKSTokenParser(server.URL, newRichToken, jwt.WithRefreshInterval(time.Hour, 0))
+
+	token, err := manager.CreateAccessToken(auth)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		result, err := parser.ParseToken(token.GetBearerToken())
+		require.NoError(t, err)
+		assert.Equal(t, auth.User.ID, result.GetSubjectID())
+	}
+
+	assert.Equal(t, 1, requests, "jwks must be cached")
+
+	// Новый ключ подхватывается при появлении токена с неизвестным kid.
+	require.NoError(t, keys.Add(newKey(t, "key-2", gojwt.SigningMethodES256)))
+	require.NoError(t, keys.SetSigningKey("key-2"))
+
+	token, err = manager.CreateAccessToken(auth)
+	require.NoError(t, err)
+
+	_, err = parser.ParseToken(token.GetBearerToken())
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+
+	// Токен, подписанный неизвестным ключом, отклоняется.
+	foreign := newKeySetManager(t, mustKeySet(t, newKey(t, "key-3", gojwt.SigningMethodES256)))
+
+	token, err = foreign.CreateAccessToken(auth)
+	require.NoError(t, err)
+
+	_, err = parser.ParseToken(token.GetBearerToken())
+	assert.ErrorIs(t, err, jwt.ErrKeyNotFound)
+}
+
+func TestNewTokenManager_pem(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "private.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	manager, err := jwt.NewTokenManager(&jwt.ManagerConfig{
+		SigningMethod:  gojwt.SigningMethodES256,
+		PrivateKeyPath: path,
+		AccessTokenTTL: time.Hour,
+	}, newRichToken)
+	require.NoError(t, err)
+
+	jwks := manager.Keys.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, jwks.Keys[0].Thumbprint(), jwks.Keys[0].Kid)
+
+	token, err := manager.CreateAccessToken(security.Authentication{User: security.User{ID: uuid.New(), Name: "user"}})
+	require.NoError(t, err)
+
+	_, err = manager.ParseToken(token.GetBearerToken())
+	assert.NoError(t, err)
+}
+
+func mustKeySet(t *testing.T, keys ...jwt.Key) *jwt.KeySet {
+	t.Helper()
+
+	set, err := jwt.NewKeySet(keys...)
+	require.NoError(t, err)
+
+	return set
+}
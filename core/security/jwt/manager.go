@@ -28,11 +28,23 @@ type TokenManager struct {
 	AccessTokenTTL  time.Duration // Время жизни токенов доступа.
 	RefreshTokenTTL time.Duration // Время жизни токенов продления.
 
+	// Keys набор ключей подписи. Если задан, токены подписываются текущим ключом набора с указанием заголовка kid и
+	// проверяются ключом из заголовка kid, см. KeySet. Поля SigningMethod, Secret, PrivateKey и PublicKey не используются.
+	Keys *KeySet
+
 	NewRichToken func() RichToken
 }
 
 // GetSigningSecret получает секрет для подписи в зависимости от алгоритма из конфигурации.
 func (m *TokenManager) GetSigningSecret() any {
+	if m.Keys != nil {
+		if key, err := m.Keys.SigningKey(); err == nil {
+			return key.PrivateKey
+		}
+
+		return nil
+	}
+
 	if _, ok := m.SigningMethod.(*jwt.SigningMethodHMAC); ok {
 		return m.Secret
 	}
@@ -42,6 +54,10 @@ func (m *TokenManager) GetSigningSecret() any {
 
 // GetParsingSecret получает секрет для проверки подписи в зависимости от алгоритма из конфигурации.
 func (m *TokenManager) GetParsingSecret() any {
+	if m.Keys != nil {
+		return m.Keys
+	}
+
 	if _, ok := m.SigningMethod.(*jwt.SigningMethodHMAC); ok {
 		return m.Secret
 	}
@@ -54,14 +70,7 @@ func (m *TokenManager) CreateAccessToken(auth security.Authentication, extra ...
 	richToken := m.NewRichToken()
 	richToken.ForAccess(auth, m.AccessTokenTTL, m.Audience, m.Issuer, extra...)
 
-	token := jwt.NewWithClaims(m.SigningMethod, richToken.GetClaims())
-	jwtToken, err := token.SignedString(m.GetSigningSecret())
-	if err != nil {
-		return nil, fmt.Errorf("can't create jwt: %w", err)
-	}
-	richToken.SetBearerToken(jwtToken)
-
-	return richToken, nil
+	return m.sign(richToken)
 }
 
 // CreateRefreshToken см. Manager.CreateRefreshToken().
@@ -69,8 +78,38 @@ func (m *TokenManager) CreateRefreshToken(tokenID uuid.UUID) (RichToken, error)
 	richToken := m.NewRichToken()
 	richToken.ForRefresh(tokenID, m.RefreshTokenTTL, m.Audience, m.Issuer)
 
-	token := jwt.NewWithClaims(m.SigningMethod, richToken.GetClaims())
-	jwtToken, err := token.SignedString(m.GetSigningSecret())
+	return m.sign(richToken)
+}
+
+// ParseToken см. Manager.ParseToken().
+func (m *TokenManager) ParseToken(jwtToken security.BearerToken) (RichToken, error) {
+	if m.Keys != nil {
+		return parseToken(jwtToken, m.NewRichToken, m.Keys.Keyfunc)
+	}
+
+	return parseToken(jwtToken, m.NewRichToken, func(*jwt.Token) (any, error) { return m.GetParsingSecret(), nil })
+}
+
+func (m *TokenManager) sign(richToken RichToken) (RichToken, error) {
+	method, secret := m.SigningMethod, m.GetSigningSecret()
+
+	var kid string
+
+	if m.Keys != nil {
+		key, err := m.Keys.SigningKey()
+		if err != nil {
+			return nil, fmt.Errorf("can't create jwt: %w", err)
+		}
+
+		method, secret, kid = key.Method, key.PrivateKey, key.ID
+	}
+
+	token := jwt.NewWithClaims(method, richToken.GetClaims())
+	if kid != "" {
+		token.Header[HeaderKeyID] = kid
+	}
+
+	jwtToken, err := token.SignedString(secret)
 	if err != nil {
 		return nil, fmt.Errorf("can't create jwt: %w", err)
 	}
@@ -79,11 +118,15 @@ func (m *TokenManager) CreateRefreshToken(tokenID uuid.UUID) (RichToken, error)
 	return richToken, nil
 }
 
-// ParseToken см. Manager.ParseToken().
-func (m *TokenManager) ParseToken(jwtToken security.BearerToken) (RichToken, error) {
-	richToken := m.NewRichToken()
+func parseToken(
+	jwtToken security.BearerToken,
+	newRichToken func() RichToken,
+	keyfunc jwt.Keyfunc,
+	opts ...jwt.ParserOption,
+) (RichToken, error) {
+	richToken := newRichToken()
 
-	token, err := jwt.ParseWithClaims(string(jwtToken), richToken.GetClaims(), func(*jwt.Token) (any, error) { return m.GetParsingSecret(), nil })
+	token, err := jwt.ParseWithClaims(string(jwtToken), richToken.GetClaims(), keyfunc, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("can't parse bearer token: %w", err)
 	}
@@ -116,6 +159,20 @@ func NewTokenManager(cfg *ManagerConfig, newRichToken func() RichToken) (*TokenM
 		manager.SigningMethod = jwt.SigningMethodHS256
 	}
 
+	if cfg.Keys != nil {
+		manager.Keys = cfg.Keys
+		if key, err := cfg.Keys.SigningKey(); err == nil {
+			manager.SigningMethod = key.Method
+		}
+
+		return &manager, nil
+	}
+
+	switch manager.SigningMethod.(type) {
+	case *jwt.SigningMethodECDSA, *jwt.SigningMethodEd25519:
+		return newKeySetManager(manager, cfg)
+	}
+
 	if len(cfg.PublicKeyPath) > 0 {
 		publicKeyBytes, err := os.ReadFile(cfg.PublicKeyPath)
 		if err != nil {
@@ -144,5 +201,47 @@ func NewTokenManager(cfg *ManagerConfig, newRichToken func() RichToken) (*TokenM
 		return nil, errors.New("HMAC secret empty") //nolint:goerr113
 	}
 
+	if cfg.KeyID != "" {
+		key := Key{ID: cfg.KeyID, Method: manager.SigningMethod, PrivateKey: manager.Secret, PublicKey: manager.Secret}
+		if _, ok := manager.SigningMethod.(*jwt.SigningMethodHMAC); !ok {
+			key.PrivateKey, key.PublicKey = nil, nil
+			if manager.PublicKey != nil {
+				key.PublicKey = manager.PublicKey
+			}
+			if manager.PrivateKey != nil {
+				key.PrivateKey, key.PublicKey = manager.PrivateKey, &manager.PrivateKey.PublicKey
+			}
+		}
+
+		keys, err := NewKeySet(key)
+		if err != nil {
+			return nil, err
+		}
+		manager.Keys = keys
+	}
+
+	return &manager, nil
+}
+
+// newKeySetManager загружает ключ ECDSA или Ed25519 в набор ключей менеджера. Если идентификатор ключа не задан,
+// используется отпечаток ключа.
+func newKeySetManager(manager TokenManager, cfg *ManagerConfig) (*TokenManager, error) {
+	key, err := LoadKeyFromPEM(cfg.KeyID, manager.SigningMethod, cfg.PrivateKeyPath, cfg.PublicKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.ID == "" {
+		jwk, err := NewJWK(key)
+		if err != nil {
+			return nil, err
+		}
+		key.ID = jwk.Thumbprint()
+	}
+
+	if manager.Keys, err = NewKeySet(key); err != nil {
+		return nil, err
+	}
+
 	return &manager, nil
 }
@@ -0,0 +1,307 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/core/security"
+)
+
+const DefaultRefreshKeyPrefix = "jwt:refresh" // Префикс ключей хранилища семейств токенов продления по умолчанию.
+
+// errRefreshConflict состояние семейства изменено одновременным запросом.
+var errRefreshConflict = errors.New("refresh token family is changed concurrently")
+
+type (
+	// Tokens пара токенов доступа и продления.
+	Tokens struct {
+		AccessToken  security.BearerToken `json:"access_token"`  //nolint:tagliatelle
+		RefreshToken security.BearerToken `json:"refresh_token"` //nolint:tagliatelle
+	}
+
+	// RefreshService обменивает токен продления на новую пару токенов (ротация токенов продления). Токены, выпущенные
+	// в рамках одного входа, образуют семейство, действителен только последний токен продления семейства. Повторное
+	// предъявление уже использованного токена продления означает его утечку: семейство отзывается целиком, вместе с
+	// последним выпущенным токеном доступа, если задано хранилище отозванных токенов (см. WithRefreshRevocations).
+	//
+	// Семейство действует не дольше ttl с момента входа: ротация не продлевает его, поэтому полномочия, сохраненные
+	// при входе, не живут бесконечно. Токены продления, отозванные в хранилище отозванных токенов (по идентификатору,
+	// пользователю или сессии, см. RevocationStore), отклоняются.
+	//
+	// Состояние семейств хранится в memorystore.MemoryStore. Если хранилище реализует memorystore.CompareAndSwapper,
+	// ротация атомарна: из одновременных запросов с одним токеном продления успешен только один, остальные считаются
+	// повторным использованием. Без этой возможности такие запросы могут оба завершиться успешно.
+	RefreshService struct {
+		manager     Manager
+		store       memorystore.MemoryStore
+		revocations *RevocationStore
+		prefix      string
+		ttl         time.Duration
+		now         func() time.Time
+	}
+
+	// RefreshOption опция RefreshService.
+	RefreshOption func(*RefreshService)
+
+	// refreshFamily состояние семейства токенов продления.
+	refreshFamily struct {
+		Authentication  security.Authentication `json:"authentication"`
+		RefreshID       uuid.UUID               `json:"refresh_id"`        //nolint:tagliatelle
+		AccessID        uuid.UUID               `json:"access_id"`         //nolint:tagliatelle
+		AccessExpiresAt time.Time               `json:"access_expires_at"` //nolint:tagliatelle
+		ExpiresAt       time.Time               `json:"expires_at"`        //nolint:tagliatelle // Срок действия семейства.
+	}
+)
+
+// Issue выпускает пару токенов для аутентифицированного пользователя и открывает новое семейство токенов продления.
+// Вызывается при входе пользователя.
+func (s *RefreshService) Issue(ctx context.Context, auth security.Authentication) (Tokens, error) {
+	return s.issue(ctx, uuid.New(), refreshFamily{Authentication: auth, ExpiresAt: s.now().Add(s.ttl)}, nil)
+}
+
+// Refresh обменивает токен продления на новую пару токенов, предъявленный токен становится недействительным. Вернет
+// ошибку errs.ErrAuthFailure, если токен недействителен, а его семейство отозвано или повторно использовано.
+func (s *RefreshService) Refresh(ctx context.Context, refreshToken security.BearerToken) (Tokens, error) {
+	token, familyID, family, stored, err := s.lookup(ctx, refreshToken)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	if family.RefreshID != token.GetID() {
+		return Tokens{}, s.reused(ctx, familyID, token.GetID(), family)
+	}
+
+	tokens, err := s.issue(ctx, familyID, family, stored)
+	if errors.Is(err, errRefreshConflict) {
+		return Tokens{}, s.reused(ctx, familyID, token.GetID(), family)
+	}
+
+	return tokens, err
+}
+
+// Logout отзывает семейство, к которому принадлежит токен продления, и последний выпущенный в нем токен доступа.
+func (s *RefreshService) Logout(ctx context.Context, refreshToken security.BearerToken) error {
+	_, familyID, family, _, err := s.lookup(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+
+	return s.revoke(ctx, familyID, family)
+}
+
+// reused отзывает семейство, токен продления которого предъявлен повторно.
+func (s *RefreshService) reused(ctx context.Context, familyID, tokenID uuid.UUID, family refreshFamily) error {
+	logs.FromContext(ctx).Warn().
+		Str("family_id", familyID.String()).
+		Str("token_id", tokenID.String()).
+		Msg("refresh token reuse detected, token family is revoked")
+
+	if err := s.revoke(ctx, familyID, family); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: refresh %w", errs.ErrAuthFailure, security.ErrTokenRevoked)
+}
+
+// issue выпускает пару токенов семейства family. Если задано прежнее состояние семейства stored и хранилище
+// поддерживает сравнение с заменой, состояние заменяется атомарно, иначе вернет ошибку errRefreshConflict.
+func (s *RefreshService) issue(
+	ctx context.Context,
+	familyID uuid.UUID,
+	family refreshFamily,
+	stored *memorystore.Value,
+) (Tokens, error) {
+	ttl := family.ExpiresAt.Sub(s.now())
+	if ttl <= 0 {
+		return Tokens{}, errs.Wrapf(errs.ErrAuthFailure, "refresh token family is expired")
+	}
+
+	access, err := s.manager.CreateAccessToken(family.Authentication)
+	if err != nil {
+		return Tokens{}, fmt.Errorf("can't issue access token: %w", err)
+	}
+
+	refresh, err := s.manager.CreateRefreshToken(access.GetID())
+	if err != nil {
+		return Tokens{}, fmt.Errorf("can't issue refresh token: %w", err)
+	}
+
+	family.RefreshID = refresh.GetID()
+	family.AccessID = access.GetID()
+	family.AccessExpiresAt = access.GetExpiresAt()
+
+	// Состояние семейства записывается раньше ключа токена: токен, не попавший в семейство, недействителен.
+	if err = s.saveFamily(ctx, familyID, family, stored, ttl); err != nil {
+		return Tokens{}, err
+	}
+
+	if err = s.store.Set(ctx, s.tokenKey(refresh.GetID()), familyID, ttl); err != nil {
+		return Tokens{}, fmt.Errorf("can't save refresh token: %w", err)
+	}
+
+	return Tokens{
+		AccessToken:  access.GetBearerToken(),
+		RefreshToken: refresh.GetBearerToken(),
+	}, nil
+}
+
+func (s *RefreshService) saveFamily(
+	ctx context.Context,
+	familyID uuid.UUID,
+	family refreshFamily,
+	stored *memorystore.Value,
+	ttl time.Duration,
+) error {
+	swapper, ok := s.store.(memorystore.CompareAndSwapper)
+	if stored == nil || !ok {
+		if err := s.store.Set(ctx, s.familyKey(familyID), family, ttl); err != nil {
+			return fmt.Errorf("can't save refresh token family: %w", err)
+		}
+
+		return nil
+	}
+
+	swapped, err := swapper.CompareAndSwap(ctx, s.familyKey(familyID), stored, family, ttl)
+	if err != nil {
+		return fmt.Errorf("can't save refresh token family: %w", err)
+	}
+
+	if !swapped {
+		return errRefreshConflict
+	}
+
+	return nil
+}
+
+// lookup находит семейство токена продления и проверяет, что токен не отозван, а семейство не истекло. Вместе с
+// семейством возвращает его сохраненное значение для сравнения с заменой.
+func (s *RefreshService) lookup(
+	ctx context.Context,
+	refreshToken security.BearerToken,
+) (RichToken, uuid.UUID, refreshFamily, *memorystore.Value, error) {
+	var family refreshFamily
+
+	token, err := s.manager.ParseToken(refreshToken)
+	if err != nil {
+		return nil, uuid.Nil, family, nil, errs.With(errs.ErrAuthFailure, err)
+	}
+
+	value, err := s.store.Get(ctx, s.tokenKey(token.GetID()))
+	if err != nil {
+		return nil, uuid.Nil, family, nil, s.lookupError(err)
+	}
+
+	var familyID uuid.UUID
+	if err = value.Struct(&familyID); err != nil {
+		return nil, uuid.Nil, family, nil, fmt.Errorf("can't read refresh token: %w", err)
+	}
+
+	stored, err := s.store.Get(ctx, s.familyKey(familyID))
+	if err != nil {
+		return nil, uuid.Nil, family, nil, s.lookupError(err)
+	}
+
+	if err = stored.Struct(&family); err != nil {
+		return nil, uuid.Nil, family, nil, fmt.Errorf("can't read refresh token family: %w", err)
+	}
+
+	// Семейства, открытые до появления срока действия, действуют ttl с первой ротации.
+	if family.ExpiresAt.IsZero() {
+		family.ExpiresAt = s.now().Add(s.ttl)
+	}
+
+	if !s.now().Before(family.ExpiresAt) {
+		return nil, uuid.Nil, family, nil, errs.Wrapf(errs.ErrAuthFailure, "refresh token family is expired")
+	}
+
+	if s.revocations != nil {
+		auth := family.Authentication
+
+		revoked, err := s.revocations.isRevoked(ctx, token.GetID(), auth.User.ID, auth.SessionID, token.GetIssuedAt())
+		if err != nil {
+			return nil, uuid.Nil, family, nil, err
+		}
+
+		if revoked {
+			return nil, uuid.Nil, family, nil, fmt.Errorf("%w: refresh %w", errs.ErrAuthFailure, security.ErrTokenRevoked)
+		}
+	}
+
+	return token, familyID, family, stored, nil
+}
+
+func (s *RefreshService) lookupError(err error) error {
+	if errors.Is(err, memorystore.ErrKeyNotFound) {
+		return errs.Wrapf(errs.ErrAuthFailure, "refresh token is revoked")
+	}
+
+	return fmt.Errorf("can't read refresh token: %w", err)
+}
+
+func (s *RefreshService) revoke(ctx context.Context, familyID uuid.UUID, family refreshFamily) error {
+	// Ключи токенов семейства не удаляются: они нужны, чтобы отличить отозванный токен от чужого.
+	if _, err := s.store.Delete(ctx, s.familyKey(familyID)); err != nil {
+		return fmt.Errorf("can't revoke refresh token family: %w", err)
+	}
+
+	if s.revocations != nil {
+		return s.revocations.RevokeID(ctx, family.AccessID, family.AccessExpiresAt)
+	}
+
+	return nil
+}
+
+func (s *RefreshService) tokenKey(id uuid.UUID) string {
+	return fmt.Sprintf("%s:token:%s", s.prefix, id)
+}
+
+func (s *RefreshService) familyKey(id uuid.UUID) string {
+	return fmt.Sprintf("%s:family:%s", s.prefix, id)
+}
+
+// WithRefreshRevocations устанавливает хранилище отозванных токенов, в которое попадает последний токен доступа
+// семейства при выходе пользователя или обнаружении повторного использования токена продления.
+func WithRefreshRevocations(revocations *RevocationStore) RefreshOption {
+	return func(s *RefreshService) {
+		s.revocations = revocations
+	}
+}
+
+// WithRefreshClock устанавливает источник текущего времени.
+func WithRefreshClock(now func() time.Time) RefreshOption {
+	return func(s *RefreshService) {
+		s.now = now
+	}
+}
+
+// WithRefreshKeyPrefix устанавливает префикс ключей хранилища.
+func WithRefreshKeyPrefix(prefix string) RefreshOption {
+	return func(s *RefreshService) {
+		s.prefix = prefix
+	}
+}
+
+// NewRefreshService возвращает новый RefreshService. Параметр ttl - срок действия семейства с момента входа, должен
+// быть не меньше времени жизни токенов продления (ManagerConfig.RefreshTokenTTL).
+func NewRefreshService(manager Manager, store memorystore.MemoryStore, ttl time.Duration, opts ...RefreshOption) *RefreshService {
+	service := &RefreshService{
+		manager: manager,
+		store:   store,
+		prefix:  DefaultRefreshKeyPrefix,
+		ttl:     ttl,
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	return service
+}
@@ -0,0 +1,195 @@
+package jwt_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/core/memorystore/local"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/jwt"
+)
+
+func TestRefreshService(t *testing.T) {
+	ctx := context.Background()
+	auth := security.Authentication{
+		User:        security.User{ID: uuid.New(), Name: "user"},
+		Authorities: security.Authorities{"user"},
+	}
+
+	manager := newTestManager(t)
+	revocations := jwt.NewRevocationStore(newMemoryStore(t), 24*time.Hour)
+	service := jwt.NewRefreshService(manager, newMemoryStore(t), 24*time.Hour, jwt.WithRefreshRevocations(revocations))
+	provider := jwt.NewRevocableAuthenticationProvider(manager, revocations)
+
+	first, err := service.Issue(ctx, auth)
+	require.NoError(t, err)
+
+	second, err := service.Refresh(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	authentication, err := provider.Authenticate(ctx, second.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, auth.User.ID, authentication.User.ID)
+	assert.Equal(t, auth.Authorities, authentication.Authorities)
+
+	// Повторное использование старого токена отзывает семейство целиком.
+	_, err = service.Refresh(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, errs.ErrAuthFailure)
+
+	_, err = service.Refresh(ctx, second.RefreshToken)
+	assert.ErrorIs(t, err, errs.ErrAuthFailure)
+
+	_, err = provider.Authenticate(ctx, second.AccessToken)
+	assert.ErrorIs(t, err, errs.ErrAuthFailure)
+}
+
+func TestRefreshService_Logout(t *testing.T) {
+	ctx := context.Background()
+	auth := security.Authentication{User: security.User{ID: uuid.New(), Name: "user"}}
+
+	manager := newTestManager(t)
+	service := jwt.NewRefreshService(manager, newMemoryStore(t), 24*time.Hour)
+
+	tokens, err := service.Issue(ctx, auth)
+	require.NoError(t, err)
+
+	other, err := service.Issue(ctx, auth)
+	require.NoError(t, err)
+
+	require.NoError(t, service.Logout(ctx, tokens.RefreshToken))
+
+	_, err = service.Refresh(ctx, tokens.RefreshToken)
+	assert.ErrorIs(t, err, errs.ErrAuthFailure)
+
+	// Другие входы пользователя не затрагиваются.
+	_, err = service.Refresh(ctx, other.RefreshToken)
+	assert.NoError(t, err)
+
+	// Токен доступа не является токеном продления.
+	_, err = service.Refresh(ctx, other.AccessToken)
+	assert.ErrorIs(t, err, errs.ErrAuthFailure)
+}
+
+func TestRefreshService_revocations(t *testing.T) {
+	ctx := context.Background()
+	sessionID := uuid.NewString()
+	auth := security.Authentication{User: security.User{ID: uuid.New(), Name: "user"}, SessionID: sessionID}
+
+	manager := newTestManager(t)
+
+	tests := []struct {
+		name   string
+		revoke func(revocations *jwt.RevocationStore, tokens jwt.Tokens) error
+	}{
+		{
+			name: "Отзыв токена продления",
+			revoke: func(revocations *jwt.RevocationStore, tokens jwt.Tokens) error {
+				refresh, err := manager.ParseToken(tokens.RefreshToken)
+				require.NoError(t, err)
+
+				return revocations.Revoke(ctx, refresh)
+			},
+		},
+		{
+			name: "Отзыв всех токенов пользователя",
+			revoke: func(revocations *jwt.RevocationStore, _ jwt.Tokens) error {
+				return revocations.RevokeSubject(ctx, auth.User.ID, time.Now())
+			},
+		},
+		{
+			name: "Отзыв токенов сессии",
+			revoke: func(revocations *jwt.RevocationStore, _ jwt.Tokens) error {
+				return revocations.RevokeSession(ctx, sessionID, time.Now())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revocations := jwt.NewRevocationStore(newMemoryStore(t), 24*time.Hour)
+			service := jwt.NewRefreshService(manager, newMemoryStore(t), 24*time.Hour, jwt.WithRefreshRevocations(revocations))
+
+			tokens, err := service.Issue(ctx, auth)
+			require.NoError(t, err)
+
+			require.NoError(t, tt.revoke(revocations, tokens))
+
+			_, err = service.Refresh(ctx, tokens.RefreshToken)
+			require.ErrorIs(t, err, errs.ErrAuthFailure)
+			assert.ErrorIs(t, err, security.ErrTokenRevoked)
+		})
+	}
+}
+
+// swapHookStore вызывает hook перед первым сравнением с заменой, имитируя одновременный запрос.
+type swapHookStore struct {
+	*local.Store
+	hook func()
+}
+
+func (s *swapHookStore) CompareAndSwap(ctx context.Context, key string, old *memorystore.Value, value any, expiration time.Duration) (bool, error) {
+	if hook := s.hook; hook != nil {
+		s.hook = nil
+		hook()
+	}
+
+	return s.Store.CompareAndSwap(ctx, key, old, value, expiration)
+}
+
+func TestRefreshService_concurrentRefresh(t *testing.T) {
+	ctx := context.Background()
+	auth := security.Authentication{User: security.User{ID: uuid.New(), Name: "user"}}
+
+	store := &swapHookStore{Store: newMemoryStore(t)}
+	service := jwt.NewRefreshService(newTestManager(t), store, 24*time.Hour)
+
+	tokens, err := service.Issue(ctx, auth)
+	require.NoError(t, err)
+
+	var concurrent jwt.Tokens
+
+	store.hook = func() {
+		concurrent, err = service.Refresh(ctx, tokens.RefreshToken)
+		require.NoError(t, err)
+	}
+
+	// Запрос, проигравший гонку, считается повторным использованием: семейство отзывается.
+	_, err = service.Refresh(ctx, tokens.RefreshToken)
+	require.ErrorIs(t, err, errs.ErrAuthFailure)
+
+	_, err = service.Refresh(ctx, concurrent.RefreshToken)
+	assert.ErrorIs(t, err, errs.ErrAuthFailure)
+}
+
+func TestRefreshService_absoluteExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	auth := security.Authentication{User: security.User{ID: uuid.New(), Name: "user"}}
+
+	service := jwt.NewRefreshService(newTestManager(t), newMemoryStore(t), 24*time.Hour,
+		jwt.WithRefreshClock(func() time.Time { return now }))
+
+	tokens, err := service.Issue(ctx, auth)
+	require.NoError(t, err)
+
+	// Ротация не продлевает семейство.
+	for _, elapsed := range []time.Duration{8 * time.Hour, 8 * time.Hour, 7 * time.Hour} {
+		now = now.Add(elapsed)
+
+		tokens, err = service.Refresh(ctx, tokens.RefreshToken)
+		require.NoError(t, err)
+	}
+
+	now = now.Add(time.Hour)
+
+	_, err = service.Refresh(ctx, tokens.RefreshToken)
+	assert.ErrorIs(t, err, errs.ErrAuthFailure)
+}
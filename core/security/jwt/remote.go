@@ -0,0 +1,245 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/core/security"
+)
+
+const (
+	DefaultJWKSRefreshInterval    = time.Hour        // Интервал обновления JWKS по умолчанию.
+	DefaultJWKSMinRefreshInterval = 10 * time.Second // Минимальный интервал между загрузками JWKS по умолчанию.
+	DefaultJWKSFetchTimeout       = 10 * time.Second // Таймаут загрузки JWKS по умолчанию.
+)
+
+var (
+	ErrJWKSFetch = errors.New("can't fetch jwks") // Не удалось загрузить JWKS.
+
+	_ TokenParser = (*JWKSTokenParser)(nil)
+)
+
+type (
+	// RemoteKeySet набор ключей проверки подписи, загружаемый в формате JWKS по адресу URL. Ключи кешируются и
+	// обновляются раз в refreshInterval, а также при появлении токена с неизвестным kid (не чаще minRefreshInterval).
+	// Если обновить ключи не удалось, используются ранее загруженные.
+	RemoteKeySet struct {
+		url                string
+		client             *http.Client
+		refreshInterval    time.Duration
+		minRefreshInterval time.Duration
+		now                func() time.Time
+
+		mu        sync.RWMutex
+		keys      map[string]Key
+		fetchedAt time.Time
+
+		fetchMu   sync.Mutex
+		attemptAt time.Time
+	}
+
+	// RemoteKeySetOption опция RemoteKeySet.
+	RemoteKeySetOption func(*RemoteKeySet)
+
+	// JWKSTokenParser реализует TokenParser для токенов, подписанных ключами, опубликованными другим сервисом в формате
+	// JWKS (см. JWKSHandler).
+	JWKSTokenParser struct {
+		Keys         *RemoteKeySet
+		NewRichToken func() RichToken
+		Options      []jwt.ParserOption // Дополнительные параметры проверки токенов (издатель, аудитория и т.п.).
+	}
+)
+
+// Keyfunc см. jwt.Keyfunc.
+func (s *RemoteKeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header[HeaderKeyID].(string)
+
+	key, err := s.Key(context.Background(), kid)
+	if err != nil {
+		return nil, err
+	}
+
+	return verificationKey(key, token.Method)
+}
+
+// Key возвращает ключ kid, при необходимости обновляя набор ключей. Если kid пуст и в наборе единственный ключ,
+// возвращается он.
+func (s *RemoteKeySet) Key(ctx context.Context, kid string) (Key, error) {
+	key, found, fresh := s.cached(kid)
+	if found && fresh {
+		return key, nil
+	}
+
+	if err := s.refresh(ctx, !found); err != nil {
+		if found {
+			logs.FromContext(ctx).Warn().Err(err).Msgf("using stale jwks from %s", s.url)
+
+			return key, nil
+		}
+
+		return Key{}, err
+	}
+
+	if key, found, _ = s.cached(kid); !found {
+		return Key{}, fmt.Errorf("%w: %s", ErrKeyNotFound, kid)
+	}
+
+	return key, nil
+}
+
+// Refresh принудительно загружает набор ключей.
+func (s *RemoteKeySet) Refresh(ctx context.Context) error {
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+
+	return s.fetch(ctx)
+}
+
+func (s *RemoteKeySet) cached(kid string) (Key, bool, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fresh := !s.fetchedAt.IsZero() && s.now().Sub(s.fetchedAt) < s.refreshInterval
+
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true, fresh
+		}
+	}
+
+	key, ok := s.keys[kid]
+
+	return key, ok, fresh
+}
+
+func (s *RemoteKeySet) refresh(ctx context.Context, missing bool) error {
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+
+	s.mu.RLock()
+	fetchedAt := s.fetchedAt
+	s.mu.RUnlock()
+
+	// Пока ожидали блокировку, ключи мог обновить другой запрос.
+	now := s.now()
+	if stale := fetchedAt.IsZero() || now.Sub(fetchedAt) >= s.refreshInterval; !stale && !missing {
+		return nil
+	}
+
+	// Не загружаем ключи слишком часто, например, при подборе kid или недоступности источника.
+	if !s.attemptAt.IsZero() && now.Sub(s.attemptAt) < s.minRefreshInterval {
+		return nil
+	}
+
+	return s.fetch(ctx)
+}
+
+func (s *RemoteKeySet) fetch(ctx context.Context) error {
+	s.attemptAt = s.now()
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultJWKSFetchTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrJWKSFetch, err)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrJWKSFetch, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s responded with status %d", ErrJWKSFetch, s.url, response.StatusCode)
+	}
+
+	var jwks JWKS
+	if err = json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(&jwks); err != nil { //nolint:gomnd
+		return fmt.Errorf("%w: %w", ErrJWKSFetch, err)
+	}
+
+	keys := make(map[string]Key, len(jwks.Keys))
+
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != keyUseSignature {
+			continue
+		}
+
+		key, err := jwk.Key()
+		if err != nil {
+			logs.FromContext(ctx).Warn().Err(err).Msgf("skipping jwk %s from %s", jwk.Kid, s.url)
+
+			continue
+		}
+
+		keys[key.ID] = key
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.fetchedAt = s.now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+// GetParsingSecret см. TokenParser.
+func (p *JWKSTokenParser) GetParsingSecret() any {
+	return p.Keys
+}
+
+// ParseToken см. TokenParser.
+func (p *JWKSTokenParser) ParseToken(token security.BearerToken) (RichToken, error) {
+	return parseToken(token, p.NewRichToken, p.Keys.Keyfunc, p.Options...)
+}
+
+// WithHTTPClient устанавливает HTTP клиент загрузки JWKS.
+func WithHTTPClient(client *http.Client) RemoteKeySetOption {
+	return func(s *RemoteKeySet) {
+		s.client = client
+	}
+}
+
+// WithRefreshInterval устанавливает интервал обновления ключей и минимальный интервал между загрузками.
+func WithRefreshInterval(refreshInterval, minRefreshInterval time.Duration) RemoteKeySetOption {
+	return func(s *RemoteKeySet) {
+		s.refreshInterval = refreshInterval
+		s.minRefreshInterval = minRefreshInterval
+	}
+}
+
+// NewRemoteKeySet возвращает набор ключей, загружаемый по адресу url. Ключи загружаются при первом обращении.
+func NewRemoteKeySet(url string, opts ...RemoteKeySetOption) *RemoteKeySet {
+	set := &RemoteKeySet{
+		url:                url,
+		client:             http.DefaultClient,
+		refreshInterval:    DefaultJWKSRefreshInterval,
+		minRefreshInterval: DefaultJWKSMinRefreshInterval,
+		now:                time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(set)
+	}
+
+	return set
+}
+
+// NewJWKSTokenParser возвращает TokenParser, проверяющий подписи токенов ключами из JWKS по адресу url.
+func NewJWKSTokenParser(url string, newRichToken func() RichToken, opts ...RemoteKeySetOption) *JWKSTokenParser {
+	return &JWKSTokenParser{
+		Keys:         NewRemoteKeySet(url, opts...),
+		NewRichToken: newRichToken,
+	}
+}
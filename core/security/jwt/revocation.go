@@ -0,0 +1,154 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wal1251/pkg/core/memorystore"
+)
+
+const DefaultRevocationKeyPrefix = "jwt:revoked" // Префикс ключей хранилища отозванных токенов по умолчанию.
+
+var _ RevocationChecker = (*RevocationStore)(nil)
+
+type (
+	// RevocationChecker проверяет, отозван ли токен.
+	RevocationChecker interface {
+		IsRevoked(ctx context.Context, token RichToken) (bool, error)
+	}
+
+	// RevocationStore хранилище отозванных токенов на базе memorystore.MemoryStore. Хранит идентификаторы отозванных
+	// токенов (RichToken.GetID) со временем жизни, равным оставшемуся сроку действия токена, а также отметки
+	// "отозвать все токены пользователя или сессии, выпущенные не позднее T" с точностью до миллисекунды.
+	//
+	// Время выпуска токена берется из RichToken.GetIssuedAt. DefaultRichToken передает его с точностью до
+	// миллисекунды (DefaultClaims.IssuedAtMs), для токенов, время выпуска которых известно с точностью до секунды,
+	// отзываются и токены, выпущенные в ту же секунду после отзыва.
+	RevocationStore struct {
+		store       memorystore.MemoryStore
+		prefix      string
+		maxTokenTTL time.Duration
+		now         func() time.Time
+	}
+)
+
+// Revoke отзывает токен до окончания срока его действия.
+func (s *RevocationStore) Revoke(ctx context.Context, token RichToken) error {
+	return s.RevokeID(ctx, token.GetID(), token.GetExpiresAt())
+}
+
+// RevokeID отзывает токен с идентификатором id, действующий до expiresAt. Просроченные токены не сохраняются.
+func (s *RevocationStore) RevokeID(ctx context.Context, id uuid.UUID, expiresAt time.Time) error {
+	ttl := expiresAt.Sub(s.now())
+	if ttl <= 0 {
+		return nil
+	}
+
+	if err := s.store.Set(ctx, s.tokenKey(id), true, ttl); err != nil {
+		return fmt.Errorf("can't revoke token %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// RevokeSubject отзывает все токены предмета (пользователя), выпущенные не позднее before.
+func (s *RevocationStore) RevokeSubject(ctx context.Context, subjectID uuid.UUID, before time.Time) error {
+	return s.revokeBefore(ctx, s.subjectKey(subjectID), before)
+}
+
+// RevokeSession отзывает все токены сессии, выпущенные не позднее before.
+func (s *RevocationStore) RevokeSession(ctx context.Context, sessionID string, before time.Time) error {
+	return s.revokeBefore(ctx, s.sessionKey(sessionID), before)
+}
+
+// IsRevoked см. RevocationChecker. Все отметки проверяются одним запросом к хранилищу.
+func (s *RevocationStore) IsRevoked(ctx context.Context, token RichToken) (bool, error) {
+	return s.isRevoked(ctx, token.GetID(), token.GetSubjectID(), token.GetSessionID(), token.GetIssuedAt())
+}
+
+// isRevoked проверяет, отозван ли токен id предмета subjectID и сессии sessionID, выпущенный в issuedAt.
+func (s *RevocationStore) isRevoked(
+	ctx context.Context,
+	id, subjectID uuid.UUID,
+	sessionID string,
+	issuedAt time.Time,
+) (bool, error) {
+	keys := []string{s.tokenKey(id), s.subjectKey(subjectID)}
+	if sessionID != "" {
+		keys = append(keys, s.sessionKey(sessionID))
+	}
+
+	values, err := s.store.GetList(ctx, keys...)
+	if err != nil {
+		return false, fmt.Errorf("can't check token revocation: %w", err)
+	}
+
+	if len(values) > 0 && values[0] != nil {
+		return true, nil
+	}
+
+	for _, value := range values[1:] {
+		if value == nil {
+			continue
+		}
+
+		before, err := value.Int64()
+		if err != nil {
+			return false, fmt.Errorf("can't check token revocation: %w", err)
+		}
+
+		if issuedAt.UnixMilli() <= before {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (s *RevocationStore) revokeBefore(ctx context.Context, key string, before time.Time) error {
+	// Отметка не должна сдвигаться назад, иначе ранее отозванные токены снова станут действительными.
+	value, err := s.store.Get(ctx, key)
+	if err != nil && !errors.Is(err, memorystore.ErrKeyNotFound) {
+		return fmt.Errorf("can't revoke tokens: %w", err)
+	}
+
+	if value != nil {
+		if current, err := value.Int64(); err == nil && current >= before.UnixMilli() {
+			return nil
+		}
+	}
+
+	if err = s.store.Set(ctx, key, before.UnixMilli(), s.maxTokenTTL); err != nil {
+		return fmt.Errorf("can't revoke tokens: %w", err)
+	}
+
+	return nil
+}
+
+func (s *RevocationStore) tokenKey(id uuid.UUID) string {
+	return fmt.Sprintf("%s:token:%s", s.prefix, id)
+}
+
+func (s *RevocationStore) subjectKey(id uuid.UUID) string {
+	return fmt.Sprintf("%s:subject:%s", s.prefix, id)
+}
+
+func (s *RevocationStore) sessionKey(id string) string {
+	return fmt.Sprintf("%s:session:%s", s.prefix, id)
+}
+
+// NewRevocationStore возвращает новый RevocationStore. Параметр maxTokenTTL - максимальный срок действия
+// выпускаемых токенов (как правило ManagerConfig.RefreshTokenTTL), столько хранятся отметки отзыва всех токенов
+// пользователя или сессии.
+func NewRevocationStore(store memorystore.MemoryStore, maxTokenTTL time.Duration) *RevocationStore {
+	return &RevocationStore{
+		store:       store,
+		prefix:      DefaultRevocationKeyPrefix,
+		maxTokenTTL: maxTokenTTL,
+		now:         time.Now,
+	}
+}
@@ -0,0 +1,183 @@
+package jwt_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/memorystore/local"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/jwt"
+)
+
+func newMemoryStore(t *testing.T) *local.Store {
+	t.Helper()
+
+	store, err := local.NewStore(&local.Config{})
+	require.NoError(t, err)
+
+	return store
+}
+
+func newTestManager(t *testing.T) *jwt.TokenManager {
+	t.Helper()
+
+	manager, err := jwt.NewTokenManager(&jwt.ManagerConfig{
+		Secret:          []byte("secret"),
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: 24 * time.Hour,
+	}, func() jwt.RichToken { return &jwt.DefaultRichToken{Claims: &jwt.DefaultClaims{}} })
+	require.NoError(t, err)
+
+	return manager
+}
+
+func TestRevocationStore(t *testing.T) {
+	ctx := context.Background()
+	auth := security.Authentication{User: security.User{ID: uuid.New(), Name: "user"}}
+
+	tests := []struct {
+		name    string
+		revoke  func(store *jwt.RevocationStore, token jwt.RichToken) error
+		revoked bool
+	}{
+		{
+			name:    "Not revoked",
+			revoke:  func(*jwt.RevocationStore, jwt.RichToken) error { return nil },
+			revoked: false,
+		},
+		{
+			name: "Token revoked",
+			revoke: func(store *jwt.RevocationStore, token jwt.RichToken) error {
+				return store.Revoke(ctx, token)
+			},
+			revoked: true,
+		},
+		{
+			name: "Other token revoked",
+			revoke: func(store *jwt.RevocationStore, token jwt.RichToken) error {
+				return store.RevokeID(ctx, uuid.New(), token.GetExpiresAt())
+			},
+			revoked: false,
+		},
+		{
+			name: "Expired token is not stored",
+			revoke: func(store *jwt.RevocationStore, token jwt.RichToken) error {
+				return store.RevokeID(ctx, token.GetID(), time.Now().Add(-time.Second))
+			},
+			revoked: false,
+		},
+		{
+			name: "User tokens revoked",
+			revoke: func(store *jwt.RevocationStore, token jwt.RichToken) error {
+				return store.RevokeSubject(ctx, token.GetSubjectID(), time.Now())
+			},
+			revoked: true,
+		},
+		{
+			name: "User tokens revoked before issue",
+			revoke: func(store *jwt.RevocationStore, token jwt.RichToken) error {
+				return store.RevokeSubject(ctx, token.GetSubjectID(), token.GetIssuedAt().Add(-time.Minute))
+			},
+			revoked: false,
+		},
+		{
+			name: "Cutoff is not moved back",
+			revoke: func(store *jwt.RevocationStore, token jwt.RichToken) error {
+				if err := store.RevokeSubject(ctx, token.GetSubjectID(), time.Now()); err != nil {
+					return err
+				}
+
+				return store.RevokeSubject(ctx, token.GetSubjectID(), token.GetIssuedAt().Add(-time.Minute))
+			},
+			revoked: true,
+		},
+		{
+			name: "User tokens issued later in the same second are kept",
+			revoke: func(store *jwt.RevocationStore, token jwt.RichToken) error {
+				return store.RevokeSubject(ctx, token.GetSubjectID(), token.GetIssuedAt().Add(-time.Millisecond))
+			},
+			revoked: false,
+		},
+		{
+			name: "Session tokens revoked",
+			revoke: func(store *jwt.RevocationStore, token jwt.RichToken) error {
+				return store.RevokeSession(ctx, token.GetSessionID(), time.Now())
+			},
+			revoked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t)
+			store := jwt.NewRevocationStore(newMemoryStore(t), 24*time.Hour)
+
+			created, err := manager.CreateAccessToken(auth)
+			require.NoError(t, err)
+
+			token, err := manager.ParseToken(created.GetBearerToken())
+			require.NoError(t, err)
+
+			require.NoError(t, tt.revoke(store, token))
+
+			revoked, err := store.IsRevoked(ctx, token)
+			require.NoError(t, err)
+			assert.Equal(t, tt.revoked, revoked)
+		})
+	}
+}
+
+func TestAuthenticationProvider_revoked(t *testing.T) {
+	ctx := context.Background()
+	manager := newTestManager(t)
+	store := jwt.NewRevocationStore(newMemoryStore(t), 24*time.Hour)
+	provider := jwt.NewRevocableAuthenticationProvider(manager, store)
+
+	token, err := manager.CreateAccessToken(security.Authentication{User: security.User{ID: uuid.New(), Name: "user"}})
+	require.NoError(t, err)
+
+	_, err = provider.Authenticate(ctx, token.GetBearerToken())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Revoke(ctx, token))
+
+	_, err = provider.Authenticate(ctx, token.GetBearerToken())
+	assert.ErrorIs(t, err, errs.ErrAuthFailure)
+}
+
+func TestRevocationStore_issuedAfterRevocationInSameSecond(t *testing.T) {
+	ctx := context.Background()
+	store := jwt.NewRevocationStore(newMemoryStore(t), 24*time.Hour)
+	second := time.Now().Truncate(time.Second)
+
+	// Выход "со всех устройств" и новый вход в ту же секунду.
+	before := &jwt.DefaultRichToken{ID: uuid.New(), SubjectID: uuid.New(), IssuedAt: second.Add(100 * time.Millisecond)}
+	after := &jwt.DefaultRichToken{ID: uuid.New(), SubjectID: before.SubjectID, IssuedAt: second.Add(600 * time.Millisecond)}
+
+	require.NoError(t, store.RevokeSubject(ctx, before.SubjectID, second.Add(300*time.Millisecond)))
+
+	revoked, err := store.IsRevoked(ctx, before)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = store.IsRevoked(ctx, after)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestTokenManager_ParseToken_issuedAtPrecision(t *testing.T) {
+	manager := newTestManager(t)
+
+	created, err := manager.CreateAccessToken(security.Authentication{User: security.User{ID: uuid.New(), Name: "user"}})
+	require.NoError(t, err)
+
+	token, err := manager.ParseToken(created.GetBearerToken())
+	require.NoError(t, err)
+	assert.Equal(t, created.GetIssuedAt().UnixMilli(), token.GetIssuedAt().UnixMilli())
+}
@@ -15,6 +15,8 @@ import (
 type DefaultClaims struct {
 	Name        string               `json:"prl"`
 	Authorities security.Authorities `json:"aus"`
+	TenantID    string               `json:"tnt,omitempty"`
+	IssuedAtMs  int64                `json:"iat_ms,omitempty"` // Время выпуска с точностью до миллисекунды, iat - до секунды.
 	jwt.RegisteredClaims
 }
 
@@ -82,6 +84,11 @@ func (r *DefaultRichToken) GetName() string {
 	return r.Claims.Name
 }
 
+// GetTenantID возвращает нестандартный клейм TenantID, см. TenantToken.
+func (r *DefaultRichToken) GetTenantID() string {
+	return r.Claims.TenantID
+}
+
 // GetAuthorities возвращает нестандартный клейм Authorities на основе ClaimsImpl.
 func (r *DefaultRichToken) GetAuthorities() []security.Authority {
 	return r.Claims.Authorities
@@ -116,6 +123,9 @@ func (r *DefaultRichToken) SetFromBearer(token jwt.Token) error {
 	} else {
 		return ErrInvalidTokenClaims
 	}
+	if c, ok := token.Claims.(*DefaultClaims); ok && c.IssuedAtMs/1000 == r.IssuedAt.Unix() {
+		r.IssuedAt = time.UnixMilli(c.IssuedAtMs)
+	}
 	if s, err := claims.GetIssuer(); err == nil {
 		r.Issuer = s
 	} else {
@@ -146,6 +156,8 @@ func (r *DefaultRichToken) ForAccess(auth security.Authentication, ttl time.Dura
 		RegisteredClaims: r.Claims.RegisteredClaims,
 		Name:             auth.User.Name,
 		Authorities:      auth.Authorities,
+		TenantID:         auth.TenantID,
+		IssuedAtMs:       r.Claims.IssuedAtMs,
 	}
 }
 
@@ -178,5 +190,6 @@ func (r *DefaultRichToken) PopulateRegisteredClaims(subjectID uuid.UUID, ttl tim
 	r.Issuer = issuer
 	r.Claims = &DefaultClaims{
 		RegisteredClaims: claims,
+		IssuedAtMs:       issuedAt.UnixMilli(),
 	}
 }
@@ -0,0 +1,34 @@
+package lockout
+
+import (
+	"time"
+
+	"github.com/wal1251/pkg/core/cfg"
+)
+
+const (
+	CfgKeyMaxUserAttempts cfg.Key = "LOCKOUT_MAX_USER_ATTEMPTS" // Число неуспешных попыток пользователя до блокировки (int).
+	CfgKeyMaxIPAttempts   cfg.Key = "LOCKOUT_MAX_IP_ATTEMPTS"   // Число неуспешных попыток с IP адреса до блокировки (int).
+	CfgKeyWindow          cfg.Key = "LOCKOUT_WINDOW"            // Окно подсчета неуспешных попыток (duration).
+	CfgKeyDuration        cfg.Key = "LOCKOUT_DURATION"          // Продолжительность первой блокировки (duration).
+	CfgKeyMaxDuration     cfg.Key = "LOCKOUT_MAX_DURATION"      // Максимальная продолжительность блокировки (duration).
+	CfgKeyResetAfter      cfg.Key = "LOCKOUT_RESET_AFTER"       // Время хранения истории блокировок (duration).
+
+	CfgDefaultMaxUserAttempts = 5                // Число неуспешных попыток пользователя по умолчанию.
+	CfgDefaultMaxIPAttempts   = 50               // Число неуспешных попыток с IP адреса по умолчанию.
+	CfgDefaultWindow          = 15 * time.Minute // Окно подсчета неуспешных попыток по умолчанию.
+	CfgDefaultDuration        = time.Minute      // Продолжительность первой блокировки по умолчанию.
+	CfgDefaultMaxDuration     = time.Hour        // Максимальная продолжительность блокировки по умолчанию.
+	CfgDefaultResetAfter      = 24 * time.Hour   // Время хранения истории блокировок по умолчанию.
+)
+
+// Config конфигурация защиты от перебора. Каждая следующая блокировка вдвое длиннее предыдущей, но не длиннее
+// MaxDuration. История блокировок забывается через ResetAfter после последней неуспешной попытки.
+type Config struct {
+	MaxUserAttempts int           // Число неуспешных попыток пользователя в окне Window до блокировки.
+	MaxIPAttempts   int           // Число неуспешных попыток с IP адреса в окне Window до блокировки.
+	Window          time.Duration // Окно подсчета неуспешных попыток.
+	Duration        time.Duration // Продолжительность первой блокировки.
+	MaxDuration     time.Duration // Максимальная продолжительность блокировки.
+	ResetAfter      time.Duration // Время хранения истории блокировок.
+}
@@ -0,0 +1,20 @@
+package lockout
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/wal1251/pkg/core/cfg"
+	"github.com/wal1251/pkg/core/cfg/viperx"
+)
+
+// CfgFromViper загрузка конфига Config с помощью viper.
+func CfgFromViper(loader *viper.Viper, keyMapping ...cfg.KeyMap) *Config {
+	return &Config{
+		MaxUserAttempts: viperx.Get(loader, CfgKeyMaxUserAttempts.Map(keyMapping...), CfgDefaultMaxUserAttempts),
+		MaxIPAttempts:   viperx.Get(loader, CfgKeyMaxIPAttempts.Map(keyMapping...), CfgDefaultMaxIPAttempts),
+		Window:          viperx.Get(loader, CfgKeyWindow.Map(keyMapping...), CfgDefaultWindow),
+		Duration:        viperx.Get(loader, CfgKeyDuration.Map(keyMapping...), CfgDefaultDuration),
+		MaxDuration:     viperx.Get(loader, CfgKeyMaxDuration.Map(keyMapping...), CfgDefaultMaxDuration),
+		ResetAfter:      viperx.Get(loader, CfgKeyResetAfter.Map(keyMapping...), CfgDefaultResetAfter),
+	}
+}
@@ -0,0 +1,431 @@
+// Package lockout предоставляет защиту от перебора учетных данных: неуспешные попытки аутентификации учитываются
+// отдельно по логину пользователя и по IP адресу клиента в memorystore.MemoryStore. После превышения порога попыток
+// логин или адрес блокируются, каждая следующая блокировка вдвое длиннее предыдущей (см. Config).
+//
+// Попытка во время блокировки отклоняется ошибкой errs.ErrTooManyRequests, оставшееся время блокировки доступно через
+// errs.RetryAfter, httpx передает его в заголовке Retry-After. Каждый исход попытки публикуется как security.Event.
+//
+// Provider подключает защиту к любому провайдеру аутентификации, например, в mw.Authorizer:
+//
+//	guard := lockout.NewGuard(store, lockout.CfgFromViper(loader), lockout.WithEvents(notifier))
+//	provider := lockout.NewProvider(guard, tokenProvider, security.AuthMethodToken, nil)
+//	router.Use(mw.Authorizer(authManager, httpx.BearerTokenExtract, provider))
+//
+// Неуспешные попытки учитываются атомарно, если хранилище реализует memorystore.ConditionalSetter и
+// memorystore.CompareAndSwapper (providers/redis, local.Store). С другими хранилищами при одновременных попытках часть
+// неуспешных попыток может быть не учтена.
+package lockout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/core/security"
+)
+
+const (
+	DefaultKeyPrefix = "lockout" // Префикс ключей хранилища счетчиков по умолчанию.
+
+	maxUpdateAttempts = 16 // Число попыток изменить счетчик при одновременных изменениях.
+)
+
+var (
+	_ security.AuthenticationProvider[security.Credentials] = (*Provider[security.Credentials])(nil)
+	_ security.AuthenticationProvider[security.BearerToken] = (*Provider[security.BearerToken])(nil)
+)
+
+type (
+	// Attempt попытка аутентификации.
+	Attempt struct {
+		Method string // Способ аутентификации, например security.AuthMethodPassword.
+		Login  string // Логин или иной идентификатор пользователя, указанный клиентом, может быть пустым.
+		IP     string // IP адрес клиента, может быть пустым.
+	}
+
+	// Guard учитывает неуспешные попытки аутентификации и блокирует логины и IP адреса.
+	Guard struct {
+		store  memorystore.MemoryStore
+		config Config
+		prefix string
+		events *security.EventNotifier
+		now    func() time.Time
+	}
+
+	// Option опция Guard.
+	Option func(*Guard)
+
+	// Provider провайдер аутентификации, защищенный от перебора учетных данных, реализует
+	// security.AuthenticationProvider.
+	Provider[T security.RequestCredentials] struct {
+		guard    *Guard
+		provider security.AuthenticationProvider[T]
+		method   string
+		login    func(T) string
+	}
+
+	// counter счетчик неуспешных попыток по логину или IP адресу.
+	counter struct {
+		Failures    int       `json:"failures"`
+		WindowStart time.Time `json:"window_start"` //nolint:tagliatelle
+		Lockouts    int       `json:"lockouts"`
+		LockedUntil time.Time `json:"locked_until"` //nolint:tagliatelle
+	}
+
+	counterKey struct {
+		key         string
+		maxAttempts int
+	}
+)
+
+// Check проверяет, что логин и IP адрес попытки не заблокированы. Вернет ошибку errs.ErrTooManyRequests с оставшимся
+// временем блокировки, см. errs.RetryAfter.
+func (g *Guard) Check(ctx context.Context, attempt Attempt) error {
+	keys := g.keys(attempt)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.key
+	}
+
+	values, err := g.store.GetList(ctx, names...)
+	if err != nil {
+		return fmt.Errorf("can't read lockout counters: %w", err)
+	}
+
+	now := g.now()
+
+	var lockedUntil time.Time
+
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+
+		var c counter
+		if err = value.Struct(&c); err != nil {
+			return fmt.Errorf("can't read lockout counter: %w", err)
+		}
+
+		if c.LockedUntil.After(lockedUntil) {
+			lockedUntil = c.LockedUntil
+		}
+	}
+
+	if !lockedUntil.After(now) {
+		return nil
+	}
+
+	return g.locked(ctx, attempt, lockedUntil.Sub(now))
+}
+
+// Failure учитывает неуспешную попытку с причиной reason. Если попытка привела к блокировке, вернет ошибку
+// errs.ErrTooManyRequests с временем блокировки.
+func (g *Guard) Failure(ctx context.Context, attempt Attempt, reason error) error {
+	event := g.event(security.EventAuthFailure, attempt)
+	if reason != nil {
+		event.Reason = reason.Error()
+	}
+
+	if errors.Is(reason, security.ErrTokenRevoked) {
+		event.Type = security.EventTokenRevoked
+	}
+
+	g.events.Notify(ctx, event)
+
+	now := g.now()
+
+	var lockedFor time.Duration
+
+	for _, key := range g.keys(attempt) {
+		c, err := g.update(ctx, key.key, func(c *counter) bool {
+			if c.LockedUntil.After(now) {
+				return false
+			}
+
+			if now.Sub(c.WindowStart) >= g.config.Window {
+				c.Failures = 0
+				c.WindowStart = now
+			}
+
+			c.Failures++
+
+			if c.Failures >= key.maxAttempts {
+				c.Lockouts++
+				c.Failures = 0
+				c.LockedUntil = now.Add(g.duration(c.Lockouts))
+			}
+
+			return true
+		})
+		if err != nil {
+			return err
+		}
+
+		if c.LockedUntil.After(now) {
+			lockedFor = max(lockedFor, c.LockedUntil.Sub(now))
+		}
+	}
+
+	if lockedFor > 0 {
+		return g.locked(ctx, attempt, lockedFor)
+	}
+
+	return nil
+}
+
+// Success учитывает успешную попытку: счетчик логина сбрасывается, счетчик IP адреса сохраняется, чтобы успешный вход
+// в одну учетную запись не снимал ограничение перебора с адреса.
+func (g *Guard) Success(ctx context.Context, attempt Attempt, auth security.Authentication) error {
+	event := g.event(security.EventAuthSuccess, attempt)
+	event.UserID = auth.User.ID
+	event.TokenID = auth.TokenID
+	g.events.Notify(ctx, event)
+
+	if attempt.Login == "" {
+		return nil
+	}
+
+	if _, err := g.store.Delete(ctx, g.userKey(attempt.Login)); err != nil {
+		return fmt.Errorf("can't reset lockout counter: %w", err)
+	}
+
+	return nil
+}
+
+func (g *Guard) locked(ctx context.Context, attempt Attempt, retryAfter time.Duration) error {
+	event := g.event(security.EventLockout, attempt)
+	event.RetryAfter = retryAfter
+	g.events.Notify(ctx, event)
+
+	logs.FromContext(ctx).Warn().
+		Str("method", attempt.Method).
+		Dur("retry_after", retryAfter).
+		Msg("authentication attempt rejected: too many failed attempts")
+
+	return errs.WithRetryAfter(errs.Wrapf(errs.ErrTooManyRequests, "too many failed authentication attempts"), retryAfter)
+}
+
+func (g *Guard) event(eventType security.EventType, attempt Attempt) security.Event {
+	return security.Event{
+		Type:   eventType,
+		Method: attempt.Method,
+		Login:  attempt.Login,
+		IP:     attempt.IP,
+		Time:   g.now(),
+	}
+}
+
+// update изменяет счетчик key функцией fn, которая вернет false, если счетчик не изменился. Если хранилище
+// поддерживает условную запись и сравнение с заменой, счетчик изменяется атомарно: при одновременном изменении fn
+// применяется к новому значению. Иначе счетчик перезаписывается, и часть одновременных попыток может быть не учтена.
+func (g *Guard) update(ctx context.Context, key string, fn func(c *counter) bool) (counter, error) {
+	setter, conditional := g.store.(memorystore.ConditionalSetter)
+	swapper, swappable := g.store.(memorystore.CompareAndSwapper)
+
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		var c counter
+
+		value, err := g.store.Get(ctx, key)
+		if err != nil && !errors.Is(err, memorystore.ErrKeyNotFound) {
+			return c, fmt.Errorf("can't read lockout counter: %w", err)
+		}
+
+		if value != nil {
+			if err = value.Struct(&c); err != nil {
+				return c, fmt.Errorf("can't read lockout counter: %w", err)
+			}
+		}
+
+		if !fn(&c) {
+			return c, nil
+		}
+
+		ttl := max(g.config.ResetAfter, c.LockedUntil.Sub(g.now()))
+
+		var saved bool
+
+		switch {
+		case !conditional || !swappable:
+			err = g.store.Set(ctx, key, c, ttl)
+			saved = true
+		case value == nil:
+			saved, err = setter.SetIfNotExists(ctx, key, c, ttl)
+		default:
+			saved, err = swapper.CompareAndSwap(ctx, key, value, c, ttl)
+		}
+
+		if err != nil {
+			return c, fmt.Errorf("can't save lockout counter: %w", err)
+		}
+
+		if saved {
+			return c, nil
+		}
+	}
+
+	return counter{}, fmt.Errorf("can't save lockout counter %s: too many concurrent updates", key)
+}
+
+// duration возвращает продолжительность блокировки с номером n: Duration, 2*Duration, 4*Duration ... MaxDuration.
+func (g *Guard) duration(n int) time.Duration {
+	duration := g.config.Duration
+	for i := 1; i < n && duration < g.config.MaxDuration; i++ {
+		duration *= 2
+	}
+
+	return min(duration, g.config.MaxDuration)
+}
+
+func (g *Guard) keys(attempt Attempt) []counterKey {
+	keys := make([]counterKey, 0, 2) //nolint:gomnd
+
+	if attempt.Login != "" {
+		keys = append(keys, counterKey{key: g.userKey(attempt.Login), maxAttempts: g.config.MaxUserAttempts})
+	}
+
+	if attempt.IP != "" {
+		keys = append(keys, counterKey{key: g.prefix + ":ip:" + attempt.IP, maxAttempts: g.config.MaxIPAttempts})
+	}
+
+	return keys
+}
+
+func (g *Guard) userKey(login string) string {
+	return g.prefix + ":user:" + strings.ToLower(login)
+}
+
+// Authenticate см. security.AuthenticationProvider. Пустые учетные данные (например, анонимный запрос) не
+// учитываются. Учитываются только ошибки errs.ErrAuthFailure, системные сбои провайдера не считаются попытками.
+func (p *Provider[T]) Authenticate(ctx context.Context, credentials T) (security.Authentication, error) {
+	var zero T
+	if credentials == zero {
+		return p.provider.Authenticate(ctx, credentials)
+	}
+
+	attempt := Attempt{
+		Method: p.method,
+		IP:     security.ClientIP(ctx),
+	}
+
+	if p.login != nil {
+		attempt.Login = p.login(credentials)
+	}
+
+	if err := p.guard.Check(ctx, attempt); err != nil {
+		return security.Authentication{}, err
+	}
+
+	auth, err := p.provider.Authenticate(ctx, credentials)
+	if err != nil {
+		if !errs.ErrAuthFailure.Is(err) {
+			return security.Authentication{}, err
+		}
+
+		if lockErr := p.guard.Failure(ctx, attempt, err); lockErr != nil {
+			if errs.ErrTooManyRequests.Is(lockErr) {
+				return security.Authentication{}, lockErr
+			}
+
+			logs.FromContext(ctx).Warn().Err(lockErr).Msg("failed to register failed authentication attempt")
+		}
+
+		return security.Authentication{}, err
+	}
+
+	if err = p.guard.Success(ctx, attempt, auth); err != nil {
+		logs.FromContext(ctx).Warn().Err(err).Msg("failed to register successful authentication attempt")
+	}
+
+	return auth, nil
+}
+
+// CredentialsLogin возвращает логин учетных данных, используется с NewProvider.
+func CredentialsLogin(credentials security.Credentials) string {
+	return credentials.Login
+}
+
+// NewProvider возвращает провайдер аутентификации provider, защищенный Guard. Функция login извлекает из учетных
+// данных логин пользователя, если она не указана (например, для токенов), попытки учитываются только по IP адресу.
+func NewProvider[T security.RequestCredentials](
+	guard *Guard,
+	provider security.AuthenticationProvider[T],
+	method string,
+	login func(T) string,
+) *Provider[T] {
+	return &Provider[T]{
+		guard:    guard,
+		provider: provider,
+		method:   method,
+		login:    login,
+	}
+}
+
+// WithKeyPrefix устанавливает префикс ключей хранилища.
+func WithKeyPrefix(prefix string) Option {
+	return func(g *Guard) {
+		g.prefix = prefix
+	}
+}
+
+// WithEvents устанавливает публикацию событий безопасности.
+func WithEvents(events *security.EventNotifier) Option {
+	return func(g *Guard) {
+		g.events = events
+	}
+}
+
+// WithClock устанавливает источник текущего времени.
+func WithClock(now func() time.Time) Option {
+	return func(g *Guard) {
+		g.now = now
+	}
+}
+
+// NewGuard возвращает новый Guard.
+func NewGuard(store memorystore.MemoryStore, config *Config, opts ...Option) *Guard {
+	guard := &Guard{
+		store:  store,
+		config: *config,
+		prefix: DefaultKeyPrefix,
+		now:    time.Now,
+	}
+
+	if guard.config.MaxUserAttempts <= 0 {
+		guard.config.MaxUserAttempts = CfgDefaultMaxUserAttempts
+	}
+
+	if guard.config.MaxIPAttempts <= 0 {
+		guard.config.MaxIPAttempts = CfgDefaultMaxIPAttempts
+	}
+
+	if guard.config.Window <= 0 {
+		guard.config.Window = CfgDefaultWindow
+	}
+
+	if guard.config.Duration <= 0 {
+		guard.config.Duration = CfgDefaultDuration
+	}
+
+	if guard.config.MaxDuration < guard.config.Duration {
+		guard.config.MaxDuration = max(CfgDefaultMaxDuration, guard.config.Duration)
+	}
+
+	if guard.config.ResetAfter <= 0 {
+		guard.config.ResetAfter = CfgDefaultResetAfter
+	}
+
+	for _, opt := range opts {
+		opt(guard)
+	}
+
+	return guard
+}
@@ -0,0 +1,256 @@
+package lockout_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/bus"
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/core/memorystore/local"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/lockout"
+)
+
+func newMemoryStore(t *testing.T, now func() time.Time) *local.Store {
+	t.Helper()
+
+	store, err := local.NewStore(&local.Config{}, local.WithClock(now))
+	require.NoError(t, err)
+
+	return store
+}
+
+type fixture struct {
+	now      time.Time
+	events   []security.Event
+	provider *lockout.Provider[security.Credentials]
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	f := &fixture{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	clock := func() time.Time { return f.now }
+
+	eventBus := bus.NewSyncEventBus[security.Event]()
+	require.NoError(t, eventBus.Subscribe(context.Background(), security.DefaultEventsTopic,
+		bus.SubscriberFn[security.Event](func(_ context.Context, events ...security.Event) error {
+			f.events = append(f.events, events...)
+
+			return nil
+		})))
+
+	guard := lockout.NewGuard(newMemoryStore(t, clock), &lockout.Config{
+		MaxUserAttempts: 3,
+		MaxIPAttempts:   5,
+		Duration:        time.Minute,
+		MaxDuration:     3 * time.Minute,
+	}, lockout.WithClock(clock), lockout.WithEvents(security.NewEventNotifier(eventBus, "")))
+
+	userID := uuid.New()
+	f.provider = lockout.NewProvider[security.Credentials](guard,
+		security.AuthenticationProviderFn[security.Credentials](
+			func(_ context.Context, credentials security.Credentials) (security.Authentication, error) {
+				if credentials.Password != "secret" {
+					return security.Authentication{}, errs.Wrapf(errs.ErrAuthFailure, "invalid login or password")
+				}
+
+				return security.Authentication{User: security.User{ID: userID, Name: credentials.Login}}, nil
+			}),
+		security.AuthMethodPassword, lockout.CredentialsLogin)
+
+	return f
+}
+
+func (f *fixture) login(ip, login, password string) error {
+	ctx := security.WithClientIP(context.Background(), ip)
+	_, err := f.provider.Authenticate(ctx, security.Credentials{Login: login, Password: security.Password(password)})
+
+	return err
+}
+
+func (f *fixture) eventTypes() []security.EventType {
+	types := make([]security.EventType, len(f.events))
+	for i, event := range f.events {
+		types[i] = event.Type
+	}
+
+	return types
+}
+
+func TestProvider_userLockout(t *testing.T) {
+	f := newFixture(t)
+
+	assert.ErrorIs(t, f.login("10.0.0.1", "john", "foo"), errs.ErrAuthFailure)
+	assert.ErrorIs(t, f.login("10.0.0.1", "john", "foo"), errs.ErrAuthFailure)
+
+	// Третья неуспешная попытка блокирует логин.
+	err := f.login("10.0.0.1", "john", "foo")
+	assert.ErrorIs(t, err, errs.ErrTooManyRequests)
+
+	retryAfter, ok := errs.RetryAfter(err)
+	require.True(t, ok)
+	assert.Equal(t, time.Minute, retryAfter)
+
+	// Во время блокировки отклоняется даже верный пароль, в том числе с другого адреса.
+	f.now = f.now.Add(20 * time.Second)
+	err = f.login("10.0.0.2", "John", "secret")
+	assert.ErrorIs(t, err, errs.ErrTooManyRequests)
+
+	retryAfter, _ = errs.RetryAfter(err)
+	assert.Equal(t, 40*time.Second, retryAfter)
+
+	// Блокировка не распространяется на другие логины.
+	assert.NoError(t, f.login("10.0.0.1", "jane", "secret"))
+
+	// Следующая блокировка длиннее предыдущей.
+	f.now = f.now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, f.login("10.0.0.3", "john", "foo"), errs.ErrAuthFailure)
+	}
+
+	retryAfter, _ = errs.RetryAfter(f.login("10.0.0.3", "john", "foo"))
+	assert.Equal(t, 2*time.Minute, retryAfter)
+
+	// После окончания блокировки успешный вход сбрасывает счетчик логина.
+	f.now = f.now.Add(2 * time.Minute)
+	assert.NoError(t, f.login("10.0.0.4", "john", "secret"))
+	assert.ErrorIs(t, f.login("10.0.0.4", "john", "foo"), errs.ErrAuthFailure)
+
+	assert.Equal(t, []security.EventType{
+		security.EventAuthFailure,
+		security.EventAuthFailure,
+		security.EventAuthFailure,
+		security.EventLockout,
+		security.EventLockout,
+		security.EventAuthSuccess,
+		security.EventAuthFailure,
+		security.EventAuthFailure,
+		security.EventAuthFailure,
+		security.EventLockout,
+		security.EventAuthSuccess,
+		security.EventAuthFailure,
+	}, f.eventTypes())
+
+	event := f.events[3]
+	assert.Equal(t, security.AuthMethodPassword, event.Method)
+	assert.Equal(t, "john", event.Login)
+	assert.Equal(t, "10.0.0.1", event.IP)
+	assert.Equal(t, time.Minute, event.RetryAfter)
+}
+
+func TestProvider_ipLockout(t *testing.T) {
+	f := newFixture(t)
+
+	logins := []string{"a", "b", "c", "d"}
+	for _, login := range logins {
+		assert.ErrorIs(t, f.login("10.0.0.1", login, "foo"), errs.ErrAuthFailure)
+	}
+
+	// Пятая неуспешная попытка с адреса блокирует адрес для всех логинов.
+	assert.ErrorIs(t, f.login("10.0.0.1", "e", "foo"), errs.ErrTooManyRequests)
+	assert.ErrorIs(t, f.login("10.0.0.1", "f", "secret"), errs.ErrTooManyRequests)
+	assert.NoError(t, f.login("10.0.0.2", "f", "secret"))
+
+	// Неуспешные попытки вне окна подсчета забываются.
+	f.now = f.now.Add(time.Hour)
+	for i := 0; i < 4; i++ {
+		assert.ErrorIs(t, f.login("10.0.0.1", "", "foo"), errs.ErrAuthFailure)
+
+		f.now = f.now.Add(5 * time.Minute)
+	}
+}
+
+func TestGuard_tokenRevoked(t *testing.T) {
+	var events []security.Event
+
+	eventBus := bus.NewSyncEventBus[security.Event]()
+	require.NoError(t, eventBus.Subscribe(context.Background(), security.DefaultEventsTopic,
+		bus.SubscriberFn[security.Event](func(_ context.Context, published ...security.Event) error {
+			events = append(events, published...)
+
+			return nil
+		})))
+
+	guard := lockout.NewGuard(newMemoryStore(t, time.Now), &lockout.Config{},
+		lockout.WithEvents(security.NewEventNotifier(eventBus, "")))
+
+	attempt := lockout.Attempt{Method: security.AuthMethodToken, IP: "10.0.0.1"}
+	require.NoError(t, guard.Failure(context.Background(), attempt,
+		fmt.Errorf("%w: %w", errs.ErrAuthFailure, security.ErrTokenRevoked)))
+
+	require.Len(t, events, 1)
+	assert.Equal(t, security.EventTokenRevoked, events[0].Type)
+	assert.Equal(t, "AUTH_FAILURE: token is revoked", events[0].Reason)
+}
+
+// concurrentStore выполняет hook перед первой условной записью, имитируя одновременную попытку.
+type concurrentStore struct {
+	*local.Store
+	hook func()
+}
+
+func (s *concurrentStore) runHook() {
+	if hook := s.hook; hook != nil {
+		s.hook = nil
+		hook()
+	}
+}
+
+func (s *concurrentStore) SetIfNotExists(ctx context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	s.runHook()
+
+	return s.Store.SetIfNotExists(ctx, key, value, expiration)
+}
+
+func (s *concurrentStore) CompareAndSwap(ctx context.Context, key string, old *memorystore.Value, value any, expiration time.Duration) (bool, error) {
+	s.runHook()
+
+	return s.Store.CompareAndSwap(ctx, key, old, value, expiration)
+}
+
+func TestGuard_Failure_concurrent(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	attempt := lockout.Attempt{Method: security.AuthMethodPassword, Login: "john"}
+
+	tests := []struct {
+		name     string
+		existing int // Неуспешных попыток до одновременных.
+	}{
+		{name: "Первая запись счетчика", existing: 0},
+		{name: "Изменение счетчика", existing: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &concurrentStore{Store: newMemoryStore(t, clock)}
+			guard := lockout.NewGuard(store, &lockout.Config{
+				MaxUserAttempts: tt.existing + 2,
+				Window:          time.Hour,
+				Duration:        time.Minute,
+				MaxDuration:     time.Hour,
+			}, lockout.WithClock(clock))
+
+			for i := 0; i < tt.existing; i++ {
+				require.NoError(t, guard.Failure(ctx, attempt, errs.ErrAuthFailure))
+			}
+
+			// Одновременная попытка записывает счетчик раньше: обе попытки должны быть учтены.
+			store.hook = func() {
+				require.NoError(t, guard.Failure(ctx, attempt, errs.ErrAuthFailure))
+			}
+
+			assert.ErrorIs(t, guard.Failure(ctx, attempt, errs.ErrAuthFailure), errs.ErrTooManyRequests)
+			assert.ErrorIs(t, guard.Check(ctx, attempt), errs.ErrTooManyRequests)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Package login предоставляет стратегию аутентификации по логину и паролю (security.Credentials).
+//
+// Пользователи берутся из подключаемого хранилища UserStore, пароли проверяются с помощью passwords.Encryptor. При
+// успешной аутентификации Provider.Login выпускает токены доступа и продления через jwt.RefreshService.Issue, поэтому
+// выпущенные токены продления участвуют в ротации (jwt.RefreshService.Refresh), обнаружении повторного использования и
+// выходе (jwt.RefreshService.Logout).
+//
+// Все неуспешные попытки (неизвестный логин, неверный пароль, заблокированный пользователь) возвращают одинаковую ошибку
+// и выполняют проверку хеша пароля, чтобы по ответу и времени ответа нельзя было определить существование пользователя.
+// Для защиты от перебора паролей подключите lockout.Guard опцией WithLockout.
+package login
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/jwt"
+	"github.com/wal1251/pkg/core/security/lockout"
+	"github.com/wal1251/pkg/tools/passwords"
+)
+
+const failureMessage = "invalid login or password"
+
+var (
+	ErrUserNotFound = errors.New("user not found") // Пользователь не найден в хранилище.
+
+	_ security.AuthenticationProvider[security.Credentials] = (*Provider)(nil)
+)
+
+type (
+	// UserRecord учетная запись пользователя в хранилище.
+	UserRecord struct {
+		User         security.User        // Пользователь.
+		PasswordHash string               // Хеш пароля, см. passwords.Encryptor.
+		Authorities  security.Authorities // Полномочия пользователя.
+		Disabled     bool                 // Учетная запись заблокирована.
+	}
+
+	// UserStore хранилище учетных записей пользователей.
+	UserStore interface {
+		// FindByLogin возвращает учетную запись по логину или ошибку ErrUserNotFound.
+		FindByLogin(ctx context.Context, login string) (UserRecord, error)
+	}
+
+	// UserStoreFn функциональное представление UserStore.
+	UserStoreFn func(ctx context.Context, login string) (UserRecord, error)
+
+	// Tokens выпущенные при входе токены.
+	Tokens = jwt.Tokens
+
+	// Provider провайдер аутентификации по логину и паролю, реализует security.AuthenticationProvider.
+	Provider struct {
+		store     UserStore
+		encryptor passwords.Encryptor
+		refresh   *jwt.RefreshService
+		dummyHash string
+		guard     *lockout.Guard
+		guarded   security.AuthenticationProvider[security.Credentials]
+	}
+
+	// Option опция Provider.
+	Option func(*Provider)
+)
+
+// FindByLogin см. UserStore.
+func (f UserStoreFn) FindByLogin(ctx context.Context, login string) (UserRecord, error) {
+	return f(ctx, login)
+}
+
+// Authenticate см. security.AuthenticationProvider. Если подключен lockout.Guard, неуспешные попытки учитываются, а
+// попытки заблокированного логина или адреса отклоняются ошибкой errs.ErrTooManyRequests.
+func (p *Provider) Authenticate(ctx context.Context, credentials security.Credentials) (security.Authentication, error) {
+	if p.guarded != nil {
+		return p.guarded.Authenticate(ctx, credentials)
+	}
+
+	return p.authenticate(ctx, credentials)
+}
+
+func (p *Provider) authenticate(ctx context.Context, credentials security.Credentials) (security.Authentication, error) {
+	record, err := p.store.FindByLogin(ctx, credentials.Login)
+	if err != nil {
+		if !errors.Is(err, ErrUserNotFound) {
+			return security.Authentication{}, fmt.Errorf("can't find user: %w", err)
+		}
+
+		// Сравниваем с фиктивным хешем, чтобы время ответа для неизвестного логина не отличалось.
+		p.encryptor.Verify(string(credentials.Password), p.dummyHash)
+		logs.FromContext(ctx).Debug().Msg("authentication failed: user not found")
+
+		return security.Authentication{}, errs.Wrapf(errs.ErrAuthFailure, failureMessage)
+	}
+
+	if !p.encryptor.Verify(string(credentials.Password), record.PasswordHash) || record.Disabled {
+		logs.FromContext(ctx).Debug().Msg("authentication failed: wrong password or user disabled")
+
+		return security.Authentication{}, errs.Wrapf(errs.ErrAuthFailure, failureMessage)
+	}
+
+	return security.Authentication{
+		User:        record.User,
+		Authorities: record.Authorities,
+	}, nil
+}
+
+// Login аутентифицирует пользователя и выпускает токены доступа и продления, открывая новое семейство токенов
+// продления, см. jwt.RefreshService.Issue.
+func (p *Provider) Login(ctx context.Context, credentials security.Credentials) (Tokens, error) {
+	auth, err := p.Authenticate(ctx, credentials)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	if p.refresh == nil {
+		return Tokens{}, errs.Wrapf(errs.ErrSystemFailure, "refresh service is not configured")
+	}
+
+	tokens, err := p.refresh.Issue(ctx, auth)
+	if err != nil {
+		return Tokens{}, fmt.Errorf("can't issue tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// WithLockout подключает защиту от перебора паролей, попытки учитываются по логину и IP адресу клиента.
+func WithLockout(guard *lockout.Guard) Option {
+	return func(p *Provider) {
+		p.guard = guard
+	}
+}
+
+// NewProvider возвращает новый Provider. Параметр refresh может быть nil, если нужна только аутентификация без выпуска
+// токенов.
+func NewProvider(store UserStore, encryptor passwords.Encryptor, refresh *jwt.RefreshService, opts ...Option) (*Provider, error) {
+	dummyHash, err := encryptor.Encrypt("dummy-password")
+	if err != nil {
+		return nil, fmt.Errorf("can't create login provider: %w", err)
+	}
+
+	provider := &Provider{
+		store:     store,
+		encryptor: encryptor,
+		refresh:   refresh,
+		dummyHash: dummyHash,
+	}
+
+	for _, opt := range opts {
+		opt(provider)
+	}
+
+	if provider.guard != nil {
+		provider.guarded = lockout.NewProvider(provider.guard,
+			security.AuthenticationProviderFn[security.Credentials](provider.authenticate),
+			security.AuthMethodPassword, lockout.CredentialsLogin)
+	}
+
+	return provider, nil
+}
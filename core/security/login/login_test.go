@@ -0,0 +1,132 @@
+package login_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/memorystore/local"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/jwt"
+	"github.com/wal1251/pkg/core/security/lockout"
+	"github.com/wal1251/pkg/core/security/login"
+	"github.com/wal1251/pkg/tools/passwords"
+)
+
+func TestProvider(t *testing.T) {
+	ctx := context.Background()
+	encryptor := passwords.NewBcryptEncryptor()
+
+	hash, err := encryptor.Encrypt("secret")
+	require.NoError(t, err)
+
+	john := security.User{ID: uuid.New(), Name: "john"}
+	errStore := errors.New("store is down")
+
+	store := login.UserStoreFn(func(_ context.Context, name string) (login.UserRecord, error) {
+		switch name {
+		case "john":
+			return login.UserRecord{User: john, PasswordHash: hash, Authorities: security.Authorities{"user"}}, nil
+		case "blocked":
+			return login.UserRecord{User: security.User{ID: uuid.New()}, PasswordHash: hash, Disabled: true}, nil
+		case "broken":
+			return login.UserRecord{}, errStore
+		default:
+			return login.UserRecord{}, login.ErrUserNotFound
+		}
+	})
+
+	manager, err := jwt.NewTokenManager(
+		&jwt.ManagerConfig{Secret: []byte("jwt-secret"), AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour},
+		func() jwt.RichToken { return &jwt.DefaultRichToken{Claims: &jwt.DefaultClaims{}} },
+	)
+	require.NoError(t, err)
+
+	memory, err := local.NewStore(&local.Config{})
+	require.NoError(t, err)
+
+	refresh := jwt.NewRefreshService(manager, memory, time.Hour)
+
+	provider, err := login.NewProvider(store, encryptor, refresh)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		credentials security.Credentials
+		wantErr     error
+	}{
+		{name: "Valid credentials", credentials: security.Credentials{Login: "john", Password: "secret"}},
+		{name: "Wrong password", credentials: security.Credentials{Login: "john", Password: "foo"}, wantErr: errs.ErrAuthFailure},
+		{name: "Unknown user", credentials: security.Credentials{Login: "jane", Password: "secret"}, wantErr: errs.ErrAuthFailure},
+		{name: "Disabled user", credentials: security.Credentials{Login: "blocked", Password: "secret"}, wantErr: errs.ErrAuthFailure},
+		{name: "Store failure", credentials: security.Credentials{Login: "broken", Password: "secret"}, wantErr: errStore},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := provider.Login(ctx, tt.credentials)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			access, err := manager.ParseToken(tokens.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, john.ID, access.GetSubjectID())
+			assert.Equal(t, []security.Authority{"user"}, access.GetAuthorities())
+
+			// Токен продления зарегистрирован в семействе и обменивается на новую пару.
+			refreshed, err := refresh.Refresh(ctx, tokens.RefreshToken)
+			require.NoError(t, err)
+			assert.NotEqual(t, tokens.RefreshToken, refreshed.RefreshToken)
+
+			_, err = refresh.Refresh(ctx, tokens.RefreshToken)
+			assert.ErrorIs(t, err, errs.ErrAuthFailure, "reused refresh token must be rejected")
+		})
+	}
+
+	_, errUnknown := provider.Authenticate(ctx, security.Credentials{Login: "jane", Password: "secret"})
+	_, errWrong := provider.Authenticate(ctx, security.Credentials{Login: "john", Password: "foo"})
+	assert.Equal(t, errUnknown.Error(), errWrong.Error(), "failures must not reveal whether user exists")
+}
+
+func TestProvider_lockout(t *testing.T) {
+	ctx := security.WithClientIP(context.Background(), "10.0.0.1")
+	encryptor := passwords.NewBcryptEncryptor()
+
+	hash, err := encryptor.Encrypt("secret")
+	require.NoError(t, err)
+
+	store := login.UserStoreFn(func(_ context.Context, name string) (login.UserRecord, error) {
+		return login.UserRecord{User: security.User{ID: uuid.New(), Name: name}, PasswordHash: hash}, nil
+	})
+
+	memory, err := local.NewStore(&local.Config{})
+	require.NoError(t, err)
+
+	guard := lockout.NewGuard(memory, &lockout.Config{MaxUserAttempts: 2})
+
+	provider, err := login.NewProvider(store, encryptor, nil, login.WithLockout(guard))
+	require.NoError(t, err)
+
+	_, err = provider.Authenticate(ctx, security.Credentials{Login: "john", Password: "foo"})
+	assert.ErrorIs(t, err, errs.ErrAuthFailure)
+
+	_, err = provider.Authenticate(ctx, security.Credentials{Login: "john", Password: "foo"})
+	assert.ErrorIs(t, err, errs.ErrTooManyRequests)
+
+	_, err = provider.Login(ctx, security.Credentials{Login: "john", Password: "secret"})
+	assert.ErrorIs(t, err, errs.ErrTooManyRequests)
+
+	_, err = provider.Authenticate(ctx, security.Credentials{Login: "jane", Password: "secret"})
+	assert.NoError(t, err)
+}
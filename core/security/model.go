@@ -2,6 +2,7 @@ package security
 
 import (
 	"context"
+	"crypto/x509"
 
 	"github.com/google/uuid"
 
@@ -15,6 +16,8 @@ const displayTokenMaxLength = 5
 var (
 	_ presenters.StringViewer = Password("")
 	_ presenters.StringViewer = BearerToken("")
+	_ presenters.StringViewer = APIKey("")
+	_ presenters.StringViewer = SessionID("")
 )
 
 type (
@@ -22,22 +25,31 @@ type (
 	Requirements struct {
 		IsAuthorizationRequired bool        // Нужна авторизация?
 		Authorities             Authorities // Требуемые полномочия.
+		Policy                  string      // Политика доступа, см. PolicyEngine.
+		Resource                any         // Ресурс, к которому запрашивается доступ, см. ResourceCheck.
 	}
 
 	BearerToken string // Токен на предъявителя.
 	Password    string // Пароль.
+	APIKey      string // Ключ API для межсервисного взаимодействия.
+	SessionID   string // Идентификатор серверной сессии (например, из cookie).
 
 	// Credentials учетные данные для аутентификации.
 	Credentials struct {
 		Login    string   // Логин (имя учетной записи).
-		Password Password // Пароль.
+		Password Password `log:"secret"` // Пароль.
+	}
+
+	// ClientCertificate проверенный сертификат клиента TLS (mTLS).
+	ClientCertificate struct {
+		Leaf *x509.Certificate // Сертификат клиента, nil если клиент не предъявил сертификат.
 	}
 
 	// User авторизованное лицо.
 	User struct { //
-		ID          uuid.UUID `json:"id"`           // Идентификатор записи.
-		Name        string    `json:"name"`         // Имя учетной записи.
-		PhoneNumber string    `json:"phone_number"` //nolint:tagliatelle // Номер телефона
+		ID          uuid.UUID `json:"id"`                      // Идентификатор записи.
+		Name        string    `json:"name"`                    // Имя учетной записи.
+		PhoneNumber string    `json:"phone_number" log:"mask"` //nolint:tagliatelle // Номер телефона
 	}
 
 	// Authority полномочие пользователя на выполнение операции или группы операций.
@@ -51,6 +63,7 @@ type (
 		User        User        // Авторизованное лицо.
 		TokenID     uuid.UUID   // Идентификатор токена доступа.
 		SessionID   string      // Идентификатор сессии.
+		TenantID    string      // Идентификатор арендатора (tenant) пользователя, пустой - без арендатора.
 		Authorities Authorities `json:"authorities,omitempty"` // Полномочия авторизованного пользователя.
 	}
 )
@@ -71,6 +84,22 @@ func (t BearerToken) StringView(view presenters.ViewType, _ presenters.ViewOptio
 	return string(t)
 }
 
+func (k APIKey) StringView(view presenters.ViewType, _ presenters.ViewOptions) string {
+	if view == presenters.ViewLogs {
+		return presenters.DefaultCredentialsPlaceholder
+	}
+
+	return string(k)
+}
+
+func (s SessionID) StringView(view presenters.ViewType, _ presenters.ViewOptions) string {
+	if view == presenters.ViewLogs {
+		return presenters.DefaultCredentialsPlaceholder
+	}
+
+	return string(s)
+}
+
 // Meets возвращает true, полномочия a удовлетворяют запрошенным полномочиям required.
 func (a Authorities) Meets(required Authorities) bool {
 	return len(required) == 0 || collections.NewSet[Authority](a...).ContainsAny(required...)
@@ -81,8 +110,11 @@ func (a Authorities) ToStrings() []string {
 	return collections.Map(a, func(t Authority) string { return string(t) })
 }
 
-// ToContext поместить полномочия в контекст.
+// ToContext поместить полномочия в контекст. Арендатор пользователя заменяет арендатора контекста, для пользователя без
+// арендатора арендатор контекста сбрасывается, см. WithTenant.
 func (a Authentication) ToContext(ctx context.Context) context.Context {
+	ctx = WithTenant(ctx, a.TenantID)
+
 	return ctxs.ValuePut(ctx, AuthorizedContextKey, a)
 }
 
@@ -0,0 +1,43 @@
+package mtls
+
+import (
+	"time"
+
+	"github.com/wal1251/pkg/core/cfg"
+	"github.com/wal1251/pkg/core/security"
+)
+
+const (
+	CfgKeyIdentities cfg.Key = "MTLS_IDENTITIES" // Полномочия клиентов вида "<идентичность>=<полномочие>|<полномочие>" ([]string).
+
+	CfgDefaultReloadInterval = time.Minute // Интервал проверки изменения файлов сертификатов по умолчанию.
+
+	ClientAuthNone     = "none"     // Сертификат клиента не запрашивается.
+	ClientAuthOptional = "optional" // Сертификат клиента проверяется, если предъявлен.
+	ClientAuthRequire  = "require"  // Сертификат клиента обязателен.
+)
+
+type (
+	// Config конфигурация TLS. Для сервера CertFile и KeyFile - сертификат сервера, CAFile - сертификаты центров,
+	// которыми подписаны сертификаты клиентов. Для клиента CertFile и KeyFile - сертификат клиента, CAFile - сертификаты
+	// центров, которыми подписан сертификат сервера.
+	Config struct {
+		CertFile       string        // Файл сертификата в формате PEM.
+		KeyFile        string        // Файл закрытого ключа в формате PEM.
+		CAFile         string        // Файл сертификатов доверенных центров в формате PEM.
+		ClientAuth     string        // Проверка сертификата клиента сервером: none, optional или require.
+		ReloadInterval time.Duration // Интервал проверки изменения файлов, 0 - проверка при каждом рукопожатии.
+	}
+
+	// ProviderConfig конфигурация провайдера аутентификации по сертификату клиента.
+	ProviderConfig struct {
+		// Identities полномочия клиентов по идентичности: SPIFFE ID или иной URI, DNS имя, адрес электронной почты из
+		// SAN или CN сертификата.
+		Identities map[string]security.Authorities
+	}
+)
+
+// Enabled возвращает true, если TLS настроен.
+func (c Config) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
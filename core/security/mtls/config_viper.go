@@ -0,0 +1,14 @@
+package mtls
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/wal1251/pkg/core/cfg"
+)
+
+// CfgFromViper загрузка конфига ProviderConfig с помощью viper.
+func CfgFromViper(loader *viper.Viper, keyMapping ...cfg.KeyMap) *ProviderConfig {
+	return &ProviderConfig{
+		Identities: ParseIdentities(loader.GetStringSlice(string(CfgKeyIdentities.Map(keyMapping...)))),
+	}
+}
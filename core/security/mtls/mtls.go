@@ -0,0 +1,117 @@
+// Package mtls предоставляет взаимную аутентификацию TLS (mTLS): конфигурации TLS сервера и клиента с перезагрузкой
+// сертификатов при изменении файлов (см. Reloader) и провайдер аутентификации по проверенному сертификату клиента.
+//
+// Provider сопоставляет идентичность сертификата клиента (SPIFFE ID, SAN или CN) с полномочиями из конфигурации и
+// реализует security.AuthenticationProvider[security.ClientCertificate]:
+//
+//	provider := mtls.NewProvider(mtls.CfgFromViper(loader))
+//	router.Use(mw.Authorizer(authManager, httpx.ClientCertificateExtract, provider))
+package mtls
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/security"
+)
+
+const SchemeSPIFFE = "spiffe" // Схема URI идентификатора SPIFFE.
+
+var _ security.AuthenticationProvider[security.ClientCertificate] = (*Provider)(nil)
+
+// Provider провайдер аутентификации по сертификату клиента, реализует security.AuthenticationProvider. Сертификат
+// должен быть проверен при рукопожатии TLS, см. Reloader.ServerTLSConfig.
+type Provider struct {
+	identities map[string]security.Authorities
+}
+
+// Authenticate см. security.AuthenticationProvider. Идентичности сертификата (см. Identities) проверяются по порядку,
+// используется первая, для которой заданы полномочия. Идентификатор пользователя - детерминированный UUID v5 от
+// идентичности.
+func (p *Provider) Authenticate(_ context.Context, certificate security.ClientCertificate) (security.Authentication, error) {
+	if certificate.Leaf == nil {
+		return security.Authentication{}, errs.Wrapf(errs.ErrAuthFailure, "client certificate is required")
+	}
+
+	for _, identity := range Identities(certificate.Leaf) {
+		authorities, ok := p.identities[identity]
+		if !ok {
+			continue
+		}
+
+		return security.Authentication{
+			User: security.User{
+				ID:   uuid.NewSHA1(uuid.NameSpaceURL, []byte(identity)),
+				Name: identity,
+			},
+			Authorities: authorities,
+		}, nil
+	}
+
+	return security.Authentication{}, errs.Wrapf(errs.ErrAuthFailure, "unknown client certificate %q",
+		certificate.Leaf.Subject.CommonName)
+}
+
+// Identities возвращает идентичности сертификата в порядке приоритета: SPIFFE ID, прочие URI, DNS имена и адреса
+// электронной почты из SAN, CN.
+func Identities(certificate *x509.Certificate) []string {
+	identities := make([]string, 0, len(certificate.URIs)+len(certificate.DNSNames)+len(certificate.EmailAddresses)+1)
+
+	if id := SPIFFEID(certificate); id != "" {
+		identities = append(identities, id)
+	}
+
+	for _, uri := range certificate.URIs {
+		if uri.Scheme != SchemeSPIFFE {
+			identities = append(identities, uri.String())
+		}
+	}
+
+	identities = append(identities, certificate.DNSNames...)
+	identities = append(identities, certificate.EmailAddresses...)
+
+	if certificate.Subject.CommonName != "" {
+		identities = append(identities, certificate.Subject.CommonName)
+	}
+
+	return identities
+}
+
+// SPIFFEID возвращает идентификатор SPIFFE сертификата (URI SAN со схемой spiffe) или пустую строку.
+func SPIFFEID(certificate *x509.Certificate) string {
+	for _, uri := range certificate.URIs {
+		if uri.Scheme == SchemeSPIFFE {
+			return uri.String()
+		}
+	}
+
+	return ""
+}
+
+// ParseIdentities возвращает полномочия клиентов из строк вида "<идентичность>=<полномочие>|<полномочие>".
+func ParseIdentities(values []string) map[string]security.Authorities {
+	identities := make(map[string]security.Authorities, len(values))
+
+	for _, value := range values {
+		identity, authorities, _ := strings.Cut(value, "=")
+		if identity = strings.TrimSpace(identity); identity == "" {
+			continue
+		}
+
+		identities[identity] = append(identities[identity],
+			security.AuthoritiesFromString(strings.FieldsFunc(authorities, func(r rune) bool { return r == '|' }))...)
+	}
+
+	return identities
+}
+
+// NewProvider возвращает новый Provider.
+func NewProvider(config *ProviderConfig) *Provider {
+	return &Provider{
+		identities: config.Identities,
+	}
+}
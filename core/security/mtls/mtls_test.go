@@ -0,0 +1,295 @@
+package mtls_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/mtls"
+)
+
+type issued struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func issue(t *testing.T, template *x509.Certificate, parent *issued) *issued {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Minute)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &issued{cert: cert, key: key}
+}
+
+func newCA(t *testing.T, name string) *issued {
+	t.Helper()
+
+	return issue(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+}
+
+func newLeaf(t *testing.T, ca *issued, template *x509.Certificate) *issued {
+	t.Helper()
+
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+
+	return issue(t, template, ca)
+}
+
+// write сохраняет сертификат и ключ в файлы PEM, возвращает пути к ним.
+func (c *issued) write(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+
+	certFile := filepath.Join(dir, name+".crt")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600))
+
+	der, err := x509.MarshalPKCS8PrivateKey(c.key)
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	return certFile, keyFile
+}
+
+func spiffe(t *testing.T, id string) []*url.URL {
+	t.Helper()
+
+	uri, err := url.Parse(id)
+	require.NoError(t, err)
+
+	return []*url.URL{uri}
+}
+
+func TestProvider_Authenticate(t *testing.T) {
+	ca := newCA(t, "ca")
+	provider := mtls.NewProvider(&mtls.ProviderConfig{Identities: mtls.ParseIdentities([]string{
+		"spiffe://cluster.local/ns/billing/sa/api=billing|payments",
+		"orders.internal=orders",
+		"legacy=reader",
+	})})
+
+	tests := []struct {
+		name     string
+		cert     *x509.Certificate
+		identity string
+		want     security.Authorities
+		wantErr  bool
+	}{
+		{
+			name: "SPIFFE ID",
+			cert: newLeaf(t, ca, &x509.Certificate{
+				Subject: pkix.Name{CommonName: "legacy"},
+				URIs:    spiffe(t, "spiffe://cluster.local/ns/billing/sa/api"),
+			}).cert,
+			identity: "spiffe://cluster.local/ns/billing/sa/api",
+			want:     security.Authorities{"billing", "payments"},
+		},
+		{
+			name:     "DNS SAN",
+			cert:     newLeaf(t, ca, &x509.Certificate{DNSNames: []string{"orders.internal"}}).cert,
+			identity: "orders.internal",
+			want:     security.Authorities{"orders"},
+		},
+		{
+			name:     "Common name",
+			cert:     newLeaf(t, ca, &x509.Certificate{Subject: pkix.Name{CommonName: "legacy"}}).cert,
+			identity: "legacy",
+			want:     security.Authorities{"reader"},
+		},
+		{
+			name:    "Unknown identity",
+			cert:    newLeaf(t, ca, &x509.Certificate{Subject: pkix.Name{CommonName: "stranger"}}).cert,
+			wantErr: true,
+		},
+		{
+			name:    "No certificate",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, err := provider.Authenticate(context.Background(), security.ClientCertificate{Leaf: tt.cert})
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errs.ErrAuthFailure)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.identity, auth.User.Name)
+			assert.Equal(t, uuid.NewSHA1(uuid.NameSpaceURL, []byte(tt.identity)), auth.User.ID)
+			assert.Equal(t, tt.want, auth.Authorities)
+		})
+	}
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+
+	ca := newCA(t, "ca")
+	serverCAFile, _ := ca.write(t, dir, "server-ca")
+	caFile, _ := ca.write(t, dir, "client-ca")
+	serverCert, serverKey := newLeaf(t, ca, &x509.Certificate{
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+	}).write(t, dir, "server")
+
+	reloader, err := mtls.NewReloader(mtls.Config{
+		CertFile: serverCert,
+		KeyFile:  serverKey,
+		CAFile:   caFile,
+	})
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.VerifiedChains[0][0].URIs[0].String()))
+	}))
+	server.TLS = reloader.ServerTLSConfig()
+	server.StartTLS()
+	defer server.Close()
+
+	request := func(certFile, keyFile, caFile string) (string, error) {
+		return requestTLS(t, server.URL, mtls.Config{CertFile: certFile, KeyFile: keyFile, CAFile: caFile}, "localhost")
+	}
+
+	clientCert, clientKey := newLeaf(t, ca, &x509.Certificate{URIs: spiffe(t, "spiffe://test/client")}).write(t, dir, "client")
+
+	got, err := request(clientCert, clientKey, serverCAFile)
+	require.NoError(t, err)
+	assert.Equal(t, "spiffe://test/client", got)
+
+	// Имя сервера проверяется по DNS и IP SAN, без явного имени берется доменное имя из адреса подключения.
+	clientConfig := mtls.Config{CertFile: clientCert, KeyFile: clientKey, CAFile: serverCAFile}
+
+	_, err = requestTLS(t, server.URL, clientConfig, "orders.internal")
+	assert.Error(t, err, "server name must match certificate")
+
+	_, err = requestTLS(t, server.URL, clientConfig, "127.0.0.1")
+	require.NoError(t, err)
+
+	_, err = requestTLS(t, strings.Replace(server.URL, "127.0.0.1", "localhost", 1), clientConfig, "")
+	require.NoError(t, err)
+
+	_, err = requestTLS(t, server.URL, clientConfig, "")
+	assert.ErrorIs(t, err, mtls.ErrInvalidConfig, "server name is required for ip address")
+
+	// Без сертификата клиента соединение отклоняется.
+	_, err = request("", "", serverCAFile)
+	assert.Error(t, err)
+
+	// Сервер недоверенный для клиента.
+	otherCAFile, _ := newCA(t, "other").write(t, dir, "other-ca")
+	_, err = request(clientCert, clientKey, otherCAFile)
+	assert.Error(t, err)
+
+	// Смена файла центра сертификации клиентов применяется к новым соединениям без перезапуска сервера.
+	rotated := newCA(t, "rotated")
+	rotatedCAFile, _ := rotated.write(t, dir, "rotated-ca")
+	rotatedCert, rotatedKey := newLeaf(t, rotated, &x509.Certificate{URIs: spiffe(t, "spiffe://test/rotated")}).
+		write(t, dir, "rotated-client")
+
+	raw, err := os.ReadFile(rotatedCAFile)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(caFile, raw, 0o600))
+
+	_, err = request(clientCert, clientKey, serverCAFile)
+	assert.Error(t, err, "client certificate of previous ca must be rejected")
+
+	got, err = request(rotatedCert, rotatedKey, serverCAFile)
+	require.NoError(t, err)
+	assert.Equal(t, "spiffe://test/rotated", got)
+}
+
+func TestParseClientAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		caFile  string
+		want    tls.ClientAuthType
+		wantErr bool
+	}{
+		{name: "", want: tls.NoClientCert},
+		{name: "", caFile: "ca.pem", want: tls.RequireAndVerifyClientCert},
+		{name: "optional", caFile: "ca.pem", want: tls.VerifyClientCertIfGiven},
+		{name: "require", wantErr: true},
+		{name: "always", caFile: "ca.pem", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.caFile, func(t *testing.T) {
+			got, err := mtls.ParseClientAuth(tt.name, tt.caFile)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, mtls.ErrInvalidConfig)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func requestTLS(t *testing.T, url string, config mtls.Config, serverName string) (string, error) {
+	t.Helper()
+
+	tlsConfig, err := mtls.ClientTLSConfig(config, serverName)
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	defer client.CloseIdleConnections()
+
+	response, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	var body [128]byte
+	n, _ := response.Body.Read(body[:])
+
+	return string(body[:n]), nil
+}
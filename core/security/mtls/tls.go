@@ -0,0 +1,297 @@
+package mtls
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wal1251/pkg/core/logs"
+)
+
+var ErrInvalidConfig = errors.New("invalid tls config") // Некорректная конфигурация TLS.
+
+type (
+	// Reloader загружает сертификат, закрытый ключ и сертификаты доверенных центров из файлов и перечитывает их при
+	// изменении. Файлы проверяются при рукопожатии TLS, но не чаще Config.ReloadInterval, поэтому обновленные
+	// сертификаты (например, выпущенные cert-manager) применяются к новым соединениям без перезапуска. Если новые файлы
+	// не удалось загрузить, продолжают использоваться прежние сертификаты.
+	Reloader struct {
+		config      Config
+		clientAuth  tls.ClientAuthType
+		lock        sync.Mutex
+		checkedAt   time.Time
+		digest      [sha256.Size]byte
+		certificate *tls.Certificate
+		pool        *x509.CertPool
+		now         func() time.Time
+	}
+
+	// Option опция Reloader.
+	Option func(*Reloader)
+)
+
+// Reload перечитывает файлы, если они изменились.
+func (r *Reloader) Reload() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.checkedAt = r.now()
+
+	return r.reload()
+}
+
+// ServerTLSConfig возвращает конфигурацию TLS сервера: сертификат сервера и проверка сертификатов клиентов по
+// Config.ClientAuth берутся из актуальных файлов.
+func (r *Reloader) ServerTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			certificate, _ := r.current(hello.Context())
+
+			return certificate, nil
+		},
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			certificate, pool := r.current(hello.Context())
+			if certificate == nil {
+				return nil, fmt.Errorf("%w: server certificate is not configured", ErrInvalidConfig)
+			}
+
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*certificate},
+				ClientCAs:    pool,
+				ClientAuth:   r.clientAuth,
+				NextProtos:   []string{"h2", "http/1.1"},
+			}, nil
+		},
+	}
+}
+
+// ClientTLSConfig возвращает конфигурацию TLS клиента: сертификат клиента (если указан) и проверка сертификата сервера
+// serverName по актуальным сертификатам доверенных центров (по умолчанию - системным). Имя сервера serverName может
+// быть доменным именем или IP-адресом, которые сверяются с DNS и IP SAN сертификата соответственно. Если serverName
+// пуст, используется доменное имя из адреса подключения (http.Transport и tls.Dial передают его в SNI). IP-адрес в SNI
+// не передается, поэтому при подключении по IP-адресу serverName необходимо указать, иначе соединение отклоняется.
+func (r *Reloader) ClientTLSConfig(serverName string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		GetClientCertificate: func(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			certificate, _ := r.current(info.Context())
+			if certificate == nil {
+				return &tls.Certificate{}, nil
+			}
+
+			return certificate, nil
+		},
+		// Сертификат сервера проверяется в VerifyConnection по актуальному набору доверенных центров.
+		InsecureSkipVerify: true, //nolint:gosec
+		VerifyConnection: func(state tls.ConnectionState) error {
+			_, pool := r.current(context.Background())
+
+			name := serverName
+			if name == "" {
+				name = state.ServerName
+			}
+
+			return verifyServer(state, pool, name)
+		},
+	}
+}
+
+func (r *Reloader) current(ctx context.Context) (*tls.Certificate, *x509.CertPool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if now := r.now(); now.Sub(r.checkedAt) >= r.config.ReloadInterval {
+		r.checkedAt = now
+
+		if err := r.reload(); err != nil {
+			logs.FromContext(ctx).Warn().Err(err).Msg("failed to reload tls certificates, previous ones are used")
+		}
+	}
+
+	return r.certificate, r.pool
+}
+
+func (r *Reloader) reload() error {
+	certPEM, err := readFile(r.config.CertFile)
+	if err != nil {
+		return err
+	}
+
+	keyPEM, err := readFile(r.config.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	caPEM, err := readFile(r.config.CAFile)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(bytes.Join([][]byte{certPEM, keyPEM, caPEM}, []byte{0}))
+	if digest == r.digest {
+		return nil
+	}
+
+	var certificate *tls.Certificate
+
+	if len(certPEM) > 0 || len(keyPEM) > 0 {
+		pair, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("%w: can't load key pair: %w", ErrInvalidConfig, err)
+		}
+
+		certificate = &pair
+	}
+
+	var pool *x509.CertPool
+
+	if len(caPEM) > 0 {
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return fmt.Errorf("%w: no certificates found in %s", ErrInvalidConfig, r.config.CAFile)
+		}
+	}
+
+	r.certificate, r.pool, r.digest = certificate, pool, digest
+
+	return nil
+}
+
+// ParseClientAuth возвращает режим проверки сертификата клиента по имени: none, optional или require. Если имя не
+// указано, сертификат обязателен при указанном файле доверенных центров caFile.
+func ParseClientAuth(name, caFile string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(name) {
+	case "":
+		if caFile != "" {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+
+		return tls.NoClientCert, nil
+	case ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthOptional:
+		if caFile == "" {
+			return 0, fmt.Errorf("%w: client ca file is required for client auth %q", ErrInvalidConfig, name)
+		}
+
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		if caFile == "" {
+			return 0, fmt.Errorf("%w: client ca file is required for client auth %q", ErrInvalidConfig, name)
+		}
+
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return 0, fmt.Errorf("%w: unknown client auth %q", ErrInvalidConfig, name)
+	}
+}
+
+// WithClock устанавливает источник текущего времени.
+func WithClock(now func() time.Time) Option {
+	return func(r *Reloader) {
+		r.now = now
+	}
+}
+
+// NewReloader загружает файлы и возвращает новый Reloader.
+func NewReloader(config Config, opts ...Option) (*Reloader, error) {
+	if (config.CertFile == "") != (config.KeyFile == "") {
+		return nil, fmt.Errorf("%w: both cert and key files must be set", ErrInvalidConfig)
+	}
+
+	clientAuth, err := ParseClientAuth(config.ClientAuth, config.CAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	reloader := &Reloader{
+		config:     config,
+		clientAuth: clientAuth,
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(reloader)
+	}
+
+	if err = reloader.Reload(); err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+// ServerTLSConfig возвращает конфигурацию TLS сервера с перезагрузкой сертификатов, см. Reloader.
+func ServerTLSConfig(config Config) (*tls.Config, error) {
+	if !config.Enabled() {
+		return nil, fmt.Errorf("%w: server cert and key files must be set", ErrInvalidConfig)
+	}
+
+	reloader, err := NewReloader(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return reloader.ServerTLSConfig(), nil
+}
+
+// ClientTLSConfig возвращает конфигурацию TLS клиента с перезагрузкой сертификатов, см. Reloader.
+func ClientTLSConfig(config Config, serverName string) (*tls.Config, error) {
+	reloader, err := NewReloader(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return reloader.ClientTLSConfig(serverName), nil
+}
+
+func verifyServer(state tls.ConnectionState, roots *x509.CertPool, serverName string) error {
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("%w: server certificate is missing", ErrInvalidConfig)
+	}
+
+	// Без имени x509.Certificate.Verify не сверяет сертификат с адресом сервера.
+	if serverName == "" {
+		return fmt.Errorf("%w: server name is required to verify server certificate", ErrInvalidConfig)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, certificate := range state.PeerCertificates[1:] {
+		intermediates.AddCert(certificate)
+	}
+
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		DNSName:       serverName, // Проверяет и IP SAN, если serverName - IP-адрес.
+	})
+	if err != nil {
+		return fmt.Errorf("can't verify server certificate: %w", err)
+	}
+
+	return nil
+}
+
+func readFile(name string) ([]byte, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	return data, nil
+}
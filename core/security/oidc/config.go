@@ -0,0 +1,50 @@
+package oidc
+
+import (
+	"time"
+
+	"github.com/wal1251/pkg/core/cfg"
+)
+
+const (
+	CfgKeyIssuerURL       cfg.Key = "OIDC_ISSUER_URL"    // Адрес издателя токенов (string).
+	CfgKeyAudience        cfg.Key = "OIDC_AUDIENCE"      // Ожидаемая аудитория токенов (string).
+	CfgKeySkipAudience    cfg.Key = "OIDC_SKIP_AUDIENCE" // Не проверять аудиторию токенов (bool).
+	CfgKeyAlgorithms      cfg.Key = "OIDC_ALGORITHMS"    // Допустимые алгоритмы подписи ([]string).
+	CfgKeyClaimSubject    cfg.Key = "OIDC_CLAIM_SUBJECT" // Клейм идентификатора пользователя (string).
+	CfgKeyClaimName       cfg.Key = "OIDC_CLAIM_NAME"    // Клейм имени пользователя (string).
+	CfgKeyClaimPhone      cfg.Key = "OIDC_CLAIM_PHONE"   // Клейм телефона пользователя (string).
+	CfgKeyClaimRoles      cfg.Key = "OIDC_CLAIM_ROLES"   // Клейм ролей пользователя, допускается путь через точку (string).
+	CfgKeyClaimScopes     cfg.Key = "OIDC_CLAIM_SCOPES"  // Клейм областей доступа (string).
+	CfgKeyClaimTenant     cfg.Key = "OIDC_CLAIM_TENANT"  // Клейм арендатора пользователя, допускается путь через точку (string).
+	CfgKeyScopePrefix     cfg.Key = "OIDC_SCOPE_PREFIX"  // Префикс полномочий из областей доступа (string).
+	CfgKeyLeeway          cfg.Key = "OIDC_LEEWAY"        // Допустимое расхождение часов (duration).
+	CfgKeyRefreshInterval cfg.Key = "OIDC_JWKS_REFRESH"  // Интервал обновления JWKS (duration).
+
+	CfgDefaultClaimSubject    = "sub"                // Клейм идентификатора пользователя по умолчанию.
+	CfgDefaultClaimName       = "preferred_username" // Клейм имени пользователя по умолчанию.
+	CfgDefaultClaimPhone      = "phone_number"       // Клейм телефона пользователя по умолчанию.
+	CfgDefaultClaimScopes     = "scope"              // Клейм областей доступа по умолчанию.
+	CfgDefaultLeeway          = 30 * time.Second     // Допустимое расхождение часов по умолчанию.
+	CfgDefaultRefreshInterval = time.Hour            // Интервал обновления JWKS по умолчанию.
+)
+
+// CfgDefaultAlgorithms допустимые алгоритмы подписи по умолчанию.
+var CfgDefaultAlgorithms = []string{"RS256"}
+
+// Config конфигурация проверки токенов доступа внешнего провайдера OpenID Connect.
+type Config struct {
+	IssuerURL         string        // Адрес издателя, по нему загружается документ /.well-known/openid-configuration.
+	Audience          string        // Ожидаемая аудитория токенов, обязательна, если не задан SkipAudienceCheck.
+	SkipAudienceCheck bool          // Не проверять аудиторию: принимать токены издателя, выпущенные для любого клиента.
+	Algorithms        []string      // Допустимые алгоритмы подписи, ограничиваются поддерживаемыми издателем.
+	ClaimSubject      string        // Клейм идентификатора пользователя.
+	ClaimName         string        // Клейм имени пользователя.
+	ClaimPhone        string        // Клейм телефона пользователя.
+	ClaimRoles        string        // Клейм ролей (например, realm_access.roles), пустое значение - не использовать.
+	ClaimScopes       string        // Клейм областей доступа, пустое значение - не использовать.
+	ClaimTenant       string        // Клейм арендатора (например, tenant_id), пустое значение - не использовать.
+	ScopePrefix       string        // Префикс полномочий из областей доступа, например "scope:".
+	Leeway            time.Duration // Допустимое расхождение часов.
+	RefreshInterval   time.Duration // Интервал обновления JWKS.
+}
@@ -0,0 +1,36 @@
+package oidc
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/wal1251/pkg/core/cfg"
+	"github.com/wal1251/pkg/core/cfg/viperx"
+)
+
+// CfgFromViper загрузка конфига Config с помощью viper.
+func CfgFromViper(loader *viper.Viper, keyMapping ...cfg.KeyMap) *Config {
+	return &Config{
+		IssuerURL:         viperx.Get(loader, CfgKeyIssuerURL.Map(keyMapping...), ""),
+		Audience:          viperx.Get(loader, CfgKeyAudience.Map(keyMapping...), ""),
+		SkipAudienceCheck: viperx.Get(loader, CfgKeySkipAudience.Map(keyMapping...), false),
+		Algorithms:        getAlgorithms(loader, keyMapping...),
+		ClaimSubject:      viperx.Get(loader, CfgKeyClaimSubject.Map(keyMapping...), CfgDefaultClaimSubject),
+		ClaimName:         viperx.Get(loader, CfgKeyClaimName.Map(keyMapping...), CfgDefaultClaimName),
+		ClaimPhone:        viperx.Get(loader, CfgKeyClaimPhone.Map(keyMapping...), CfgDefaultClaimPhone),
+		ClaimRoles:        viperx.Get(loader, CfgKeyClaimRoles.Map(keyMapping...), ""),
+		ClaimScopes:       viperx.Get(loader, CfgKeyClaimScopes.Map(keyMapping...), CfgDefaultClaimScopes),
+		ClaimTenant:       viperx.Get(loader, CfgKeyClaimTenant.Map(keyMapping...), ""),
+		ScopePrefix:       viperx.Get(loader, CfgKeyScopePrefix.Map(keyMapping...), ""),
+		Leeway:            viperx.Get(loader, CfgKeyLeeway.Map(keyMapping...), CfgDefaultLeeway),
+		RefreshInterval:   viperx.Get(loader, CfgKeyRefreshInterval.Map(keyMapping...), CfgDefaultRefreshInterval),
+	}
+}
+
+func getAlgorithms(loader *viper.Viper, keyMapping ...cfg.KeyMap) []string {
+	algorithms := loader.GetStringSlice(string(CfgKeyAlgorithms.Map(keyMapping...)))
+	if len(algorithms) == 0 {
+		algorithms = CfgDefaultAlgorithms
+	}
+
+	return algorithms
+}
@@ -0,0 +1,301 @@
+// Package oidc предоставляет проверку токенов доступа, выпущенных внешним провайдером OpenID Connect (сервер ресурсов).
+//
+// Provider загружает документ обнаружения издателя и его JWKS, проверяет подпись, издателя, аудиторию, срок действия
+// и алгоритм токена, а затем сопоставляет клеймы токена с security.Authentication. Provider реализует
+// security.AuthenticationProvider[security.BearerToken] и подключается к mw.Authorizer:
+//
+//	provider, err := oidc.NewProvider(ctx, oidc.CfgFromViper(loader))
+//	router.Use(mw.Authorizer(authManager, httpx.BearerTokenExtract, provider))
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/jwt"
+)
+
+const (
+	DiscoveryPath = "/.well-known/openid-configuration" // Путь документа обнаружения относительно адреса издателя.
+
+	claimTokenID   = "jti"
+	claimSessionID = "sid"
+	maxBodySize    = 1 << 20
+)
+
+var (
+	ErrDiscovery     = errors.New("oidc discovery failed") // Не удалось загрузить документ обнаружения.
+	ErrInvalidConfig = errors.New("invalid oidc config")   // Некорректная конфигурация.
+
+	_ security.AuthenticationProvider[security.BearerToken] = (*Provider)(nil)
+)
+
+type (
+	// Discovery документ обнаружения провайдера OpenID Connect (используемые поля).
+	Discovery struct {
+		Issuer            string   `json:"issuer"`
+		JWKSURI           string   `json:"jwks_uri"`                              //nolint:tagliatelle
+		SigningAlgorithms []string `json:"id_token_signing_alg_values_supported"` //nolint:tagliatelle
+	}
+
+	// Provider провайдер аутентификации по токенам доступа внешнего провайдера OpenID Connect.
+	Provider struct {
+		config    Config
+		discovery Discovery
+		keys      *jwt.RemoteKeySet
+		parser    *gojwt.Parser
+	}
+
+	// Option опция Provider.
+	Option func(*options)
+
+	options struct {
+		client *http.Client
+	}
+)
+
+// Authenticate см. security.AuthenticationProvider. Идентификатор пользователя берется из клейма ClaimSubject: если он
+// не является UUID, используется детерминированный UUID v5 от издателя и идентификатора.
+func (p *Provider) Authenticate(_ context.Context, token security.BearerToken) (security.Authentication, error) {
+	claims, err := p.Claims(token)
+	if err != nil {
+		return security.Authentication{}, err
+	}
+
+	subject, _ := lookup(claims, p.config.ClaimSubject).(string)
+	if subject == "" {
+		return security.Authentication{}, errs.Wrapf(errs.ErrAuthFailure, "token has no subject")
+	}
+
+	name, _ := lookup(claims, p.config.ClaimName).(string)
+	phone, _ := lookup(claims, p.config.ClaimPhone).(string)
+	tokenID, _ := claims[claimTokenID].(string)
+	sessionID, _ := claims[claimSessionID].(string)
+
+	auth := security.Authentication{
+		User: security.User{
+			ID:          p.toUUID(subject),
+			Name:        name,
+			PhoneNumber: phone,
+		},
+		SessionID: sessionID,
+	}
+
+	if tokenID != "" {
+		auth.TokenID = p.toUUID(tokenID)
+	}
+
+	if p.config.ClaimTenant != "" {
+		auth.TenantID, _ = lookup(claims, p.config.ClaimTenant).(string)
+	}
+
+	if p.config.ClaimRoles != "" {
+		auth.Authorities = append(auth.Authorities, security.AuthoritiesFromString(values(lookup(claims, p.config.ClaimRoles)))...)
+	}
+
+	if p.config.ClaimScopes != "" {
+		for _, scope := range values(lookup(claims, p.config.ClaimScopes)) {
+			auth.Authorities = append(auth.Authorities, security.Authority(p.config.ScopePrefix+scope))
+		}
+	}
+
+	return auth, nil
+}
+
+// Claims проверяет токен и возвращает его клеймы. Вернет ошибку errs.ErrAuthFailure, если токен недействителен.
+func (p *Provider) Claims(token security.BearerToken) (gojwt.MapClaims, error) {
+	claims := gojwt.MapClaims{}
+
+	if _, err := p.parser.ParseWithClaims(string(token), claims, p.keys.Keyfunc); err != nil {
+		return nil, errs.With(errs.ErrAuthFailure, err)
+	}
+
+	return claims, nil
+}
+
+// Discovery возвращает документ обнаружения провайдера.
+func (p *Provider) Discovery() Discovery {
+	return p.discovery
+}
+
+func (p *Provider) toUUID(value string) uuid.UUID {
+	if id, err := uuid.Parse(value); err == nil {
+		return id
+	}
+
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(p.discovery.Issuer+"#"+value))
+}
+
+// Discover загружает документ обнаружения издателя issuerURL и проверяет, что он выпущен этим издателем.
+func Discover(ctx context.Context, client *http.Client, issuerURL string) (Discovery, error) {
+	url := strings.TrimSuffix(issuerURL, "/") + DiscoveryPath
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Discovery{}, fmt.Errorf("%w: %w", ErrDiscovery, err)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return Discovery{}, fmt.Errorf("%w: %w", ErrDiscovery, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return Discovery{}, fmt.Errorf("%w: %s responded with status %d", ErrDiscovery, url, response.StatusCode)
+	}
+
+	var discovery Discovery
+	if err = json.NewDecoder(io.LimitReader(response.Body, maxBodySize)).Decode(&discovery); err != nil {
+		return Discovery{}, fmt.Errorf("%w: %w", ErrDiscovery, err)
+	}
+
+	if discovery.Issuer != issuerURL {
+		return Discovery{}, fmt.Errorf("%w: issuer mismatch: expected %s, got %s", ErrDiscovery, issuerURL, discovery.Issuer)
+	}
+
+	if discovery.JWKSURI == "" {
+		return Discovery{}, fmt.Errorf("%w: jwks_uri is missing", ErrDiscovery)
+	}
+
+	return discovery, nil
+}
+
+// WithHTTPClient устанавливает HTTP клиент загрузки документа обнаружения и JWKS.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// NewProvider загружает документ обнаружения издателя и возвращает новый Provider. Вернет ошибку ErrInvalidConfig, если
+// не задана аудитория токенов и проверка аудитории не отключена явно (Config.SkipAudienceCheck): иначе принимались бы
+// токены, выпущенные тем же издателем для любого другого клиента. Допустимые алгоритмы подписи (Config.Algorithms)
+// ограничиваются алгоритмами, которые издатель указал в документе обнаружения (Discovery.SigningAlgorithms); если
+// таких нет, также возвращается ошибка ErrInvalidConfig.
+func NewProvider(ctx context.Context, config *Config, opts ...Option) (*Provider, error) {
+	if config.Audience == "" && !config.SkipAudienceCheck {
+		return nil, fmt.Errorf("%w: audience is required, set SkipAudienceCheck to accept tokens for any audience",
+			ErrInvalidConfig)
+	}
+
+	o := options{client: http.DefaultClient}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	discovery, err := Discover(ctx, o.client, config.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := *config
+	if cfg.ClaimSubject == "" {
+		cfg.ClaimSubject = CfgDefaultClaimSubject
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = CfgDefaultRefreshInterval
+	}
+	if len(cfg.Algorithms) == 0 {
+		cfg.Algorithms = CfgDefaultAlgorithms
+	}
+
+	if cfg.Algorithms, err = supportedAlgorithms(cfg.Algorithms, discovery.SigningAlgorithms); err != nil {
+		return nil, err
+	}
+
+	parserOptions := []gojwt.ParserOption{
+		gojwt.WithValidMethods(cfg.Algorithms),
+		gojwt.WithIssuer(discovery.Issuer),
+		gojwt.WithExpirationRequired(),
+		gojwt.WithLeeway(cfg.Leeway),
+	}
+
+	if !cfg.SkipAudienceCheck {
+		parserOptions = append(parserOptions, gojwt.WithAudience(cfg.Audience))
+	}
+
+	return &Provider{
+		config:    cfg,
+		discovery: discovery,
+		keys: jwt.NewRemoteKeySet(discovery.JWKSURI,
+			jwt.WithHTTPClient(o.client),
+			jwt.WithRefreshInterval(cfg.RefreshInterval, jwt.DefaultJWKSMinRefreshInterval),
+		),
+		parser: gojwt.NewParser(parserOptions...),
+	}, nil
+}
+
+// supportedAlgorithms возвращает допустимые алгоритмы, которые поддерживает издатель. Если издатель не указал
+// поддерживаемые алгоритмы, допустимые алгоритмы не ограничиваются.
+func supportedAlgorithms(accepted, supported []string) ([]string, error) {
+	if len(supported) == 0 {
+		return accepted, nil
+	}
+
+	result := make([]string, 0, len(accepted))
+
+	for _, algorithm := range accepted {
+		if slices.Contains(supported, algorithm) {
+			result = append(result, algorithm)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%w: issuer supports none of algorithms %v, supported: %v", ErrInvalidConfig, accepted,
+			supported)
+	}
+
+	return result, nil
+}
+
+// lookup возвращает значение клейма по пути через точку, например "realm_access.roles".
+func lookup(claims map[string]any, path string) any {
+	if path == "" {
+		return nil
+	}
+
+	var value any = claims
+
+	for _, part := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		value = object[part]
+	}
+
+	return value
+}
+
+// values возвращает значения клейма-массива или строки, разделенной пробелами (как клейм scope).
+func values(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		result := make([]string, 0, len(v))
+
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+
+		return result
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,254 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/jwt"
+	"github.com/wal1251/pkg/core/security/oidc"
+)
+
+// testIdP локальный провайдер OpenID Connect: публикует документ обнаружения и JWKS, подписывает токены.
+type testIdP struct {
+	*httptest.Server
+	key        jwt.Key
+	algorithms []string // Поддерживаемые алгоритмы в документе обнаружения.
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	key, err := jwt.NewSigningKey("idp-key", gojwt.SigningMethodRS256, privateKey)
+	require.NoError(t, err)
+
+	keys, err := jwt.NewKeySet(key)
+	require.NoError(t, err)
+
+	idp := &testIdP{key: key}
+
+	mux := http.NewServeMux()
+	mux.Handle("/jwks", jwt.JWKSHandler(keys, time.Minute))
+	mux.HandleFunc(oidc.DiscoveryPath, func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(oidc.Discovery{
+			Issuer:            idp.URL,
+			JWKSURI:           idp.URL + "/jwks",
+			SigningAlgorithms: idp.algorithms,
+		})
+	})
+
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+
+	return idp
+}
+
+func (idp *testIdP) token(t *testing.T, claims gojwt.MapClaims, modify ...func(*gojwt.Token) any) security.BearerToken {
+	t.Helper()
+
+	base := gojwt.MapClaims{
+		"iss": idp.URL,
+		"aud": "orders",
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+	}
+	for k, v := range claims {
+		if v == nil {
+			delete(base, k)
+		} else {
+			base[k] = v
+		}
+	}
+
+	token := gojwt.NewWithClaims(gojwt.SigningMethodRS256, base)
+	token.Header[jwt.HeaderKeyID] = idp.key.ID
+
+	var secret any = idp.key.PrivateKey
+	for _, m := range modify {
+		secret = m(token)
+	}
+
+	signed, err := token.SignedString(secret)
+	require.NoError(t, err)
+
+	return security.BearerToken(signed)
+}
+
+func TestProvider_Authenticate(t *testing.T) {
+	idp := newTestIdP(t)
+
+	provider, err := oidc.NewProvider(context.Background(), &oidc.Config{
+		IssuerURL:   idp.URL,
+		Audience:    "orders",
+		ClaimName:   oidc.CfgDefaultClaimName,
+		ClaimRoles:  "realm_access.roles",
+		ClaimScopes: oidc.CfgDefaultClaimScopes,
+		ClaimTenant: "tenant_id",
+		ScopePrefix: "scope:",
+	})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		token   security.BearerToken
+		want    security.Authentication
+		wantErr bool
+	}{
+		{
+			name: "Valid token",
+			token: idp.token(t, gojwt.MapClaims{
+				"sub":                userID.String(),
+				"preferred_username": "john",
+				"sid":                "session-1",
+				"scope":              "orders.read orders.write",
+				"realm_access":       map[string]any{"roles": []string{"admin"}},
+				"tenant_id":          "acme",
+			}),
+			want: security.Authentication{
+				User:        security.User{ID: userID, Name: "john"},
+				SessionID:   "session-1",
+				TenantID:    "acme",
+				Authorities: security.Authorities{"admin", "scope:orders.read", "scope:orders.write"},
+			},
+		},
+		{
+			name:  "Non UUID subject",
+			token: idp.token(t, nil),
+			want: security.Authentication{
+				User: security.User{ID: uuid.NewSHA1(uuid.NameSpaceURL, []byte(idp.URL+"#user-1"))},
+			},
+		},
+		{
+			name:    "Wrong issuer",
+			token:   idp.token(t, gojwt.MapClaims{"iss": "https://evil.example.com"}),
+			wantErr: true,
+		},
+		{
+			name:    "Wrong audience",
+			token:   idp.token(t, gojwt.MapClaims{"aud": "billing"}),
+			wantErr: true,
+		},
+		{
+			name:    "Expired",
+			token:   idp.token(t, gojwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}),
+			wantErr: true,
+		},
+		{
+			name:    "No expiry",
+			token:   idp.token(t, gojwt.MapClaims{"exp": nil}),
+			wantErr: true,
+		},
+		{
+			name:    "No subject",
+			token:   idp.token(t, gojwt.MapClaims{"sub": nil}),
+			wantErr: true,
+		},
+		{
+			name: "Algorithm not allowed",
+			token: idp.token(t, nil, func(token *gojwt.Token) any {
+				key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				token.Method = gojwt.SigningMethodES256
+				token.Header["alg"] = gojwt.SigningMethodES256.Alg()
+
+				return key
+			}),
+			wantErr: true,
+		},
+		{
+			name: "Unknown key",
+			token: idp.token(t, nil, func(token *gojwt.Token) any {
+				key, _ := rsa.GenerateKey(rand.Reader, 2048)
+				token.Header[jwt.HeaderKeyID] = "unknown"
+
+				return key
+			}),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := provider.Authenticate(context.Background(), tt.token)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errs.ErrAuthFailure)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewProvider_issuerMismatch(t *testing.T) {
+	idp := newTestIdP(t)
+
+	_, err := oidc.NewProvider(context.Background(), &oidc.Config{IssuerURL: idp.URL + "/realms/other", Audience: "orders"})
+	assert.ErrorIs(t, err, oidc.ErrDiscovery)
+}
+
+func TestNewProvider_audience(t *testing.T) {
+	idp := newTestIdP(t)
+
+	_, err := oidc.NewProvider(context.Background(), &oidc.Config{IssuerURL: idp.URL})
+	assert.ErrorIs(t, err, oidc.ErrInvalidConfig)
+
+	_, err = oidc.NewProvider(context.Background(), &oidc.Config{IssuerURL: idp.URL, SkipAudienceCheck: true})
+	assert.NoError(t, err)
+}
+
+func TestNewProvider_algorithms(t *testing.T) {
+	idp := newTestIdP(t)
+
+	tests := []struct {
+		name       string
+		supported  []string
+		algorithms []string
+		wantErr    bool
+	}{
+		{name: "Not advertised", algorithms: []string{"RS256"}},
+		{name: "Supported", supported: []string{"ES256", "RS256"}, algorithms: []string{"RS256"}},
+		{name: "Partially supported", supported: []string{"RS256"}, algorithms: []string{"ES256", "RS256"}},
+		{name: "Not supported", supported: []string{"ES256"}, algorithms: []string{"RS256"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp.algorithms = tt.supported
+
+			provider, err := oidc.NewProvider(context.Background(),
+				&oidc.Config{IssuerURL: idp.URL, Audience: "orders", Algorithms: tt.algorithms})
+			if tt.wantErr {
+				assert.ErrorIs(t, err, oidc.ErrInvalidConfig)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			_, err = provider.Authenticate(context.Background(), idp.token(t, nil))
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/wal1251/pkg/core/ctxs"
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/tools/collections"
+)
+
+const (
+	PolicyContextKey   = "AUTH-Policy"   // Ключ хранения имени политики доступа операции.
+	ResourceContextKey = "AUTH-Resource" // Ключ хранения ресурса, к которому запрашивается доступ.
+)
+
+var (
+	ErrPolicyNotFound = errors.New("policy not found")
+	ErrInvalidPolicy  = errors.New("invalid policy")
+
+	_ AccessChecker = AuthoritiesChecker{}
+	_ AccessChecker = (*PolicyEngine)(nil)
+)
+
+type (
+	// AccessChecker принимает решение о предоставлении доступа аутентифицированному пользователю к операции с
+	// заявленными требованиями безопасности.
+	AccessChecker interface {
+		Decide(ctx context.Context, auth Authentication, requirements Requirements) Decision
+	}
+
+	// Decision решение о предоставлении доступа, записывается в журнал аудита, см. Audit.
+	Decision struct {
+		Granted     bool        // Доступ предоставлен?
+		Policy      string      // Примененная политика.
+		Reason      string      // Причина отказа.
+		Authority   Authorities // Требуемые полномочия.
+		Authorities Authorities // Полномочия пользователя с учетом иерархии ролей.
+	}
+
+	// AuthoritiesChecker проверяет, что пользователь обладает хотя бы одним из требуемых полномочий, см. Authorities.Meets.
+	AuthoritiesChecker struct{}
+
+	// ResourceCheck проверка доступа к конкретному ресурсу, например, является ли пользователь владельцем записи.
+	ResourceCheck func(ctx context.Context, auth Authentication, resource any) bool
+
+	// RoleHierarchy иерархия ролей: роль раскрывается в набор вложенных ролей и разрешений.
+	RoleHierarchy map[Authority]Authorities
+
+	// PolicySpec описание политики доступа. Все заданные условия должны быть выполнены.
+	PolicySpec struct {
+		AllOf    []string `yaml:"all_of"`   // Требуются все перечисленные полномочия.
+		AnyOf    []string `yaml:"any_of"`   // Требуется хотя бы одно из перечисленных полномочий.
+		Policies []string `yaml:"policies"` // Должны быть выполнены все перечисленные политики.
+		Resource []string `yaml:"resource"` // Должны пройти все перечисленные проверки ресурса.
+	}
+
+	// PolicyConfig конфигурация движка политик, как правило загружается из YAML, см. LoadPolicyConfig.
+	//
+	//	roles:
+	//	  admin: [manager, users.delete]
+	//	  manager: [users.read, users.write]
+	//	policies:
+	//	  users.update:
+	//	    all_of: [users.write]
+	//	    resource: [owner]
+	PolicyConfig struct {
+		Roles    map[string][]string   `yaml:"roles"`
+		Policies map[string]PolicySpec `yaml:"policies"`
+	}
+
+	// PolicyEngine движок политик доступа: раскрывает роли по иерархии, проверяет требуемые полномочия и именованные
+	// политики, записывает решения в журнал аудита. Реализует AccessChecker.
+	PolicyEngine struct {
+		hierarchy RoleHierarchy
+		policies  map[string]PolicySpec
+		checks    map[string]ResourceCheck
+	}
+)
+
+// Decide см. AccessChecker.
+func (AuthoritiesChecker) Decide(_ context.Context, auth Authentication, requirements Requirements) Decision {
+	decision := Decision{
+		Granted:     auth.Authorities.Meets(requirements.Authorities),
+		Policy:      requirements.Policy,
+		Authority:   requirements.Authorities,
+		Authorities: auth.Authorities,
+	}
+
+	if !decision.Granted {
+		decision.Reason = "missing authority"
+	}
+
+	return decision
+}
+
+// Err возвращает nil, если доступ предоставлен, иначе ошибку errs.ErrForbidden.
+func (d Decision) Err() error {
+	if d.Granted {
+		return nil
+	}
+
+	return errs.Wrapf(errs.ErrForbidden, "requested action is not permitted for current user: %s", d.Reason)
+}
+
+// Expand возвращает полномочия, дополненные всеми ролями и разрешениями, вложенными в них по иерархии.
+func (h RoleHierarchy) Expand(authorities Authorities) Authorities {
+	expanded := collections.NewSet[Authority]()
+	result := make(Authorities, 0, len(authorities))
+
+	var visit func(Authority)
+	visit = func(authority Authority) {
+		if expanded.Contains(authority) {
+			return
+		}
+
+		expanded.Add(authority)
+		result = append(result, authority)
+
+		for _, child := range h[authority] {
+			visit(child)
+		}
+	}
+
+	for _, authority := range authorities {
+		visit(authority)
+	}
+
+	return result
+}
+
+// Decide см. AccessChecker. Полномочия пользователя раскрываются по иерархии ролей, затем проверяются требуемые
+// полномочия (хотя бы одно из) и политика Requirements.Policy, если она задана.
+func (e *PolicyEngine) Decide(ctx context.Context, auth Authentication, requirements Requirements) Decision {
+	auth.Authorities = e.hierarchy.Expand(auth.Authorities)
+
+	decision := AuthoritiesChecker{}.Decide(ctx, auth, requirements)
+	if !decision.Granted || requirements.Policy == "" {
+		return decision
+	}
+
+	if err := e.evaluate(ctx, auth, requirements.Policy, requirements.Resource, nil); err != nil {
+		decision.Granted = false
+		decision.Reason = err.Error()
+	}
+
+	return decision
+}
+
+// Authorize проверяет доступ пользователя к ресурсу по политике с именем policy, записывает решение в журнал аудита.
+// Вернет ошибку errs.ErrForbidden, если доступ запрещен.
+func (e *PolicyEngine) Authorize(ctx context.Context, auth Authentication, policy string, resource any) error {
+	decision := e.Decide(ctx, auth, Requirements{IsAuthorizationRequired: true, Policy: policy, Resource: resource})
+	Audit(ctx, auth, decision)
+
+	return decision.Err()
+}
+
+// Expand возвращает полномочия, раскрытые по иерархии ролей движка.
+func (e *PolicyEngine) Expand(authorities Authorities) Authorities {
+	return e.hierarchy.Expand(authorities)
+}
+
+func (e *PolicyEngine) evaluate(ctx context.Context, auth Authentication, name string, resource any, visited []string) error {
+	for _, v := range visited {
+		if v == name {
+			return fmt.Errorf("%w: cyclic reference: %s", ErrInvalidPolicy, name)
+		}
+	}
+
+	spec, ok := e.policies[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrPolicyNotFound, name)
+	}
+
+	granted := collections.NewSet[Authority](auth.Authorities...)
+
+	for _, authority := range spec.AllOf {
+		if !granted.Contains(Authority(authority)) {
+			return fmt.Errorf("policy %s: missing authority %s", name, authority)
+		}
+	}
+
+	if len(spec.AnyOf) != 0 && !granted.ContainsAny(AuthoritiesFromString(spec.AnyOf)...) {
+		return fmt.Errorf("policy %s: none of authorities %s", name, strings.Join(spec.AnyOf, ", "))
+	}
+
+	for _, nested := range spec.Policies {
+		if err := e.evaluate(ctx, auth, nested, resource, append(visited, name)); err != nil {
+			return err
+		}
+	}
+
+	for _, check := range spec.Resource {
+		if !e.checks[check](ctx, auth, resource) {
+			return fmt.Errorf("policy %s: resource check %s failed", name, check)
+		}
+	}
+
+	return nil
+}
+
+// NewPolicyEngine возвращает новый PolicyEngine. Проверки ресурсов checks, на которые ссылаются политики, должны быть
+// переданы по имени, иначе вернет ошибку ErrInvalidPolicy.
+func NewPolicyEngine(config PolicyConfig, checks map[string]ResourceCheck) (*PolicyEngine, error) {
+	hierarchy := make(RoleHierarchy, len(config.Roles))
+	for role, children := range config.Roles {
+		hierarchy[Authority(role)] = AuthoritiesFromString(children)
+	}
+
+	for name, spec := range config.Policies {
+		for _, nested := range spec.Policies {
+			if _, ok := config.Policies[nested]; !ok {
+				return nil, fmt.Errorf("%w: policy %s refers to unknown policy %s", ErrInvalidPolicy, name, nested)
+			}
+		}
+
+		for _, check := range spec.Resource {
+			if _, ok := checks[check]; !ok {
+				return nil, fmt.Errorf("%w: policy %s refers to unknown resource check %s", ErrInvalidPolicy, name, check)
+			}
+		}
+	}
+
+	return &PolicyEngine{
+		hierarchy: hierarchy,
+		policies:  config.Policies,
+		checks:    checks,
+	}, nil
+}
+
+// LoadPolicyConfig загружает конфигурацию политик из YAML.
+func LoadPolicyConfig(reader io.Reader) (PolicyConfig, error) {
+	var config PolicyConfig
+
+	if err := yaml.NewDecoder(reader).Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return config, fmt.Errorf("can't unmarshal policy config from yaml: %w", err)
+	}
+
+	return config, nil
+}
+
+// LoadPolicyConfigFromFile загружает конфигурацию политик из YAML файла.
+func LoadPolicyConfigFromFile(fileName string) (PolicyConfig, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return PolicyConfig{}, fmt.Errorf("unable to load policy config: %w", err)
+	}
+	defer file.Close()
+
+	return LoadPolicyConfig(file)
+}
+
+// Decide принимает решение о доступе с помощью checkers, доступ предоставляется, если его предоставили все checkers.
+// Если checkers не указаны, используется AuthoritiesChecker.
+func Decide(ctx context.Context, auth Authentication, requirements Requirements, checkers ...AccessChecker) Decision {
+	if len(checkers) == 0 {
+		return AuthoritiesChecker{}.Decide(ctx, auth, requirements)
+	}
+
+	var decision Decision
+	for _, checker := range checkers {
+		if decision = checker.Decide(ctx, auth, requirements); !decision.Granted {
+			return decision
+		}
+	}
+
+	return decision
+}
+
+// WithPolicy возвращает контекст с именем политики доступа операции, см. DefaultManager.SecurityRequirements.
+func WithPolicy(ctx context.Context, policy string) context.Context {
+	return ctxs.ValuePut(ctx, PolicyContextKey, policy)
+}
+
+// WithResource возвращает контекст с ресурсом, к которому запрашивается доступ, см. DefaultManager.SecurityRequirements.
+func WithResource(ctx context.Context, resource any) context.Context {
+	return ctxs.ValuePut(ctx, ResourceContextKey, resource)
+}
+
+// Audit записывает решение о доступе в журнал аудита.
+func Audit(ctx context.Context, auth Authentication, decision Decision) {
+	logger := logs.FromContext(ctx)
+
+	event := logger.Info()
+	if !decision.Granted {
+		event = logger.Warn()
+	}
+
+	logs.EventWith(event,
+		logs.AuditTag.Value("access"),
+		logs.UserIDTag.Value(auth.User.ID),
+		logs.TenantIDTag.Value(auth.TenantID).If(auth.TenantID != ""),
+		logs.PolicyTag.Value(decision.Policy).If(decision.Policy != ""),
+		logs.UserAuthorityTag.Value(logs.TagStringArray(decision.Authorities.ToStrings())),
+	).
+		Bool("granted", decision.Granted).
+		Strs("required", decision.Authority.ToStrings()).
+		Str("reason", decision.Reason).
+		Msg("access decision")
+}
@@ -0,0 +1,97 @@
+package security_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/security"
+)
+
+const testPolicies = `
+roles:
+  admin: [manager, users.delete]
+  manager: [users.read, users.write]
+  auditor: [users.read]
+policies:
+  users.read:
+    any_of: [users.read]
+  users.update:
+    all_of: [users.read, users.write]
+  users.update.own:
+    any_of: [users.write, self]
+    resource: [owner]
+  users.delete:
+    all_of: [users.delete]
+    policies: [users.update]
+`
+
+func TestPolicyEngine_Authorize(t *testing.T) {
+	owner := uuid.New()
+
+	config, err := security.LoadPolicyConfig(strings.NewReader(testPolicies))
+	require.NoError(t, err)
+
+	engine, err := security.NewPolicyEngine(config, map[string]security.ResourceCheck{
+		"owner": func(_ context.Context, auth security.Authentication, resource any) bool {
+			return resource == auth.User.ID
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		authorities security.Authorities
+		policy      string
+		resource    any
+		wantErr     error
+	}{
+		{name: "Role expands into permission", authorities: security.Authorities{"admin"}, policy: "users.read"},
+		{name: "All of granted", authorities: security.Authorities{"manager"}, policy: "users.update"},
+		{name: "All of denied", authorities: security.Authorities{"auditor"}, policy: "users.update", wantErr: errs.ErrForbidden},
+		{name: "Nested policy", authorities: security.Authorities{"admin"}, policy: "users.delete"},
+		{name: "Nested policy denied", authorities: security.Authorities{"users.delete"}, policy: "users.delete", wantErr: errs.ErrForbidden},
+		{name: "Resource owner", authorities: security.Authorities{"self"}, policy: "users.update.own", resource: owner},
+		{name: "Resource of other user", authorities: security.Authorities{"self"}, policy: "users.update.own", resource: uuid.New(), wantErr: errs.ErrForbidden},
+		{name: "Unknown policy", authorities: security.Authorities{"admin"}, policy: "foo", wantErr: errs.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := security.Authentication{User: security.User{ID: owner}, Authorities: tt.authorities}
+
+			err := engine.Authorize(context.Background(), auth, tt.policy, tt.resource)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewPolicyEngine_invalid(t *testing.T) {
+	_, err := security.NewPolicyEngine(security.PolicyConfig{
+		Policies: map[string]security.PolicySpec{"foo": {Resource: []string{"owner"}}},
+	}, nil)
+	assert.ErrorIs(t, err, security.ErrInvalidPolicy)
+
+	_, err = security.NewPolicyEngine(security.PolicyConfig{
+		Policies: map[string]security.PolicySpec{"foo": {Policies: []string{"bar"}}},
+	}, nil)
+	assert.ErrorIs(t, err, security.ErrInvalidPolicy)
+}
+
+func TestRoleHierarchy_Expand(t *testing.T) {
+	hierarchy := security.RoleHierarchy{
+		"admin":   {"manager", "admin"},
+		"manager": {"read", "admin"},
+	}
+
+	assert.ElementsMatch(t, security.Authorities{"admin", "manager", "read"}, hierarchy.Expand(security.Authorities{"admin"}))
+}
@@ -60,7 +60,7 @@ var _ Manager = DefaultManager{}
 type (
 	// RequestCredentials формирует скоуп аутентификационных запросов.
 	RequestCredentials interface {
-		BearerToken | Credentials
+		BearerToken | Credentials | APIKey | SessionID | ClientCertificate
 	}
 
 	// Manager менеджер авторизации. Ответит на вопросы: какие права нужны для доступа и кто сейчас авторизован.
@@ -77,6 +77,9 @@ type (
 		Authenticate(context.Context, CRED) (Authentication, error)
 	}
 
+	// AuthenticationProviderFn функциональное представление AuthenticationProvider.
+	AuthenticationProviderFn[CRED RequestCredentials] func(context.Context, CRED) (Authentication, error)
+
 	// HTTPCredentialsProvider извлекает аутентификационный запрос из HTTP запроса.
 	HTTPCredentialsProvider[CRED RequestCredentials] func(r *http.Request) CRED
 
@@ -86,13 +89,28 @@ type (
 	}
 )
 
+// Authenticate см. AuthenticationProvider.
+func (f AuthenticationProviderFn[CRED]) Authenticate(ctx context.Context, credentials CRED) (Authentication, error) {
+	return f(ctx, credentials)
+}
+
 // Authorized см. Manager.Authorized().
 func (m DefaultManager) Authorized(ctx context.Context) Authentication {
 	return ctxs.ValueGet[Authentication](ctx, AuthorizedContextKey)
 }
 
-// SecurityRequirements см. Manager.SecurityRequirements().
+// SecurityRequirements см. Manager.SecurityRequirements(). Политика и ресурс читаются из контекста, см. WithPolicy и
+// WithResource.
 func (m DefaultManager) SecurityRequirements(ctx context.Context) Requirements {
+	requirements := m.authoritiesRequirements(ctx)
+	requirements.Policy = ctxs.ValueGet[string](ctx, PolicyContextKey)
+	requirements.Resource = ctxs.ValueGet[any](ctx, ResourceContextKey)
+	requirements.IsAuthorizationRequired = requirements.IsAuthorizationRequired || requirements.Policy != ""
+
+	return requirements
+}
+
+func (m DefaultManager) authoritiesRequirements(ctx context.Context) Requirements {
 	scopes := ctx.Value(m.AuthoritiesContextKey)
 	if scopes == nil {
 		return Requirements{}
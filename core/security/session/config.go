@@ -0,0 +1,34 @@
+package session
+
+import (
+	"time"
+
+	"github.com/wal1251/pkg/core/cfg"
+)
+
+const (
+	CfgKeyIdleTTL      cfg.Key = "SESSION_IDLE_TTL"         // Время жизни сессии без активности (duration).
+	CfgKeyAbsoluteTTL  cfg.Key = "SESSION_ABSOLUTE_TTL"     // Максимальное время жизни сессии (duration).
+	CfgKeyCookieName   cfg.Key = "SESSION_COOKIE_NAME"      // Имя cookie сессии (string).
+	CfgKeyCookieDomain cfg.Key = "SESSION_COOKIE_DOMAIN"    // Домен cookie сессии (string).
+	CfgKeyCookiePath   cfg.Key = "SESSION_COOKIE_PATH"      // Путь cookie сессии (string).
+	CfgKeyInsecure     cfg.Key = "SESSION_COOKIE_INSECURE"  // Не устанавливать атрибут Secure, только для разработки (bool).
+	CfgKeySameSite     cfg.Key = "SESSION_COOKIE_SAME_SITE" // Атрибут SameSite: lax, strict или none (string).
+
+	CfgDefaultIdleTTL     = 30 * time.Minute // Время жизни сессии без активности по умолчанию.
+	CfgDefaultAbsoluteTTL = 12 * time.Hour   // Максимальное время жизни сессии по умолчанию.
+	CfgDefaultCookieName  = "session_id"     // Имя cookie сессии по умолчанию.
+	CfgDefaultCookiePath  = "/"              // Путь cookie сессии по умолчанию.
+	CfgDefaultSameSite    = "lax"            // Атрибут SameSite по умолчанию.
+)
+
+// Config конфигурация менеджера сессий.
+type Config struct {
+	IdleTTL      time.Duration // Время жизни сессии без активности, продлевается при каждом обращении.
+	AbsoluteTTL  time.Duration // Максимальное время жизни сессии независимо от активности.
+	CookieName   string        // Имя cookie сессии.
+	CookieDomain string        // Домен cookie сессии.
+	CookiePath   string        // Путь cookie сессии.
+	Insecure     bool          // Не устанавливать атрибут Secure (например, при разработке без TLS).
+	SameSite     string        // Атрибут SameSite: lax, strict или none.
+}
@@ -0,0 +1,21 @@
+package session
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/wal1251/pkg/core/cfg"
+	"github.com/wal1251/pkg/core/cfg/viperx"
+)
+
+// CfgFromViper загрузка конфига Config с помощью viper.
+func CfgFromViper(loader *viper.Viper, keyMapping ...cfg.KeyMap) *Config {
+	return &Config{
+		IdleTTL:      viperx.Get(loader, CfgKeyIdleTTL.Map(keyMapping...), CfgDefaultIdleTTL),
+		AbsoluteTTL:  viperx.Get(loader, CfgKeyAbsoluteTTL.Map(keyMapping...), CfgDefaultAbsoluteTTL),
+		CookieName:   viperx.Get(loader, CfgKeyCookieName.Map(keyMapping...), CfgDefaultCookieName),
+		CookieDomain: viperx.Get(loader, CfgKeyCookieDomain.Map(keyMapping...), ""),
+		CookiePath:   viperx.Get(loader, CfgKeyCookiePath.Map(keyMapping...), CfgDefaultCookiePath),
+		Insecure:     viperx.Get(loader, CfgKeyInsecure.Map(keyMapping...), false),
+		SameSite:     viperx.Get(loader, CfgKeySameSite.Map(keyMapping...), CfgDefaultSameSite),
+	}
+}
@@ -0,0 +1,340 @@
+// Package session предоставляет серверные сессии для браузерных клиентов: непрозрачный идентификатор сессии передается
+// в cookie, данные сессии хранятся в memorystore.MemoryStore.
+//
+// Сессия продлевается при каждом обращении (скользящее время жизни Config.IdleTTL), но живет не дольше
+// Config.AbsoluteTTL. При входе пользователя идентификатор сессии меняется (защита от фиксации сессии). Manager
+// реализует security.AuthenticationProvider и подключается к mw.Authorizer:
+//
+//	sessions := session.NewManager(store, session.CfgFromViper(loader))
+//	router.Use(mw.Authorizer(authManager, sessions.Extract, sessions))
+//
+// Для защиты от CSRF используйте mw.CSRF с привязкой токена к сессии:
+//
+//	router.Use(mw.CSRF(mw.CSRFOptions{
+//		SessionID: func(r *http.Request) string { return string(sessions.Extract(r)) },
+//		Secret:    csrfSecret,
+//	}))
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/core/security"
+)
+
+const (
+	DefaultKeyPrefix     = "session"   // Префикс ключей хранилища сессий по умолчанию.
+	DefaultTouchInterval = time.Minute // Минимальный интервал продления сессии.
+
+	idLength = 32 // Длина идентификатора сессии в байтах.
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found") // Сессия не найдена или истекла.
+
+	_ security.AuthenticationProvider[security.SessionID] = (*Manager)(nil)
+)
+
+type (
+	// Session серверная сессия.
+	Session struct {
+		ID             security.SessionID      `json:"-"`              // Секретный идентификатор сессии из cookie.
+		PublicID       string                  `json:"public_id"`      //nolint:tagliatelle // Несекретный идентификатор для журналов и аудита, не меняется при ротации.
+		Authentication security.Authentication `json:"authentication"` // Данные аутентификации пользователя.
+		Values         map[string]string       `json:"values,omitempty"`
+		CreatedAt      time.Time               `json:"created_at"`   //nolint:tagliatelle
+		LastSeenAt     time.Time               `json:"last_seen_at"` //nolint:tagliatelle
+	}
+
+	// Manager управляет серверными сессиями и их cookie, реализует security.AuthenticationProvider.
+	Manager struct {
+		store         memorystore.MemoryStore
+		config        Config
+		prefix        string
+		touchInterval time.Duration
+		now           func() time.Time
+	}
+
+	// Option опция Manager.
+	Option func(*Manager)
+)
+
+// Create создает новую сессию для аутентифицированного пользователя.
+func (m *Manager) Create(ctx context.Context, auth security.Authentication) (Session, error) {
+	id, err := newID()
+	if err != nil {
+		return Session{}, err
+	}
+
+	now := m.now()
+	session := Session{
+		ID:             id,
+		PublicID:       uuid.NewString(),
+		Authentication: auth,
+		CreatedAt:      now,
+		LastSeenAt:     now,
+	}
+
+	return session, m.Save(ctx, session)
+}
+
+// Get возвращает сессию по идентификатору и продлевает ее. Вернет ErrSessionNotFound, если сессия не найдена или
+// истекла.
+func (m *Manager) Get(ctx context.Context, id security.SessionID) (Session, error) {
+	if id == "" {
+		return Session{}, ErrSessionNotFound
+	}
+
+	value, err := m.store.Get(ctx, m.key(id))
+	if err != nil {
+		if errors.Is(err, memorystore.ErrKeyNotFound) {
+			return Session{}, ErrSessionNotFound
+		}
+
+		return Session{}, fmt.Errorf("can't read session: %w", err)
+	}
+
+	var session Session
+	if err = value.Struct(&session); err != nil {
+		return Session{}, fmt.Errorf("can't read session: %w", err)
+	}
+
+	session.ID = id
+	now := m.now()
+
+	if now.Sub(session.CreatedAt) >= m.config.AbsoluteTTL {
+		if err = m.Destroy(ctx, id); err != nil {
+			logs.FromContext(ctx).Warn().Err(err).Msg("failed to destroy expired session")
+		}
+
+		return Session{}, ErrSessionNotFound
+	}
+
+	if now.Sub(session.LastSeenAt) >= m.touchInterval {
+		session.LastSeenAt = now
+		if err = m.Save(ctx, session); err != nil {
+			logs.FromContext(ctx).Warn().Err(err).Msg("failed to prolong session")
+		}
+	}
+
+	return session, nil
+}
+
+// Save сохраняет сессию, время жизни отсчитывается заново, но не превышает абсолютного времени жизни сессии.
+func (m *Manager) Save(ctx context.Context, session Session) error {
+	ttl := min(m.config.IdleTTL, m.config.AbsoluteTTL-m.now().Sub(session.CreatedAt))
+	if ttl <= 0 {
+		return ErrSessionNotFound
+	}
+
+	if err := m.store.Set(ctx, m.key(session.ID), session, ttl); err != nil {
+		return fmt.Errorf("can't save session: %w", err)
+	}
+
+	return nil
+}
+
+// Rotate выдает сессии новый идентификатор, прежний идентификатор перестает действовать. Данные сессии сохраняются.
+func (m *Manager) Rotate(ctx context.Context, session Session) (Session, error) {
+	id, err := newID()
+	if err != nil {
+		return Session{}, err
+	}
+
+	previous := session.ID
+	session.ID = id
+	session.LastSeenAt = m.now()
+
+	if err = m.Save(ctx, session); err != nil {
+		return Session{}, err
+	}
+
+	return session, m.Destroy(ctx, previous)
+}
+
+// Destroy удаляет сессию.
+func (m *Manager) Destroy(ctx context.Context, id security.SessionID) error {
+	if _, err := m.store.Delete(ctx, m.key(id)); err != nil {
+		return fmt.Errorf("can't destroy session: %w", err)
+	}
+
+	return nil
+}
+
+// Login открывает сессию аутентифицированного пользователя и устанавливает cookie. Сессия, переданная в запросе,
+// удаляется, ее данные переносятся в новую сессию с новым идентификатором.
+func (m *Manager) Login(w http.ResponseWriter, r *http.Request, auth security.Authentication) (Session, error) {
+	ctx := r.Context()
+
+	session, err := m.Create(ctx, auth)
+	if err != nil {
+		return Session{}, err
+	}
+
+	if previous, err := m.Get(ctx, m.Extract(r)); err == nil {
+		session.Values = previous.Values
+		if err = m.Save(ctx, session); err != nil {
+			return Session{}, err
+		}
+
+		if err = m.Destroy(ctx, previous.ID); err != nil {
+			return Session{}, err
+		}
+	}
+
+	http.SetCookie(w, m.Cookie(session))
+
+	return session, nil
+}
+
+// Logout удаляет сессию, переданную в запросе, и cookie.
+func (m *Manager) Logout(w http.ResponseWriter, r *http.Request) error {
+	http.SetCookie(w, m.expiredCookie())
+
+	if id := m.Extract(r); id != "" {
+		return m.Destroy(r.Context(), id)
+	}
+
+	return nil
+}
+
+// Authenticate см. security.AuthenticationProvider. В Authentication.SessionID помещается несекретный идентификатор
+// сессии Session.PublicID.
+func (m *Manager) Authenticate(ctx context.Context, id security.SessionID) (security.Authentication, error) {
+	session, err := m.Get(ctx, id)
+	if err != nil {
+		return security.Authentication{}, errs.With(errs.ErrAuthFailure, err)
+	}
+
+	auth := session.Authentication
+	auth.SessionID = session.PublicID
+
+	return auth, nil
+}
+
+// Extract извлекает идентификатор сессии из cookie запроса, реализует security.HTTPCredentialsProvider.
+func (m *Manager) Extract(r *http.Request) security.SessionID {
+	cookie, err := r.Cookie(m.config.CookieName)
+	if err != nil {
+		return ""
+	}
+
+	return security.SessionID(cookie.Value)
+}
+
+// Cookie возвращает cookie сессии с атрибутами HttpOnly, Secure и SameSite.
+func (m *Manager) Cookie(session Session) *http.Cookie {
+	return &http.Cookie{
+		Name:     m.config.CookieName,
+		Value:    string(session.ID),
+		Path:     m.config.CookiePath,
+		Domain:   m.config.CookieDomain,
+		Secure:   !m.config.Insecure,
+		HttpOnly: true,
+		SameSite: SameSite(m.config.SameSite),
+	}
+}
+
+func (m *Manager) expiredCookie() *http.Cookie {
+	cookie := m.Cookie(Session{})
+	cookie.MaxAge = -1
+
+	return cookie
+}
+
+// key возвращает ключ хранилища: хранится хеш идентификатора, чтобы содержимое хранилища не раскрывало действующие
+// идентификаторы сессий.
+func (m *Manager) key(id security.SessionID) string {
+	sum := sha256.Sum256([]byte(id))
+
+	return m.prefix + ":" + hex.EncodeToString(sum[:])
+}
+
+// SameSite возвращает значение атрибута cookie SameSite по имени: lax, strict или none.
+func SameSite(name string) http.SameSite {
+	switch strings.ToLower(name) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// WithKeyPrefix устанавливает префикс ключей хранилища.
+func WithKeyPrefix(prefix string) Option {
+	return func(m *Manager) {
+		m.prefix = prefix
+	}
+}
+
+// WithTouchInterval устанавливает минимальный интервал продления сессии, чтобы не писать в хранилище на каждом запросе.
+func WithTouchInterval(interval time.Duration) Option {
+	return func(m *Manager) {
+		m.touchInterval = interval
+	}
+}
+
+// WithClock устанавливает источник текущего времени.
+func WithClock(now func() time.Time) Option {
+	return func(m *Manager) {
+		m.now = now
+	}
+}
+
+// NewManager возвращает новый Manager.
+func NewManager(store memorystore.MemoryStore, config *Config, opts ...Option) *Manager {
+	manager := &Manager{
+		store:         store,
+		config:        *config,
+		prefix:        DefaultKeyPrefix,
+		touchInterval: DefaultTouchInterval,
+		now:           time.Now,
+	}
+
+	if manager.config.IdleTTL <= 0 {
+		manager.config.IdleTTL = CfgDefaultIdleTTL
+	}
+
+	if manager.config.AbsoluteTTL <= 0 {
+		manager.config.AbsoluteTTL = CfgDefaultAbsoluteTTL
+	}
+
+	if manager.config.CookieName == "" {
+		manager.config.CookieName = CfgDefaultCookieName
+	}
+
+	if manager.config.CookiePath == "" {
+		manager.config.CookiePath = CfgDefaultCookiePath
+	}
+
+	for _, opt := range opts {
+		opt(manager)
+	}
+
+	return manager
+}
+
+func newID() (security.SessionID, error) {
+	raw := make([]byte, idLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("can't generate session id: %w", err)
+	}
+
+	return security.SessionID(base64.RawURLEncoding.EncodeToString(raw)), nil
+}
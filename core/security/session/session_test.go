@@ -0,0 +1,122 @@
+package session_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/memorystore/local"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/session"
+)
+
+func newMemoryStore(t *testing.T, now func() time.Time) *local.Store {
+	t.Helper()
+
+	store, err := local.NewStore(&local.Config{}, local.WithClock(now))
+	require.NoError(t, err)
+
+	return store
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	manager := session.NewManager(newMemoryStore(t, clock), &session.Config{
+		IdleTTL:     10 * time.Minute,
+		AbsoluteTTL: time.Hour,
+	}, session.WithClock(clock), session.WithTouchInterval(0))
+
+	auth := security.Authentication{
+		User:        security.User{ID: uuid.New(), Name: "admin"},
+		Authorities: security.Authorities{"admin"},
+	}
+
+	created, err := manager.Create(ctx, auth)
+	require.NoError(t, err)
+
+	got, err := manager.Authenticate(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, auth.User, got.User)
+	assert.Equal(t, created.PublicID, got.SessionID)
+	assert.NotEqual(t, string(created.ID), got.SessionID)
+
+	// Скользящее время жизни: активная сессия продлевается.
+	for i := 0; i < 5; i++ {
+		now = now.Add(9 * time.Minute)
+
+		_, err = manager.Authenticate(ctx, created.ID)
+		require.NoError(t, err)
+	}
+
+	// Неактивная сессия истекает.
+	now = now.Add(11 * time.Minute)
+
+	_, err = manager.Authenticate(ctx, created.ID)
+	assert.ErrorIs(t, err, errs.ErrAuthFailure)
+
+	// Абсолютное время жизни не продлевается активностью.
+	created, err = manager.Create(ctx, auth)
+	require.NoError(t, err)
+
+	for i := 0; i < 7; i++ {
+		now = now.Add(9 * time.Minute)
+		_, err = manager.Get(ctx, created.ID)
+	}
+
+	assert.ErrorIs(t, err, session.ErrSessionNotFound)
+}
+
+func TestManager_LoginLogout(t *testing.T) {
+	manager := session.NewManager(newMemoryStore(t, time.Now), &session.Config{SameSite: "strict"})
+	auth := security.Authentication{User: security.User{ID: uuid.New(), Name: "admin"}}
+
+	anonymous, err := manager.Create(context.Background(), security.Authentication{})
+	require.NoError(t, err)
+
+	anonymous.Values = map[string]string{"return_to": "/orders"}
+	require.NoError(t, manager.Save(context.Background(), anonymous))
+
+	r := httptest.NewRequest(http.MethodPost, "/login", nil)
+	r.AddCookie(manager.Cookie(anonymous))
+
+	w := httptest.NewRecorder()
+
+	logged, err := manager.Login(w, r, auth)
+	require.NoError(t, err)
+
+	// Идентификатор сессии меняется при входе, данные сессии сохраняются.
+	assert.NotEqual(t, anonymous.ID, logged.ID)
+	assert.Equal(t, anonymous.Values, logged.Values)
+
+	_, err = manager.Get(context.Background(), anonymous.ID)
+	assert.ErrorIs(t, err, session.ErrSessionNotFound)
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, session.CfgDefaultCookieName, cookies[0].Name)
+	assert.Equal(t, string(logged.ID), cookies[0].Value)
+	assert.True(t, cookies[0].Secure)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookies[0])
+	assert.Equal(t, logged.ID, manager.Extract(r))
+
+	w = httptest.NewRecorder()
+	require.NoError(t, manager.Logout(w, r))
+	assert.Equal(t, -1, w.Result().Cookies()[0].MaxAge)
+
+	_, err = manager.Authenticate(context.Background(), logged.ID)
+	assert.ErrorIs(t, err, errs.ErrAuthFailure)
+}
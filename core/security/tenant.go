@@ -0,0 +1,73 @@
+package security
+
+import (
+	"context"
+
+	"github.com/wal1251/pkg/core/ctxs"
+	"github.com/wal1251/pkg/core/errs"
+)
+
+// TenantSkipContextKey ключ хранения признака отключения проверки арендатора, см. WithoutTenant.
+const TenantSkipContextKey = "AUTH-TenantSkip"
+
+var _ ResourceCheck = TenantResourceCheck
+
+// TenantOwned ресурс, принадлежащий арендатору.
+type TenantOwned interface {
+	GetTenantID() string // Идентификатор арендатора-владельца ресурса.
+}
+
+// WithTenant возвращает контекст с идентификатором арендатора. Арендатор передается между сервисами через метаданные
+// gRPC и заголовки сообщений KAFKA, см. ctxs.TenantPropagator.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return ctxs.ValuePut(ctx, ctxs.TenantIDKey, tenantID)
+}
+
+// TenantFromContext возвращает идентификатор арендатора из контекста и признак его наличия, см. WithTenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctxs.ValueCheckAndGet[string](ctx, ctxs.TenantIDKey)
+
+	return tenantID, ok && tenantID != ""
+}
+
+// WithoutTenant возвращает контекст, в котором проверка арендатора отключена, например, для фоновых задач и миграций,
+// обрабатывающих данные всех арендаторов.
+func WithoutTenant(ctx context.Context) context.Context {
+	return ctxs.ValuePut(ctx, TenantSkipContextKey, true)
+}
+
+// TenantSkipped возвращает true, если проверка арендатора отключена, см. WithoutTenant.
+func TenantSkipped(ctx context.Context) bool {
+	return ctxs.ValueGet[bool](ctx, TenantSkipContextKey)
+}
+
+// CheckTenant проверяет, что ресурс арендатора tenantID доступен в контексте ctx. Вернет ошибку errs.ErrForbidden, если
+// арендатор в контексте не указан или отличается от tenantID.
+func CheckTenant(ctx context.Context, tenantID string) error {
+	if TenantSkipped(ctx) {
+		return nil
+	}
+
+	current, ok := TenantFromContext(ctx)
+	if !ok {
+		return errs.Wrapf(errs.ErrForbidden, "tenant is not set")
+	}
+
+	if current != tenantID {
+		return errs.Wrapf(errs.ErrForbidden, "cross-tenant access is not permitted")
+	}
+
+	return nil
+}
+
+// TenantResourceCheck проверка доступа к ресурсу, реализующему TenantOwned: ресурс должен принадлежать арендатору
+// пользователя. Регистрируется в PolicyEngine под выбранным именем:
+//
+//	engine, err := security.NewPolicyEngine(config, map[string]security.ResourceCheck{
+//		"tenant": security.TenantResourceCheck,
+//	})
+func TenantResourceCheck(_ context.Context, auth Authentication, resource any) bool {
+	owned, ok := resource.(TenantOwned)
+
+	return ok && auth.TenantID != "" && owned.GetTenantID() == auth.TenantID
+}
@@ -0,0 +1,54 @@
+package security_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/security"
+)
+
+type tenantResource string
+
+func (r tenantResource) GetTenantID() string {
+	return string(r)
+}
+
+func TestCheckTenant(t *testing.T) {
+	auth := security.Authentication{User: security.User{ID: uuid.New()}, TenantID: "acme"}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context //nolint:containedctx
+		tenant  string
+		wantErr bool
+	}{
+		{name: "Same tenant", ctx: auth.ToContext(context.Background()), tenant: "acme"},
+		{name: "Other tenant", ctx: auth.ToContext(context.Background()), tenant: "other", wantErr: true},
+		{name: "No tenant", ctx: context.Background(), tenant: "acme", wantErr: true},
+		{name: "Skipped", ctx: security.WithoutTenant(context.Background()), tenant: "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := security.CheckTenant(tt.ctx, tt.tenant)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errs.ErrForbidden)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTenantResourceCheck(t *testing.T) {
+	auth := security.Authentication{TenantID: "acme"}
+
+	assert.True(t, security.TenantResourceCheck(context.Background(), auth, tenantResource("acme")))
+	assert.False(t, security.TenantResourceCheck(context.Background(), auth, tenantResource("other")))
+	assert.False(t, security.TenantResourceCheck(context.Background(), auth, "acme"))
+	assert.False(t, security.TenantResourceCheck(context.Background(), security.Authentication{}, tenantResource("")))
+}
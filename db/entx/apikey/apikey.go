@@ -0,0 +1,197 @@
+// Package apikey содержит реализацию хранилища ключей API apikey.Storage на базе ent и mixin схемы таблицы ключей.
+//
+// Схема подключается в проект ent как mixin:
+//
+//	func (APIKey) Mixin() []ent.Mixin {
+//		return []ent.Mixin{apikey.Mixin{}}
+//	}
+//
+// Хранилище работает с таблицей напрямую через драйвер ent, поэтому не зависит от сгенерированного кода:
+//
+//	storage := apikey.NewStorage(client.Driver(), apikey.DefaultTable)
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/mixin"
+	"github.com/google/uuid"
+
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/apikey"
+	"github.com/wal1251/pkg/db/entx"
+)
+
+const (
+	DefaultTable = "api_keys" // Имя таблицы ключей по умолчанию.
+
+	FieldName         = "name"           // Название ключа.
+	FieldHash         = "hash"           // Хеш секрета ключа.
+	FieldOwnerID      = "owner_id"       // Идентификатор владельца ключа.
+	FieldOwnerName    = "owner_name"     // Имя владельца ключа.
+	FieldAuthorities  = "authorities"    // Полномочия ключа.
+	FieldExpiresTime  = "expires_time"   // Время окончания срока действия.
+	FieldLastUsedTime = "last_used_time" // Время последнего использования.
+	FieldRevokedTime  = "revoked_time"   // Время отзыва.
+)
+
+var (
+	_ apikey.Storage = (*Storage)(nil)
+
+	columns = []string{
+		entx.FieldID, FieldName, FieldHash, FieldOwnerID, FieldOwnerName, FieldAuthorities,
+		entx.FieldCreateTime, FieldExpiresTime, FieldLastUsedTime, FieldRevokedTime,
+	}
+)
+
+type (
+	// Mixin реализует ent.Mixin, поля таблицы ключей API.
+	Mixin struct {
+		mixin.Schema
+	}
+
+	// Storage хранилище ключей API в таблице БД, реализует apikey.Storage.
+	Storage struct {
+		driver dialect.Driver
+		table  string
+	}
+)
+
+func (Mixin) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID(entx.FieldID, uuid.UUID{}).
+			Default(uuid.New).
+			Immutable(),
+		field.String(FieldName),
+		field.String(FieldHash).
+			Sensitive(),
+		field.UUID(FieldOwnerID, uuid.UUID{}).
+			Optional(),
+		field.String(FieldOwnerName).
+			Optional(),
+		field.Strings(FieldAuthorities).
+			Optional(),
+		field.Time(entx.FieldCreateTime).
+			Default(time.Now).
+			Immutable(),
+		field.Time(FieldExpiresTime).
+			Optional().
+			Nillable(),
+		field.Time(FieldLastUsedTime).
+			Optional().
+			Nillable(),
+		field.Time(FieldRevokedTime).
+			Optional().
+			Nillable(),
+	}
+}
+
+// Save см. apikey.Storage.
+func (s *Storage) Save(ctx context.Context, key apikey.Key) error {
+	authorities, err := json.Marshal(key.Authorities.ToStrings())
+	if err != nil {
+		return fmt.Errorf("can't marshal api key authorities: %w", err)
+	}
+
+	query, args := entsql.Dialect(s.driver.Dialect()).
+		Insert(s.table).
+		Columns(columns...).
+		Values(
+			key.ID, key.Name, key.Hash, key.Owner.ID, key.Owner.Name, string(authorities),
+			key.CreatedAt, nullTime(key.ExpiresAt), nullTime(key.LastUsedAt), nullTime(key.RevokedAt),
+		).
+		OnConflict(entsql.ConflictColumns(entx.FieldID), entsql.ResolveWithNewValues()).
+		Query()
+
+	if err = s.driver.Exec(ctx, query, args, nil); err != nil {
+		return fmt.Errorf("can't save api key %s: %w", key.ID, err)
+	}
+
+	return nil
+}
+
+// Get см. apikey.Storage.
+func (s *Storage) Get(ctx context.Context, id uuid.UUID) (apikey.Key, error) {
+	builder := entsql.Dialect(s.driver.Dialect())
+	table := builder.Table(s.table)
+
+	query, args := builder.
+		Select(columns...).
+		From(table).
+		Where(entsql.EQ(entx.FieldID, id)).
+		Query()
+
+	var rows entsql.Rows
+	if err := s.driver.Query(ctx, query, args, &rows); err != nil {
+		return apikey.Key{}, fmt.Errorf("can't query api key %s: %w", id, err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return apikey.Key{}, fmt.Errorf("can't query api key %s: %w", id, err)
+		}
+
+		return apikey.Key{}, fmt.Errorf("%w: %s", apikey.ErrKeyNotFound, id)
+	}
+
+	var (
+		key                           apikey.Key
+		ownerName                     sql.NullString
+		authorities                   sql.NullString
+		expiresAt, lastUsed, revokeAt sql.NullTime
+	)
+
+	if err := rows.Scan(&key.ID, &key.Name, &key.Hash, &key.Owner.ID, &ownerName, &authorities,
+		&key.CreatedAt, &expiresAt, &lastUsed, &revokeAt); err != nil {
+		return apikey.Key{}, fmt.Errorf("can't scan api key %s: %w", id, err)
+	}
+
+	if authorities.Valid && authorities.String != "" {
+		var list []string
+		if err := json.Unmarshal([]byte(authorities.String), &list); err != nil {
+			return apikey.Key{}, fmt.Errorf("can't unmarshal api key authorities: %w", err)
+		}
+
+		key.Authorities = security.AuthoritiesFromString(list)
+	}
+
+	key.Owner.Name = ownerName.String
+	key.ExpiresAt = expiresAt.Time
+	key.LastUsedAt = lastUsed.Time
+	key.RevokedAt = revokeAt.Time
+
+	return key, nil
+}
+
+// Touch см. apikey.Storage.
+func (s *Storage) Touch(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	query, args := entsql.Dialect(s.driver.Dialect()).
+		Update(s.table).
+		Set(FieldLastUsedTime, usedAt).
+		Where(entsql.EQ(entx.FieldID, id)).
+		Query()
+
+	if err := s.driver.Exec(ctx, query, args, nil); err != nil {
+		return fmt.Errorf("can't update api key %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// NewStorage возвращает хранилище ключей API в таблице table (см. DefaultTable).
+func NewStorage(driver dialect.Driver, table string) *Storage {
+	return &Storage{driver: driver, table: table}
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
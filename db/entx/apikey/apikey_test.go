@@ -0,0 +1,63 @@
+package apikey_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/security"
+	coreapikey "github.com/wal1251/pkg/core/security/apikey"
+	"github.com/wal1251/pkg/db"
+	"github.com/wal1251/pkg/db/entx"
+	"github.com/wal1251/pkg/db/entx/apikey"
+)
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+
+	driver, err := entx.Driver(db.NewCfgSQLiteMem("apikey"))
+	require.NoError(t, err)
+	defer driver.Close()
+
+	_, err = driver.DB().Exec(`CREATE TABLE api_keys (
+		id TEXT PRIMARY KEY, name TEXT, hash TEXT, owner_id TEXT, owner_name TEXT, authorities TEXT,
+		create_time DATETIME, expires_time DATETIME, last_used_time DATETIME, revoked_time DATETIME)`)
+	require.NoError(t, err)
+
+	storage := apikey.NewStorage(driver, apikey.DefaultTable)
+	manager, err := coreapikey.NewManager(storage, coreapikey.NewHMACEncryptor("secret"))
+	require.NoError(t, err)
+
+	owner := security.User{ID: uuid.New(), Name: "billing"}
+
+	plain, key, err := manager.Create(ctx, coreapikey.Spec{
+		Name:        "billing",
+		Owner:       owner,
+		Authorities: security.Authorities{"payments.read", "payments.write"},
+		TTL:         time.Hour,
+	})
+	require.NoError(t, err)
+
+	auth, err := manager.Authenticate(ctx, plain)
+	require.NoError(t, err)
+	assert.Equal(t, owner, auth.User)
+	assert.Equal(t, security.Authorities{"payments.read", "payments.write"}, auth.Authorities)
+
+	stored, err := storage.Get(ctx, key.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.LastUsedAt.IsZero())
+	assert.WithinDuration(t, key.ExpiresAt, stored.ExpiresAt, time.Millisecond)
+
+	require.NoError(t, manager.Revoke(ctx, key.ID))
+
+	_, err = manager.Authenticate(ctx, plain)
+	assert.Error(t, err)
+
+	_, err = storage.Get(ctx, uuid.New())
+	assert.ErrorIs(t, err, coreapikey.ErrKeyNotFound)
+}
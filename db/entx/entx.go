@@ -13,6 +13,7 @@ const (
 	FieldDeletedTime = "deleted_time" // Время установки флага удаления записи.
 	FieldCreateTime  = "create_time"  // Время создания записи.
 	FieldUpdateTime  = "update_time"  // Время обновления записи.
+	FieldTenantID    = "tenant_id"    // Идентификатор арендатора (tenant) - владельца записи.
 )
 
 // Driver возвращает ent драйвер для переданных параметров подключения к БД.
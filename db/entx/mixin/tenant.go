@@ -0,0 +1,40 @@
+package mixin
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"entgo.io/ent/schema/mixin"
+
+	"github.com/wal1251/pkg/db/entx"
+)
+
+// Tenant реализует ent.Mixin, поле идентификатора арендатора (tenant) записи. Запросы к схеме автоматически
+// ограничиваются записями арендатора из контекста, а создаваемые записи получают его идентификатор, см.
+// entx.TenantInterceptor и entx.TenantHook. Схемы с перехватчиками и хуками требуют импорта пакета ent/runtime
+// проекта.
+type Tenant struct {
+	mixin.Schema
+}
+
+func (Tenant) Fields() []ent.Field {
+	return []ent.Field{
+		field.String(entx.FieldTenantID).
+			NotEmpty().
+			Immutable(),
+	}
+}
+
+func (Tenant) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields(entx.FieldTenantID),
+	}
+}
+
+func (Tenant) Interceptors() []ent.Interceptor {
+	return []ent.Interceptor{entx.TenantInterceptor()}
+}
+
+func (Tenant) Hooks() []ent.Hook {
+	return []ent.Hook{entx.TenantHook()}
+}
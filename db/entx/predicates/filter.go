@@ -0,0 +1,70 @@
+package predicates
+
+import (
+	"entgo.io/ent/dialect/sql"
+
+	"github.com/wal1251/pkg/core/presenters/filter"
+)
+
+// Filter возвращает предикат выражения фильтрации, проверенного схемой (см. filter.Schema). Для пустого выражения
+// условие не накладывается. Например:
+//
+//	expression, err := schema.Parse(raw)
+//	if err != nil {
+//		return nil, err
+//	}
+//
+//	items, err := client.Item.Query().Where(predicates.Filter(expression)).All(ctx)
+//
+// ВНИМАНИЕ! Оператор filter.OpRegex поддерживается только диалектом postgres, см. RegexpFold.
+func Filter(expression *filter.Expression) func(s *sql.Selector) {
+	return Optional(func(s *sql.Selector) {
+		for _, condition := range expression.Conditions {
+			Condition(condition)(s)
+		}
+	}, !expression.IsEmpty())
+}
+
+// Condition возвращает предикат условия выражения фильтрации, см. Filter.
+func Condition(condition filter.Condition) func(s *sql.Selector) {
+	if condition.Operator == filter.OpRegex {
+		regexp, _ := condition.Value().(string)
+
+		return RegexpFold(condition.Column, regexp)
+	}
+
+	return func(s *sql.Selector) {
+		column := s.C(condition.Column)
+		value := condition.Value()
+
+		switch condition.Operator {
+		case filter.OpEq:
+			s.Where(sql.EQ(column, value))
+		case filter.OpNe:
+			s.Where(sql.NEQ(column, value))
+		case filter.OpGt:
+			s.Where(sql.GT(column, value))
+		case filter.OpGte:
+			s.Where(sql.GTE(column, value))
+		case filter.OpLt:
+			s.Where(sql.LT(column, value))
+		case filter.OpLte:
+			s.Where(sql.LTE(column, value))
+		case filter.OpIn:
+			s.Where(sql.In(column, condition.Values...))
+		case filter.OpNin:
+			s.Where(sql.NotIn(column, condition.Values...))
+		case filter.OpLike:
+			substr, _ := value.(string)
+			s.Where(sql.ContainsFold(column, substr))
+		case filter.OpNull:
+			if isNull, _ := value.(bool); isNull {
+				s.Where(sql.IsNull(column))
+			} else {
+				s.Where(sql.NotNull(column))
+			}
+		default:
+			s.Where(sql.False())
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package predicates
+
+import (
+	"entgo.io/ent/dialect/sql"
+
+	"github.com/wal1251/pkg/core/presenters/pagination"
+)
+
+// Keyset возвращает предикат выборки страницы курсорной пагинации: элементы, следующие за граничным элементом
+// курсора в порядке keyset.Order(). Для первой страницы условие не накладывается. Например:
+//
+//	keyset, err := query.Keyset(signer, pagination.SortKeys(sorting, item.FieldID))
+//	if err != nil {
+//		return nil, err
+//	}
+//
+//	items, err := client.Item.Query().
+//		Where(predicates.Keyset(keyset)).
+//		Order(predicates.KeysetOrder(keyset)).
+//		Limit(keyset.FetchLimit()).
+//		All(ctx)
+//
+// .
+func Keyset(keyset *pagination.Keyset) func(s *sql.Selector) {
+	terms := keyset.Terms()
+
+	return Optional(func(s *sql.Selector) {
+		predicates := make([]*sql.Predicate, len(terms))
+
+		for i, term := range terms {
+			conditions := make([]*sql.Predicate, 0, len(term.Equal)+1)
+			for _, equal := range term.Equal {
+				conditions = append(conditions, sql.EQ(s.C(equal.Field), equal.Value))
+			}
+
+			if term.Greater {
+				conditions = append(conditions, sql.GT(s.C(term.Field), term.Value))
+			} else {
+				conditions = append(conditions, sql.LT(s.C(term.Field), term.Value))
+			}
+
+			predicates[i] = sql.And(conditions...)
+		}
+
+		s.Where(sql.Or(predicates...))
+	}, len(terms) > 0)
+}
+
+// KeysetOrder возвращает порядок выборки страницы курсорной пагинации, см. Keyset.
+func KeysetOrder(keyset *pagination.Keyset) func(s *sql.Selector) {
+	return Order(keyset.Order()...)
+}
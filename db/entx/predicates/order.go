@@ -0,0 +1,66 @@
+package predicates
+
+import (
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+
+	"github.com/wal1251/pkg/core/presenters/pagination"
+)
+
+// Sort возвращает упорядочивание выборки по параметрам сортировки sort с направлением каждого поля. Например:
+//
+//	sorting, err := pagination.ParseSorting(r.URL.Query()["sort"]...)
+//	if err != nil {
+//		return nil, err
+//	}
+//
+//	sorting.WithAcceptableFields("priority", "created").WithMapping(map[string]string{"created": task.FieldCreatedAt})
+//	if err = sorting.Validate(); err != nil {
+//		return nil, err
+//	}
+//
+//	tasks, err := client.Task.Query().Order(predicates.Sort(sorting)).All(ctx)
+//
+// .
+func Sort(sort pagination.SortQuery) func(s *sql.Selector) {
+	return Order(pagination.SortKeys(sort)...)
+}
+
+// Order возвращает упорядочивание выборки по ключам сортировки keys. Положение пустых значений задается
+// NULLS FIRST/NULLS LAST, для MySQL, который их не поддерживает, - дополнительным упорядочиванием по признаку
+// пустого значения.
+func Order(keys ...pagination.SortKey) func(s *sql.Selector) {
+	return func(s *sql.Selector) {
+		for _, key := range keys {
+			s.OrderExprFunc(func(b *sql.Builder) {
+				column := s.C(key.Field)
+				emulateNulls := key.Nulls != pagination.NullsDefault && s.Dialect() == dialect.MySQL
+
+				if emulateNulls {
+					b.WriteString(column)
+					if key.Nulls == pagination.NullsFirst {
+						b.WriteString(" IS NOT NULL, ")
+					} else {
+						b.WriteString(" IS NULL, ")
+					}
+				}
+
+				b.WriteString(column)
+				if key.Direction == pagination.SortDesc {
+					b.WriteString(" DESC")
+				} else {
+					b.WriteString(" ASC")
+				}
+
+				if !emulateNulls {
+					switch key.Nulls {
+					case pagination.NullsFirst:
+						b.WriteString(" NULLS FIRST")
+					case pagination.NullsLast:
+						b.WriteString(" NULLS LAST")
+					}
+				}
+			})
+		}
+	}
+}
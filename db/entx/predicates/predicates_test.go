@@ -2,11 +2,15 @@ package predicates_test
 
 import (
 	"testing"
+	"time"
 
 	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/wal1251/pkg/core/presenters/filter"
+	"github.com/wal1251/pkg/core/presenters/pagination"
 	"github.com/wal1251/pkg/db/entx/predicates"
 )
 
@@ -76,3 +80,143 @@ func TestRegexpFold(t *testing.T) {
 		})
 	}
 }
+
+func TestKeyset(t *testing.T) {
+	keys := []pagination.SortKey{{Field: "name", Direction: pagination.SortDesc}, {Field: "id", Direction: pagination.SortAsc}}
+
+	tests := []struct {
+		name   string
+		keyset *pagination.Keyset
+		want   string
+		args   []any
+	}{
+		{
+			name:   "Первая страница",
+			keyset: &pagination.Keyset{Keys: keys, Limit: 10},
+			want:   "SELECT `id`, `name` FROM `sample` ORDER BY `sample`.`name` DESC, `sample`.`id` ASC",
+		},
+		{
+			name:   "Следующая страница",
+			keyset: &pagination.Keyset{Keys: keys, Values: []any{"foo", 5}, Limit: 10},
+			want: "SELECT `id`, `name` FROM `sample` WHERE `sample`.`name` < ? OR (`sample`.`name` = ? AND `sample`.`id` > ?) " +
+				"ORDER BY `sample`.`name` DESC, `sample`.`id` ASC",
+			args: []any{"foo", "foo", 5},
+		},
+		{
+			name:   "Предыдущая страница",
+			keyset: &pagination.Keyset{Keys: keys, Values: []any{"foo", 5}, Backward: true, Limit: 10},
+			want: "SELECT `id`, `name` FROM `sample` WHERE `sample`.`name` > ? OR (`sample`.`name` = ? AND `sample`.`id` < ?) " +
+				"ORDER BY `sample`.`name` ASC, `sample`.`id` DESC",
+			args: []any{"foo", "foo", 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := sql.Select("id", "name")
+			builder.From(sql.Table("sample"))
+
+			predicates.Keyset(tt.keyset)(builder)
+			predicates.KeysetOrder(tt.keyset)(builder)
+
+			query, args := builder.Query()
+			assert.Equal(t, tt.want, query)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	sorting, err := pagination.ParseSorting("-priority,created")
+	require.NoError(t, err)
+
+	sorting.WithNulls(pagination.NullsLast).WithMapping(map[string]string{"created": "created_at"})
+
+	tests := []struct {
+		name    string
+		dialect string
+		want    string
+	}{
+		{
+			name:    "Postgres",
+			dialect: dialect.Postgres,
+			want: `SELECT "id", "name" FROM "sample" ORDER BY "sample"."priority" DESC NULLS LAST, ` +
+				`"sample"."created_at" ASC NULLS LAST`,
+		},
+		{
+			name:    "MySQL",
+			dialect: dialect.MySQL,
+			want: "SELECT `id`, `name` FROM `sample` ORDER BY `sample`.`priority` IS NULL, `sample`.`priority` DESC, " +
+				"`sample`.`created_at` IS NULL, `sample`.`created_at` ASC",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := sql.Dialect(tt.dialect).Select("id", "name")
+			builder.From(sql.Table("sample"))
+
+			predicates.Sort(sorting)(builder)
+
+			query, _ := builder.Query()
+			assert.Equal(t, tt.want, query)
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	schema := filter.NewSchema(
+		filter.Field{Name: "status", Type: filter.TypeString},
+		filter.Field{Name: "name", Type: filter.TypeString, Operators: []filter.Operator{filter.OpLike, filter.OpRegex}},
+		filter.Field{Name: "created", Column: "created_at", Type: filter.TypeTime},
+		filter.Field{Name: "count", Type: filter.TypeInt},
+	)
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		dialect string
+		raw     string
+		want    string
+		args    []any
+	}{
+		{
+			name:    "Пустое выражение",
+			dialect: dialect.Postgres,
+			raw:     "",
+			want:    `SELECT "id", "name" FROM "sample"`,
+		},
+		{
+			name:    "Несколько условий",
+			dialect: dialect.Postgres,
+			raw:     "status:in:new,open;created:gte:2024-01-01;count:null:false;name:like:foo",
+			want: `SELECT "id", "name" FROM "sample" WHERE (("sample"."status" IN ($1, $2) ` +
+				`AND "sample"."created_at" >= $3) AND "sample"."count" IS NOT NULL) AND "sample"."name" ILIKE $4`,
+			args: []any{"new", "open", created, "%foo%"},
+		},
+		{
+			name:    "Регулярное выражение",
+			dialect: dialect.Postgres,
+			raw:     `count:ne:3;name:re:^foo`,
+			want:    `SELECT "id", "name" FROM "sample" WHERE "sample"."count" <> $1 AND "sample"."name" ~* $2`,
+			args:    []any{int64(3), "^foo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, err := schema.Parse(tt.raw)
+			require.NoError(t, err)
+
+			builder := sql.Dialect(tt.dialect).Select("id", "name")
+			builder.From(sql.Table("sample"))
+
+			predicates.Filter(expression)(builder)
+
+			query, args := builder.Query()
+			assert.Equal(t, tt.want, query)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
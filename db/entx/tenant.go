@@ -0,0 +1,84 @@
+package entx
+
+import (
+	"context"
+	"fmt"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/sql"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/security"
+)
+
+// TenantFilter запрос или мутация ent, поддерживающие условия на уровне sql.Selector (генерируются ent для всех
+// схем).
+type TenantFilter interface {
+	WhereP(...func(*sql.Selector))
+}
+
+// TenantInterceptor возвращает перехватчик запросов ent, ограничивающий выборку записями арендатора из контекста, см.
+// security.WithTenant. Если арендатор в контексте не указан, запрос отклоняется ошибкой errs.ErrForbidden. Проверку
+// можно отключить с помощью security.WithoutTenant.
+func TenantInterceptor() ent.Interceptor {
+	return ent.TraverseFunc(func(ctx context.Context, query ent.Query) error {
+		return whereTenant(ctx, query)
+	})
+}
+
+// TenantHook возвращает хук мутаций ent: создаваемые записи получают арендатора из контекста, изменение и удаление
+// ограничены записями арендатора. Попытка создать запись другого арендатора или изменить арендатора записи
+// отклоняется ошибкой errs.ErrForbidden.
+func TenantHook() ent.Hook {
+	return func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, mutation ent.Mutation) (ent.Value, error) {
+			if security.TenantSkipped(ctx) {
+				return next.Mutate(ctx, mutation)
+			}
+
+			tenantID, ok := security.TenantFromContext(ctx)
+			if !ok {
+				return nil, errs.Wrapf(errs.ErrForbidden, "tenant is not set")
+			}
+
+			value, isSet := mutation.Field(FieldTenantID)
+			if isSet && value != tenantID {
+				return nil, errs.Wrapf(errs.ErrForbidden, "cross-tenant access is not permitted")
+			}
+
+			if mutation.Op().Is(ent.OpCreate) {
+				if err := mutation.SetField(FieldTenantID, tenantID); err != nil {
+					return nil, fmt.Errorf("can't set tenant: %w", err)
+				}
+
+				return next.Mutate(ctx, mutation)
+			}
+
+			if err := whereTenant(ctx, mutation); err != nil {
+				return nil, err
+			}
+
+			return next.Mutate(ctx, mutation)
+		})
+	}
+}
+
+func whereTenant(ctx context.Context, target any) error {
+	if security.TenantSkipped(ctx) {
+		return nil
+	}
+
+	tenantID, ok := security.TenantFromContext(ctx)
+	if !ok {
+		return errs.Wrapf(errs.ErrForbidden, "tenant is not set")
+	}
+
+	filter, ok := target.(TenantFilter)
+	if !ok {
+		return fmt.Errorf("%T does not support tenant filtering", target) //nolint:goerr113
+	}
+
+	filter.WhereP(sql.FieldEQ(FieldTenantID, tenantID))
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package entx_test
+
+import (
+	"context"
+	"testing"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/db/entx"
+)
+
+type tenantQuery struct {
+	predicates []func(*sql.Selector)
+}
+
+func (q *tenantQuery) WhereP(predicates ...func(*sql.Selector)) {
+	q.predicates = append(q.predicates, predicates...)
+}
+
+func (q *tenantQuery) where() string {
+	selector := sql.Select("*").From(sql.Table("items"))
+	for _, predicate := range q.predicates {
+		predicate(selector)
+	}
+
+	query, _ := selector.Query()
+
+	return query
+}
+
+type tenantMutation struct {
+	ent.Mutation
+	tenantQuery
+	op     ent.Op
+	fields map[string]ent.Value
+}
+
+func (m *tenantMutation) Op() ent.Op {
+	return m.op
+}
+
+func (m *tenantMutation) Field(name string) (ent.Value, bool) {
+	value, ok := m.fields[name]
+
+	return value, ok
+}
+
+func (m *tenantMutation) SetField(name string, value ent.Value) error {
+	m.fields[name] = value
+
+	return nil
+}
+
+func TestTenantInterceptor(t *testing.T) {
+	traverser, ok := entx.TenantInterceptor().(ent.Traverser)
+	require.True(t, ok)
+
+	tests := []struct {
+		name      string
+		ctx       context.Context //nolint:containedctx
+		wantWhere string
+		wantErr   error
+	}{
+		{
+			name:      "Tenant",
+			ctx:       security.WithTenant(context.Background(), "acme"),
+			wantWhere: "SELECT * FROM `items` WHERE `items`.`tenant_id` = ?",
+		},
+		{
+			name:    "No tenant",
+			ctx:     context.Background(),
+			wantErr: errs.ErrForbidden,
+		},
+		{
+			name:      "Skipped",
+			ctx:       security.WithoutTenant(context.Background()),
+			wantWhere: "SELECT * FROM `items`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := &tenantQuery{}
+
+			err := traverser.Traverse(tt.ctx, query)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantWhere, query.where())
+		})
+	}
+}
+
+func TestTenantHook(t *testing.T) {
+	ctx := security.WithTenant(context.Background(), "acme")
+
+	tests := []struct {
+		name       string
+		op         ent.Op
+		fields     map[string]ent.Value
+		wantFields map[string]ent.Value
+		wantWhere  string
+		wantErr    error
+	}{
+		{
+			name:       "Create",
+			op:         ent.OpCreate,
+			fields:     map[string]ent.Value{},
+			wantFields: map[string]ent.Value{entx.FieldTenantID: "acme"},
+			wantWhere:  "SELECT * FROM `items`",
+		},
+		{
+			name:    "Create for other tenant",
+			op:      ent.OpCreate,
+			fields:  map[string]ent.Value{entx.FieldTenantID: "other"},
+			wantErr: errs.ErrForbidden,
+		},
+		{
+			name:       "Update",
+			op:         ent.OpUpdate,
+			fields:     map[string]ent.Value{},
+			wantFields: map[string]ent.Value{},
+			wantWhere:  "SELECT * FROM `items` WHERE `items`.`tenant_id` = ?",
+		},
+		{
+			name:       "Delete one",
+			op:         ent.OpDeleteOne,
+			fields:     map[string]ent.Value{},
+			wantFields: map[string]ent.Value{},
+			wantWhere:  "SELECT * FROM `items` WHERE `items`.`tenant_id` = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mutation := &tenantMutation{op: tt.op, fields: tt.fields}
+			called := false
+
+			mutator := entx.TenantHook()(ent.MutateFunc(func(context.Context, ent.Mutation) (ent.Value, error) {
+				called = true
+
+				return nil, nil
+			}))
+
+			_, err := mutator.Mutate(ctx, mutation)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.False(t, called)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, called)
+			assert.Equal(t, tt.wantFields, mutation.fields)
+			assert.Equal(t, tt.wantWhere, mutation.where())
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package grpcx
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/core/security"
+)
+
+const (
+	MetadataAuthorization = "authorization" // Ключ метаданных с данными авторизации.
+
+	bearerPrefix = "bearer "
+)
+
+type (
+	// CredentialsProvider извлекает аутентификационный запрос из контекста gRPC-запроса.
+	CredentialsProvider[CRED security.RequestCredentials] func(ctx context.Context) CRED
+
+	// RequirementsProvider возвращает требования безопасности для вызываемого метода gRPC.
+	RequirementsProvider func(ctx context.Context, fullMethod string) security.Requirements
+)
+
+// BearerTokenFromMetadata извлекает токен на предъявителя из входящих метаданных authorization.
+func BearerTokenFromMetadata(ctx context.Context) security.BearerToken {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(MetadataAuthorization)
+	if len(values) == 0 || !strings.HasPrefix(strings.ToLower(values[0]), bearerPrefix) {
+		return ""
+	}
+
+	return security.BearerToken(values[0][len(bearerPrefix):])
+}
+
+// ClientCertificateFromPeer извлекает сертификат клиента, проверенный при рукопожатии TLS (mTLS), см. SetOptions и
+// Config.TLSClientCAFile.
+func ClientCertificateFromPeer(ctx context.Context) security.ClientCertificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return security.ClientCertificate{}
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return security.ClientCertificate{}
+	}
+
+	return security.ClientCertificate{Leaf: info.State.VerifiedChains[0][0]}
+}
+
+// MethodRequirements возвращает RequirementsProvider, сопоставляющий полное имя метода gRPC с требованиями
+// безопасности. Для методов, отсутствующих в methods, требования берутся из manager.
+func MethodRequirements(manager security.Manager, methods map[string]security.Requirements) RequirementsProvider {
+	return func(ctx context.Context, fullMethod string) security.Requirements {
+		if requirements, ok := methods[fullMethod]; ok {
+			return requirements
+		}
+
+		return manager.SecurityRequirements(ctx)
+	}
+}
+
+// AuthorizerServerInterceptor аутентифицирует пользователя и проверяет доступ к методу gRPC аналогично mw.Authorizer.
+// Решение о доступе принимают checkers (по умолчанию security.AuthoritiesChecker) и записывается в журнал аудита.
+// Арендатор, переданный в метаданных, должен совпадать с арендатором пользователя, иначе вернет ошибку
+// errs.ErrForbidden. Пользователю без арендатора передавать арендатора запрещено: сервисные учетные записи, которым
+// разрешено действовать от имени арендатора, подключаются явно, см. TrustedTenantProvider. Обработчик получает
+// арендатора только из аутентификации пользователя.
+func AuthorizerServerInterceptor[T security.RequestCredentials](
+	requirementsProvider RequirementsProvider,
+	credentialsProvider CredentialsProvider[T],
+	authProvider security.AuthenticationProvider[T],
+	checkers ...security.AccessChecker,
+) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		logger := logs.FromContext(ctx)
+
+		requirements := requirementsProvider(ctx, info.FullMethod)
+
+		authentication, err := authProvider.Authenticate(ctx, credentialsProvider(ctx))
+		if err != nil {
+			if !requirements.IsAuthorizationRequired {
+				return handler(security.WithTenant(ctx, ""), req)
+			}
+
+			logger.Warn().Msg("invalid access token credentials")
+
+			return nil, NewGrpcError(errs.With(errs.ErrAuthFailure, err))
+		}
+
+		// Арендатор, переданный вызывающим сервисом, должен совпадать с арендатором пользователя
+		if propagated, ok := security.TenantFromContext(ctx); ok && propagated != authentication.TenantID {
+			logger.Warn().Msg("tenant of the request does not match tenant of the user")
+
+			return nil, NewGrpcError(errs.Wrapf(errs.ErrForbidden, "cross-tenant access is not permitted"))
+		}
+
+		decision := security.Decide(ctx, authentication, requirements, checkers...)
+		security.Audit(ctx, authentication, decision)
+
+		if !decision.Granted {
+			return nil, NewGrpcError(decision.Err())
+		}
+
+		return handler(authentication.ToContext(ctx), req)
+	}
+}
+
+// TrustedTenantProvider оборачивает authProvider: пользователю без арендатора, обладающему полномочием authority
+// (например, доверенному сервису), назначается арендатор, переданный в метаданных запроса. Остальные пользователи
+// аутентифицируются без изменений, см. AuthorizerServerInterceptor.
+func TrustedTenantProvider[T security.RequestCredentials](
+	authProvider security.AuthenticationProvider[T],
+	authority security.Authority,
+) security.AuthenticationProvider[T] {
+	return security.AuthenticationProviderFn[T](func(ctx context.Context, credentials T) (security.Authentication, error) {
+		authentication, err := authProvider.Authenticate(ctx, credentials)
+		if err != nil {
+			return authentication, err //nolint:wrapcheck
+		}
+
+		if authority != "" && authentication.TenantID == "" &&
+			authentication.Authorities.Meets(security.Authorities{authority}) {
+			authentication.TenantID, _ = security.TenantFromContext(ctx)
+		}
+
+		return authentication, nil
+	})
+}
@@ -0,0 +1,135 @@
+package grpcx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/security"
+)
+
+type tokenProviderStub map[security.BearerToken]security.Authentication
+
+func (s tokenProviderStub) Authenticate(_ context.Context, token security.BearerToken) (security.Authentication, error) {
+	if auth, ok := s[token]; ok {
+		return auth, nil
+	}
+
+	return security.Authentication{}, errs.ErrAuthFailure
+}
+
+func TestAuthorizerServerInterceptor(t *testing.T) {
+	provider := tokenProviderStub{
+		"admin": {User: security.User{ID: uuid.New()}, Authorities: security.Authorities{"admin"}},
+		"user":  {User: security.User{ID: uuid.New()}, Authorities: security.Authorities{"user"}},
+		"acme":  {User: security.User{ID: uuid.New()}, Authorities: security.Authorities{"admin"}, TenantID: "acme"},
+		"svc":   {User: security.User{ID: uuid.New()}, Authorities: security.Authorities{"admin", "service"}},
+	}
+
+	interceptor := AuthorizerServerInterceptor[security.BearerToken](
+		MethodRequirements(security.DefaultManager{}, map[string]security.Requirements{
+			"/test/Admin": {IsAuthorizationRequired: true, Authorities: security.Authorities{"admin"}},
+		}),
+		BearerTokenFromMetadata,
+		provider,
+	)
+
+	tests := []struct {
+		name     string
+		method   string
+		token    string
+		tenant   string
+		wantCode codes.Code
+	}{
+		{name: "Granted", method: "/test/Admin", token: "admin", wantCode: codes.OK},
+		{name: "Forbidden", method: "/test/Admin", token: "user", wantCode: codes.PermissionDenied},
+		{name: "Unauthenticated", method: "/test/Admin", wantCode: codes.Unauthenticated},
+		{name: "Public method", method: "/test/Public", wantCode: codes.OK},
+		{name: "Same tenant", method: "/test/Admin", token: "acme", tenant: "acme", wantCode: codes.OK},
+		{name: "Cross-tenant", method: "/test/Admin", token: "acme", tenant: "other", wantCode: codes.PermissionDenied},
+		{name: "Tenant-less user with foreign tenant", method: "/test/Admin", token: "admin", tenant: "other", wantCode: codes.PermissionDenied},
+		{name: "Untrusted service with foreign tenant", method: "/test/Admin", token: "svc", tenant: "other", wantCode: codes.PermissionDenied},
+		{name: "Anonymous public call with tenant", method: "/test/Public", tenant: "other", wantCode: codes.OK},
+		{name: "Tenant-less public call with foreign tenant", method: "/test/Public", token: "user", tenant: "other", wantCode: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(MetadataAuthorization, "Bearer "+tt.token))
+			}
+
+			if tt.tenant != "" {
+				ctx = security.WithTenant(ctx, tt.tenant)
+			}
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(ctx context.Context, _ interface{}) (interface{}, error) {
+					if tt.token != "" {
+						assert.Equal(t, provider[security.BearerToken(tt.token)], security.DefaultManager{}.Authorized(ctx))
+					}
+
+					tenant, _ := security.TenantFromContext(ctx)
+					assert.Equal(t, provider[security.BearerToken(tt.token)].TenantID, tenant)
+
+					return nil, nil
+				})
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestTrustedTenantProvider(t *testing.T) {
+	provider := tokenProviderStub{
+		"svc":  {User: security.User{ID: uuid.New()}, Authorities: security.Authorities{"service"}},
+		"user": {User: security.User{ID: uuid.New()}, Authorities: security.Authorities{"user"}},
+		"acme": {User: security.User{ID: uuid.New()}, Authorities: security.Authorities{"service"}, TenantID: "acme"},
+	}
+
+	interceptor := AuthorizerServerInterceptor[security.BearerToken](
+		MethodRequirements(security.DefaultManager{}, nil),
+		BearerTokenFromMetadata,
+		TrustedTenantProvider[security.BearerToken](provider, "service"),
+	)
+
+	tests := []struct {
+		name       string
+		token      string
+		tenant     string
+		wantTenant string
+		wantCode   codes.Code
+	}{
+		{name: "Trusted service acts for tenant", token: "svc", tenant: "other", wantTenant: "other", wantCode: codes.OK},
+		{name: "Trusted service without tenant", token: "svc", wantCode: codes.OK},
+		{name: "Untrusted user", token: "user", tenant: "other", wantCode: codes.PermissionDenied},
+		{name: "Service tenant is not replaced", token: "acme", tenant: "other", wantCode: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataAuthorization, "Bearer "+tt.token))
+			if tt.tenant != "" {
+				ctx = security.WithTenant(ctx, tt.tenant)
+			}
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test/Method"},
+				func(ctx context.Context, _ interface{}) (interface{}, error) {
+					tenant, _ := security.TenantFromContext(ctx)
+					assert.Equal(t, tt.wantTenant, tenant)
+
+					return nil, nil
+				})
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
+
+	"github.com/wal1251/pkg/core/security/mtls"
 )
 
 const maxCallRecvMsgSize = 1024 * 1024 * 20
@@ -24,15 +26,16 @@ func ConnectServer(cfg *Config) (*grpc.ClientConn, error) {
 		grpc.WithUnaryInterceptor(UserInfoClientInterceptor()),
 	}
 
-	// Настройка TLS, если предоставлены сертификаты
+	// Настройка TLS, если предоставлены сертификаты. При указании TLSClientCertFile серверу предъявляется сертификат
+	// клиента (mTLS), сертификаты перечитываются при изменении файлов.
 	if cfg.TLSCertFile != "" {
-		creds, err := credentials.NewClientTLSFromFile(cfg.TLSCertFile, "")
+		tlsConfig, err := mtls.ClientTLSConfig(cfg.ClientTLS(), cfg.Host)
 		if err != nil {
 			log.Error().Msgf("не удалось загрузить TLS сертификаты: %v", err)
 
 			return nil, fmt.Errorf("не удалось загрузить TLS сертификаты: %w", err)
 		}
-		opts = append(opts, grpc.WithTransportCredentials(creds))
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	} else {
 		// Если TLS не настроен, подключаемся небезопасно
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
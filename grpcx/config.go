@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/wal1251/pkg/core/cfg"
+	"github.com/wal1251/pkg/core/security/mtls"
 )
 
 const (
@@ -20,6 +21,11 @@ const (
 	CfgKeyMaxSendMsgSize        cfg.Key = "GRPC_SERVER_MAX_SEND_MSG_SIZE"
 	CfgKeyTLSCertFile           cfg.Key = "GRPC_SERVER_TLS_CERT_FILE"
 	CfgKeyTLSKeyFile            cfg.Key = "GRPC_SERVER_TLS_KEY_FILE"
+	CfgKeyTLSClientCAFile       cfg.Key = "GRPC_SERVER_TLS_CLIENT_CA_FILE"  // Доверенные центры сертификатов клиентов (PEM).
+	CfgKeyTLSClientAuth         cfg.Key = "GRPC_SERVER_TLS_CLIENT_AUTH"     // Проверка сертификата клиента: none, optional, require.
+	CfgKeyTLSReloadInterval     cfg.Key = "GRPC_SERVER_TLS_RELOAD_INTERVAL" // Интервал проверки изменения файлов сертификатов.
+	CfgKeyTLSClientCertFile     cfg.Key = "GRPC_CLIENT_TLS_CERT_FILE"       // Сертификат клиента для ConnectServer (PEM).
+	CfgKeyTLSClientKeyFile      cfg.Key = "GRPC_CLIENT_TLS_KEY_FILE"        // Закрытый ключ клиента для ConnectServer (PEM).
 
 	CfgDefaultHost                  = "localhost"
 	CfgDefaultHostKube              = "0.0.0.0"
@@ -49,6 +55,11 @@ type Config struct {
 	MaxSendMsgSize        int
 	TLSCertFile           string
 	TLSKeyFile            string
+	TLSClientCAFile       string        // Доверенные центры сертификатов клиентов, включает mTLS на сервере.
+	TLSClientAuth         string        // Проверка сертификата клиента: none, optional или require, см. mtls.ParseClientAuth.
+	TLSReloadInterval     time.Duration // Интервал проверки изменения файлов сертификатов.
+	TLSClientCertFile     string        // Сертификат, который ConnectServer предъявляет серверу.
+	TLSClientKeyFile      string        // Закрытый ключ сертификата, который ConnectServer предъявляет серверу.
 	ServiceID             int
 }
 
@@ -60,3 +71,25 @@ func (c Config) Addr() string {
 func (c Config) AddrServer() string {
 	return net.JoinHostPort(CfgDefaultHostKube, c.Port)
 }
+
+// ServerTLS возвращает конфигурацию TLS сервера.
+func (c Config) ServerTLS() mtls.Config {
+	return mtls.Config{
+		CertFile:       c.TLSCertFile,
+		KeyFile:        c.TLSKeyFile,
+		CAFile:         c.TLSClientCAFile,
+		ClientAuth:     c.TLSClientAuth,
+		ReloadInterval: c.TLSReloadInterval,
+	}
+}
+
+// ClientTLS возвращает конфигурацию TLS клиента: TLSCertFile используется как сертификат доверенного центра сервера,
+// TLSClientCertFile и TLSClientKeyFile - как сертификат клиента.
+func (c Config) ClientTLS() mtls.Config {
+	return mtls.Config{
+		CertFile:       c.TLSClientCertFile,
+		KeyFile:        c.TLSClientKeyFile,
+		CAFile:         c.TLSCertFile,
+		ReloadInterval: c.TLSReloadInterval,
+	}
+}
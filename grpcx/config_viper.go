@@ -5,6 +5,7 @@ import (
 
 	"github.com/wal1251/pkg/core/cfg"
 	"github.com/wal1251/pkg/core/cfg/viperx"
+	"github.com/wal1251/pkg/core/security/mtls"
 )
 
 func CfgFromViper(v *viper.Viper, keyMapping ...cfg.KeyMap) *Config {
@@ -21,5 +22,10 @@ func CfgFromViper(v *viper.Viper, keyMapping ...cfg.KeyMap) *Config {
 		MaxSendMsgSize:        viperx.Get(v, CfgKeyMaxSendMsgSize.Map(keyMapping...), CfgDefaultMaxSendMsgSize),
 		TLSCertFile:           viperx.Get(v, CfgKeyTLSCertFile.Map(keyMapping...), CfgDefaultTLSCertFile),
 		TLSKeyFile:            viperx.Get(v, CfgKeyTLSKeyFile.Map(keyMapping...), CfgDefaultTLSKeyFile),
+		TLSClientCAFile:       viperx.Get(v, CfgKeyTLSClientCAFile.Map(keyMapping...), ""),
+		TLSClientAuth:         viperx.Get(v, CfgKeyTLSClientAuth.Map(keyMapping...), ""),
+		TLSReloadInterval:     viperx.Get(v, CfgKeyTLSReloadInterval.Map(keyMapping...), mtls.CfgDefaultReloadInterval),
+		TLSClientCertFile:     viperx.Get(v, CfgKeyTLSClientCertFile.Map(keyMapping...), ""),
+		TLSClientKeyFile:      viperx.Get(v, CfgKeyTLSClientKeyFile.Map(keyMapping...), ""),
 	}
 }
@@ -13,11 +13,12 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
+	"github.com/wal1251/pkg/core/ctxs"
 	"github.com/wal1251/pkg/core/errs"
 )
 
 // UserInfoKey - ключ для хранения информации о пользователе в контексте.
-const UserInfoKey = "userInfo"
+const UserInfoKey = ctxs.UserInfoKey
 
 // UserInfoClientInterceptor добавляет информацию о пользователе в метаданные gRPC-запроса.
 func UserInfoClientInterceptor() grpc.UnaryClientInterceptor {
@@ -34,9 +35,9 @@ func UserInfoClientInterceptor() grpc.UnaryClientInterceptor {
 		if userInfo != nil {
 			claims, ok := userInfo.(map[string]interface{})
 			if ok {
-				md := metadata.New(nil)
+				md := outgoingMetadata(ctx)
 				for key, value := range claims {
-					md.Append(key, fmt.Sprintf("%v", value))
+					md.Set(key, fmt.Sprintf("%v", value))
 				}
 				ctx = metadata.NewOutgoingContext(ctx, md)
 			}
@@ -196,9 +197,8 @@ func AcceptLanguageClientInterceptor() grpc.UnaryClientInterceptor {
 		if acceptLanguage != nil {
 			alSubtag, ok := acceptLanguage.(string)
 			if ok {
-				md := metadata.New(map[string]string{
-					"accept_language": alSubtag,
-				})
+				md := outgoingMetadata(ctx)
+				md.Set("accept_language", alSubtag)
 				ctx = metadata.NewOutgoingContext(ctx, md)
 			}
 		}
@@ -206,3 +206,56 @@ func AcceptLanguageClientInterceptor() grpc.UnaryClientInterceptor {
 		return invoker(ctx, method, req, reply, clientConn, opts...)
 	}
 }
+
+// PropagationClientInterceptor добавляет в метаданные gRPC-запроса значения контекста всех распространителей,
+// зарегистрированных в ctxs.DefaultPropagators. Имеющиеся исходящие метаданные сохраняются.
+func PropagationClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		clientConn *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		return invoker(ctxs.InjectOutgoingMetadata(ctx), method, req, reply, clientConn, opts...)
+	}
+}
+
+// PropagationServerInterceptor извлекает из метаданных gRPC-запроса значения контекста всех распространителей,
+// зарегистрированных в ctxs.DefaultPropagators.
+func PropagationServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		return handler(ctxs.ExtractIncomingMetadata(ctx), req)
+	}
+}
+
+// outgoingMetadata возвращает копию исходящих метаданных контекста, чтобы дополнить их, а не перезаписать.
+func outgoingMetadata(ctx context.Context) metadata.MD {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		return md.Copy()
+	}
+
+	return metadata.MD{}
+}
+
+// DeadlineServerInterceptor сокращает крайний срок обработки gRPC-запроса, оставляя долю reserve оставшегося времени
+// на формирование ответа. Нижестоящие вызовы получат сокращенный бюджет. См. ctxs.WithBudget.
+func DeadlineServerInterceptor(reserve float64) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx, cancel := ctxs.WithBudget(ctx, reserve)
+		defer cancel()
+
+		return handler(ctx, req)
+	}
+}
@@ -222,3 +222,25 @@ func TestAcceptLanguageClientInterceptor(t *testing.T) {
 	err := interceptor(ctx, "/test/method", nil, nil, nil, invoker)
 	assert.NoError(t, err, "interceptor should not return an error")
 }
+
+func TestClientInterceptors_keepMetadata(t *testing.T) {
+	ctx := context.WithValue(context.Background(), UserInfoKey, map[string]interface{}{"user_id": "12345"})
+	ctx = context.WithValue(ctx, acceptlanguage.AcceptLanguageKey, "kk")
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-request-id", "req-1")
+
+	invoker := func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, ok := metadata.FromOutgoingContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"req-1"}, md.Get("x-request-id"))
+		assert.Equal(t, []string{"12345"}, md.Get("user_id"))
+		assert.Equal(t, []string{"kk"}, md.Get("accept_language"))
+
+		return nil
+	}
+
+	chained := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return AcceptLanguageClientInterceptor()(ctx, method, req, reply, cc, invoker, opts...)
+	}
+
+	assert.NoError(t, UserInfoClientInterceptor()(ctx, "/test/method", nil, nil, nil, chained))
+}
@@ -15,6 +15,7 @@ import (
 	"google.golang.org/grpc/reflection"
 
 	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/core/security/mtls"
 )
 
 func StartServer(ctx context.Context, cfg *Config, server *grpc.Server) error {
@@ -88,15 +89,16 @@ func SetOptions(cfg *Config) ([]grpc.ServerOption, error) {
 		),
 	)
 
-	// Настраиваем TLS, если предоставлены сертификаты
-	if cfg.TLSCertFile != "" && cfg.TLSKeyFile != "" {
-		creds, err := credentials.NewServerTLSFromFile(cfg.TLSCertFile, cfg.TLSKeyFile)
+	// Настраиваем TLS, если предоставлены сертификаты. Сертификаты перечитываются при изменении файлов, при указании
+	// TLSClientCAFile проверяются сертификаты клиентов (mTLS).
+	if cfg.ServerTLS().Enabled() {
+		tlsConfig, err := mtls.ServerTLSConfig(cfg.ServerTLS())
 		if err != nil {
 			log.Error().Msg("failed to load TLS credentials")
 
 			return nil, fmt.Errorf("failed to load TLS credentials: %w", err)
 		}
-		serverOptions = append(serverOptions, grpc.Creds(creds))
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
 	}
 
 	return serverOptions, nil
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/wal1251/pkg/core/cfg"
+	"github.com/wal1251/pkg/core/security/mtls"
 )
 
 const (
@@ -19,6 +20,11 @@ const (
 	CfgKeyCORSHeaders     cfg.Key = "HTTP_SERVER_CORS_HEADERS"
 	CfgKeyCORSCredentials cfg.Key = "HTTP_SERVER_CORS_CREDENTIALS" //nolint:gosec
 	CfgKeyFrontMinVersion cfg.Key = "HTTP_SERVER_FRONT_MIN_VERSION"
+	CfgKeyTLSCertFile     cfg.Key = "HTTP_SERVER_TLS_CERT_FILE"       // Сертификат сервера (PEM).
+	CfgKeyTLSKeyFile      cfg.Key = "HTTP_SERVER_TLS_KEY_FILE"        // Закрытый ключ сервера (PEM).
+	CfgKeyTLSClientCAFile cfg.Key = "HTTP_SERVER_TLS_CLIENT_CA_FILE"  // Доверенные центры сертификатов клиентов (PEM).
+	CfgKeyTLSClientAuth   cfg.Key = "HTTP_SERVER_TLS_CLIENT_AUTH"     // Проверка сертификата клиента: none, optional, require.
+	CfgKeyTLSReload       cfg.Key = "HTTP_SERVER_TLS_RELOAD_INTERVAL" // Интервал проверки изменения файлов сертификатов.
 
 	CfgDefaultPort              = "8080"
 	CfgDefaultReadHeaderTimeout = 30 * time.Second
@@ -41,6 +47,7 @@ type Config struct {
 	Rate              RateLimit
 	CORS              CORSConfig
 	FrontMinVersion   string
+	TLS               mtls.Config
 }
 
 type RateLimit struct {
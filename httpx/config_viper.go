@@ -5,6 +5,7 @@ import (
 
 	"github.com/wal1251/pkg/core/cfg"
 	"github.com/wal1251/pkg/core/cfg/viperx"
+	"github.com/wal1251/pkg/core/security/mtls"
 )
 
 func CfgFromViper(v *viper.Viper, keyMapping ...cfg.KeyMap) *Config {
@@ -25,5 +26,12 @@ func CfgFromViper(v *viper.Viper, keyMapping ...cfg.KeyMap) *Config {
 			Credentials: viperx.Get(v, CfgKeyCORSCredentials.Map(keyMapping...), false),
 		},
 		FrontMinVersion: viperx.Get(v, CfgKeyFrontMinVersion.Map(keyMapping...), ""),
+		TLS: mtls.Config{
+			CertFile:       viperx.Get(v, CfgKeyTLSCertFile.Map(keyMapping...), ""),
+			KeyFile:        viperx.Get(v, CfgKeyTLSKeyFile.Map(keyMapping...), ""),
+			CAFile:         viperx.Get(v, CfgKeyTLSClientCAFile.Map(keyMapping...), ""),
+			ClientAuth:     viperx.Get(v, CfgKeyTLSClientAuth.Map(keyMapping...), ""),
+			ReloadInterval: viperx.Get(v, CfgKeyTLSReload.Map(keyMapping...), mtls.CfgDefaultReloadInterval),
+		},
 	}
 }
@@ -8,6 +8,8 @@ import (
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc/metadata"
+
+	"github.com/wal1251/pkg/core/ctxs"
 )
 
 const (
@@ -42,5 +44,46 @@ func AnnotateContext(req *http.Request) context.Context {
 	}
 	pairs = append(pairs, strings.ToLower(xRequestID), reqID)
 
-	return metadata.NewOutgoingContext(req.Context(), metadata.Pairs(pairs...))
+	md, ok := metadata.FromOutgoingContext(req.Context())
+	if !ok {
+		md = metadata.MD{}
+	}
+
+	for i := 0; i < len(pairs); i += 2 {
+		md.Set(pairs[i], pairs[i+1])
+	}
+
+	return metadata.NewOutgoingContext(req.Context(), md)
+}
+
+// InjectHeader записывает в заголовки значения контекста всех распространителей, зарегистрированных в
+// ctxs.DefaultPropagators.
+func InjectHeader(ctx context.Context, header http.Header) {
+	ctxs.Inject(ctx, ctxs.HeaderCarrier(header))
+}
+
+// ExtractHeader возвращает контекст, дополненный значениями из заголовков, см. ctxs.Extract.
+func ExtractHeader(ctx context.Context, header http.Header) context.Context {
+	return ctxs.Extract(ctx, ctxs.HeaderCarrier(header))
+}
+
+// PropagationTransport возвращает http.RoundTripper, который добавляет в заголовки исходящего запроса значения
+// контекста запроса. Если base не указан, используется http.DefaultTransport.
+func PropagationTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return roundTripperFn(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		InjectHeader(req.Context(), req.Header)
+
+		return base.RoundTrip(req)
+	})
+}
+
+type roundTripperFn func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFn) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
 }
@@ -6,6 +6,7 @@ package httpx
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -18,6 +19,11 @@ import (
 const (
 	HeaderAuthorization = "Authorization"
 	HeaderContentType   = "Content-Type"
+	HeaderAPIKey        = "X-API-Key"
+	HeaderCSRFToken     = "X-CSRF-Token"
+	HeaderRetryAfter    = "Retry-After"
+	HeaderLink          = "Link"
+	HeaderTotalCount    = "X-Total-Count"
 
 	ContentTypeJSON = "application/json"
 	ContentTypeXML  = "application/xml"
@@ -25,9 +31,12 @@ const (
 )
 
 var (
-	_ presenters.StringViewer                                = Header{}
-	_ presenters.StringViewer                                = (*Request)(nil)
-	_ security.HTTPCredentialsProvider[security.BearerToken] = BearerTokenExtract
+	_ presenters.StringViewer                                      = Header{}
+	_ presenters.StringViewer                                      = (*Request)(nil)
+	_ security.HTTPCredentialsProvider[security.BearerToken]       = BearerTokenExtract
+	_ security.HTTPCredentialsProvider[security.APIKey]            = APIKeyExtract
+	_ security.HTTPCredentialsProvider[security.Credentials]       = CredentialsExtract
+	_ security.HTTPCredentialsProvider[security.ClientCertificate] = ClientCertificateExtract
 )
 
 type (
@@ -55,10 +64,11 @@ func (h Header) HasXMLContent() bool {
 
 func (h Header) StringView(view presenters.ViewType, opts presenters.ViewOptions) string {
 	pairs := make(map[string]string)
+	redactor := presenters.NewRedactor(opts)
 
 	for key, list := range h {
 		s := strings.Join(list, "|")
-		if view == presenters.ViewLogs && key == HeaderAuthorization {
+		if view == presenters.ViewLogs && (key == HeaderAuthorization || key == HeaderAPIKey || redactor.IsSecret(key)) {
 			s = "*"
 		}
 
@@ -68,12 +78,13 @@ func (h Header) StringView(view presenters.ViewType, opts presenters.ViewOptions
 	return presenters.ParameterView(pairs, view, opts)
 }
 
-func (h Header) InterfaceView(view presenters.ViewType, _ presenters.ViewOptions) any {
+func (h Header) InterfaceView(view presenters.ViewType, opts presenters.ViewOptions) any {
 	pairs := make(map[string]string)
+	redactor := presenters.NewRedactor(opts)
 
 	for key, list := range h {
 		s := strings.Join(list, "|")
-		if view == presenters.ViewLogs && key == HeaderAuthorization {
+		if view == presenters.ViewLogs && (key == HeaderAuthorization || key == HeaderAPIKey || redactor.IsSecret(key)) {
 			s = "*"
 		}
 
@@ -122,6 +133,48 @@ func BearerTokenExtract(r *http.Request) security.BearerToken {
 	return ""
 }
 
+// APIKeyExtract извлекает ключ API из заголовка X-API-Key.
+func APIKeyExtract(r *http.Request) security.APIKey {
+	return security.APIKey(strings.TrimSpace(r.Header.Get(HeaderAPIKey)))
+}
+
+// ClientCertificateExtract извлекает сертификат клиента, проверенный при рукопожатии TLS (mTLS), см. NewTLSServer.
+func ClientCertificateExtract(r *http.Request) security.ClientCertificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return security.ClientCertificate{}
+	}
+
+	return security.ClientCertificate{Leaf: r.TLS.VerifiedChains[0][0]}
+}
+
+// CredentialsExtract извлекает логин и пароль из заголовка Authorization (схема Basic) или из JSON тела запроса вида
+// {"login": "...", "password": "..."}. Тело запроса остается доступным для чтения.
+func CredentialsExtract(r *http.Request) security.Credentials {
+	if login, password, ok := r.BasicAuth(); ok {
+		return security.Credentials{Login: login, Password: security.Password(password)}
+	}
+
+	if r.Body == nil || !Header(r.Header).HasJSONContent() {
+		return security.Credentials{}
+	}
+
+	body, err := (*Request)(r).ReadBody()
+	if err != nil {
+		return security.Credentials{}
+	}
+
+	var credentials struct {
+		Login    string `json:"login"`
+		Password string `json:"password"`
+	}
+
+	if err = json.Unmarshal(body, &credentials); err != nil {
+		return security.Credentials{}
+	}
+
+	return security.Credentials{Login: credentials.Login, Password: security.Password(credentials.Password)}
+}
+
 func BearerTokenSet(r *http.Request, token security.BearerToken) {
 	r.Header.Set(HeaderAuthorization, fmt.Sprintf("%s %s", BearerKeyword, token))
 }
@@ -3,6 +3,7 @@ package httpx_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -51,3 +52,47 @@ func TestBearerTokenAuthorizer(t *testing.T) {
 		})
 	}
 }
+
+func TestCredentialsExtract(t *testing.T) {
+	basic := httptest.NewRequest("POST", "/login", nil)
+	basic.SetBasicAuth("john", "secret")
+
+	jsonBody := func(contentType, body string) *http.Request {
+		r := httptest.NewRequest("POST", "/login", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		return r
+	}
+
+	tests := []struct {
+		name string
+		r    *http.Request
+		want security.Credentials
+	}{
+		{
+			name: "Basic authorization",
+			r:    basic,
+			want: security.Credentials{Login: "john", Password: "secret"},
+		},
+		{
+			name: "JSON body",
+			r:    jsonBody("application/json", `{"login":"john","password":"secret"}`),
+			want: security.Credentials{Login: "john", Password: "secret"},
+		},
+		{
+			name: "Not a JSON body",
+			r:    jsonBody("text/plain", `{"login":"john","password":"secret"}`),
+			want: security.Credentials{},
+		},
+		{
+			name: "Malformed JSON body",
+			r:    jsonBody("application/json", `{"login":`),
+			want: security.Credentials{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, httpx.CredentialsExtract(tt.r))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/core/security"
+)
+
+// LogLevel представление уровня логирования.
+type LogLevel struct {
+	Level string `json:"level"`
+}
+
+// LogLevelHandler возвращает обработчик для управления уровнем логирования level без перезапуска приложения (см.
+// logs.LoggerWithLevel). На GET запрос вернет текущий уровень логирования, на запросы с другими методами установит
+// уровень, переданный в теле запроса, например {"level": "debug"}. Обработчик должен быть защищен посредником
+// аутентификации (например, mw.Authorizer), доступ разрешен только аутентифицированному пользователю с полномочиями
+// authorities. Если полномочия не указаны, возвращает ошибку errs.ErrIllegalArgument.
+func LogLevelHandler(
+	level *logs.LevelVar,
+	authManager security.Manager,
+	authorities ...security.Authority,
+) (http.HandlerFunc, error) {
+	if len(authorities) == 0 {
+		return nil, errs.Wrapf(errs.ErrIllegalArgument, "log level handler requires at least one authority")
+	}
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		NewServerHandler[LogLevel, LogLevel](writer, request).
+			WithResponseJSON().
+			WithMethod(func(ctx context.Context, req LogLevel) (LogLevel, error) {
+				auth := authManager.Authorized(ctx)
+				if auth.User.ID == uuid.Nil {
+					return LogLevel{}, errs.Wrapf(errs.ErrAuthFailure, "authentication required")
+				}
+
+				if !auth.Authorities.Meets(authorities) {
+					return LogLevel{}, errs.Wrapf(errs.ErrForbidden, "requested action is not permitted for current user")
+				}
+
+				if request.Method != http.MethodGet {
+					if err := level.Set(req.Level); err != nil {
+						return LogLevel{}, errs.Wrapf(errs.ErrIllegalArgument, "%v", err)
+					}
+
+					logs.FromContext(ctx).Warn().Msgf("log level changed to %s by %s", level.Level(), auth.User.Name)
+				}
+
+				return LogLevel{Level: level.Level().String()}, nil
+			}).
+			Handle()
+	}, nil
+}
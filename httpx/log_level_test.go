@@ -0,0 +1,127 @@
+package httpx_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/httpx"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	admin := security.Authentication{
+		User:        security.User{ID: uuid.New(), Name: "admin"},
+		Authorities: security.Authorities{"admin"},
+	}
+	user := security.Authentication{
+		User:        security.User{ID: uuid.New(), Name: "user"},
+		Authorities: security.Authorities{"user"},
+	}
+
+	tests := []struct {
+		name       string
+		auth       *security.Authentication
+		method     string
+		body       string
+		wantStatus int
+		wantLevel  zerolog.Level
+	}{
+		{
+			name:       "Get current level",
+			auth:       &admin,
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantLevel:  zerolog.InfoLevel,
+		},
+		{
+			name:       "Set level",
+			auth:       &admin,
+			method:     http.MethodPut,
+			body:       `{"level":"debug"}`,
+			wantStatus: http.StatusOK,
+			wantLevel:  zerolog.DebugLevel,
+		},
+		{
+			name:       "Invalid level",
+			auth:       &admin,
+			method:     http.MethodPut,
+			body:       `{"level":"foo"}`,
+			wantStatus: http.StatusBadRequest,
+			wantLevel:  zerolog.InfoLevel,
+		},
+		{
+			name:       "Not authenticated",
+			method:     http.MethodPut,
+			body:       `{"level":"debug"}`,
+			wantStatus: http.StatusUnauthorized,
+			wantLevel:  zerolog.InfoLevel,
+		},
+		{
+			name:       "Not permitted",
+			auth:       &user,
+			method:     http.MethodPut,
+			body:       `{"level":"debug"}`,
+			wantStatus: http.StatusForbidden,
+			wantLevel:  zerolog.InfoLevel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level := logs.NewLevelVar(zerolog.InfoLevel)
+			handler, err := httpx.LogLevelHandler(level, security.DefaultManager{}, "admin")
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			if tt.auth != nil {
+				ctx = tt.auth.ToContext(ctx)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, "/log-level", bytes.NewBufferString(tt.body)).WithContext(ctx)
+			if tt.body != "" {
+				r.Header.Set(httpx.HeaderContentType, httpx.ContentTypeJSON)
+			}
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantLevel, level.Level())
+		})
+	}
+}
+
+func TestLogLevelHandler_noAuthorities(t *testing.T) {
+	_, err := httpx.LogLevelHandler(logs.NewLevelVar(zerolog.InfoLevel), security.DefaultManager{})
+	assert.ErrorIs(t, err, errs.ErrIllegalArgument)
+}
+
+func TestLoggerWithLevel(t *testing.T) {
+	level := logs.NewLevelVar(zerolog.InfoLevel)
+	logger := logs.LoggerWithLevel(&logs.Config{}, level)
+
+	// Уровень других логеров не зависит от уровня, измененного во время работы, и от их конфигурации.
+	traceLogger := logs.Logger(&logs.Config{Level: zerolog.LevelTraceValue})
+	errorLogger := logs.Logger(&logs.Config{Level: zerolog.LevelErrorValue})
+
+	assert.Nil(t, logger.Debug(), "disabled event must not be created")
+	assert.NotNil(t, logger.Info())
+	assert.NotNil(t, traceLogger.Trace())
+	assert.Nil(t, errorLogger.Warn())
+
+	level.SetLevel(zerolog.DebugLevel)
+
+	assert.NotNil(t, logger.Debug())
+	assert.Nil(t, errorLogger.Warn())
+	assert.Equal(t, zerolog.GlobalLevel(), zerolog.TraceLevel, "global level must not be changed")
+}
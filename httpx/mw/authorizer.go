@@ -4,18 +4,23 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/wal1251/pkg/core/ctxs"
 	"github.com/wal1251/pkg/core/errs"
 	"github.com/wal1251/pkg/core/logs"
 	"github.com/wal1251/pkg/core/security"
 	"github.com/wal1251/pkg/httpx"
 )
 
-const UserInfoKey = "userInfo"
+const UserInfoKey = ctxs.UserInfoKey
 
+// Authorizer аутентифицирует пользователя и проверяет доступ к операции. Решение о доступе принимают checkers (по
+// умолчанию security.AuthoritiesChecker), например, security.PolicyEngine, и записывается в журнал аудита. IP адрес
+// клиента добавляется в контекст (см. ClientIP), для защиты от перебора оберните authProvider в lockout.Provider.
 func Authorizer[T security.RequestCredentials](
 	authManager security.Manager,
 	credentialsProvider security.HTTPCredentialsProvider[T],
 	authProvider security.AuthenticationProvider[T],
+	checkers ...security.AccessChecker,
 ) httpx.Middleware {
 	errResponse := httpx.ServerErrorResponses(
 		httpx.MakeServerError,
@@ -23,7 +28,7 @@ func Authorizer[T security.RequestCredentials](
 	)
 
 	return httpx.MiddlewareFn(func(response http.ResponseWriter, request *http.Request, next http.Handler) {
-		ctx := request.Context()
+		ctx := withClientIP(request.Context(), request)
 		logger := logs.FromContext(ctx)
 
 		// Проверяем, требуется ли авторизация
@@ -50,10 +55,14 @@ func Authorizer[T security.RequestCredentials](
 			logs.UserIDTag.Option(authentication.User.ID),
 			logs.UserAuthorityTag.Option(logs.TagStringArray(authentication.Authorities.ToStrings())),
 			logs.TokenIDTag.Option(authentication.TokenID),
+			logs.WithTenantID(authentication.TenantID),
 		))
 
-		// Проверяем права доступа
-		if authentication.Authorities.Meets(requirements.Authorities) {
+		// Проверяем права доступа и записываем решение в журнал аудита
+		decision := security.Decide(ctx, authentication, requirements, checkers...)
+		security.Audit(ctx, authentication, decision)
+
+		if decision.Granted {
 			logger.Info().Msg("user authority granted")
 
 			// Добавляем информацию о пользователе в контекст
@@ -81,10 +90,12 @@ func Authorizer[T security.RequestCredentials](
 	}).Middleware()
 }
 
+// SecureAuthorizer аналогичен Authorizer, но возвращает ошибки в безопасном формате, см. httpx.MakeSecureServerError.
 func SecureAuthorizer[T security.RequestCredentials](
 	authManager security.Manager,
 	credentialsProvider security.HTTPCredentialsProvider[T],
 	authProvider security.AuthenticationProvider[T],
+	checkers ...security.AccessChecker,
 ) httpx.Middleware {
 	errResponse := httpx.ServerErrorResponses(
 		httpx.MakeSecureServerError,
@@ -92,7 +103,7 @@ func SecureAuthorizer[T security.RequestCredentials](
 	)
 
 	return httpx.MiddlewareFn(func(response http.ResponseWriter, request *http.Request, next http.Handler) {
-		ctx := request.Context()
+		ctx := withClientIP(request.Context(), request)
 		logger := logs.FromContext(ctx)
 
 		// Проверяем, требуется ли авторизация
@@ -109,6 +120,13 @@ func SecureAuthorizer[T security.RequestCredentials](
 				return
 			}
 			logger.Warn().Msg("invalid access token credentials")
+
+			if errs.ErrTooManyRequests.Is(err) {
+				httpx.SendResponse(ctx, response, errResponse(err))
+
+				return
+			}
+
 			httpx.SendResponse(ctx, response, errResponse(errs.Reasons(err.Error(), errs.TypeAuthFailure, "1.1")))
 
 			return
@@ -119,10 +137,14 @@ func SecureAuthorizer[T security.RequestCredentials](
 			logs.UserIDTag.Option(authentication.User.ID),
 			logs.UserAuthorityTag.Option(logs.TagStringArray(authentication.Authorities.ToStrings())),
 			logs.TokenIDTag.Option(authentication.TokenID),
+			logs.WithTenantID(authentication.TenantID),
 		))
 
-		// Проверяем права доступа
-		if authentication.Authorities.Meets(requirements.Authorities) {
+		// Проверяем права доступа и записываем решение в журнал аудита
+		decision := security.Decide(ctx, authentication, requirements, checkers...)
+		security.Audit(ctx, authentication, decision)
+
+		if decision.Granted {
 			logger.Info().Msg("user authority granted")
 
 			// Добавляем информацию о пользователе в контекст
@@ -0,0 +1,27 @@
+package mw
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/httpx"
+)
+
+// ClientIP добавляет в контекст запроса IP адрес клиента (см. security.ClientIP), по которому учитываются неуспешные
+// попытки аутентификации, например, lockout.Guard. Адрес берется из http.Request.RemoteAddr: за обратным прокси
+// используйте middleware.RealIP перед этим посредником.
+func ClientIP() httpx.Middleware {
+	return httpx.MiddlewareFn(func(response http.ResponseWriter, request *http.Request, next http.Handler) {
+		next.ServeHTTP(response, request.WithContext(withClientIP(request.Context(), request)))
+	}).Middleware()
+}
+
+// withClientIP добавляет в контекст IP адрес клиента, если он не был добавлен ранее.
+func withClientIP(ctx context.Context, request *http.Request) context.Context {
+	if security.ClientIP(ctx) != "" {
+		return ctx
+	}
+
+	return security.WithClientIP(ctx, getIP(request))
+}
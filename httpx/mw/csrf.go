@@ -0,0 +1,172 @@
+package mw
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/wal1251/pkg/core/ctxs"
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/httpx"
+	"github.com/wal1251/pkg/tools/crypto"
+)
+
+const (
+	CSRFContextKey        = "CSRF-Token" // Ключ хранения токена CSRF в контексте запроса.
+	DefaultCSRFCookieName = "csrf_token" // Имя cookie с токеном CSRF по умолчанию.
+	DefaultCSRFFormField  = "csrf_token" // Имя поля формы с токеном CSRF по умолчанию.
+
+	csrfTokenLength = 32
+)
+
+// CSRFOptions параметры защиты от CSRF.
+type CSRFOptions struct {
+	CookieName string        // Имя cookie с токеном, по умолчанию DefaultCSRFCookieName.
+	HeaderName string        // Заголовок с токеном, по умолчанию httpx.HeaderCSRFToken.
+	FormField  string        // Поле формы с токеном, по умолчанию DefaultCSRFFormField.
+	Path       string        // Путь cookie, по умолчанию "/".
+	Domain     string        // Домен cookie.
+	Insecure   bool          // Не устанавливать атрибут Secure (например, при разработке без TLS).
+	SameSite   http.SameSite // Атрибут SameSite, по умолчанию http.SameSiteLaxMode.
+
+	// SessionID возвращает идентификатор сессии запроса, например, session.Manager.Extract. Если задан вместе с
+	// Secret, токен аутентифицированного запроса привязывается к сессии, см. CSRF.
+	SessionID func(*http.Request) string
+	Secret    string // Секрет подписи токена, привязанного к сессии.
+}
+
+// CSRF защищает от подделки межсайтовых запросов по схеме double-submit cookie: клиент получает случайный токен в cookie
+// и должен повторить его в заголовке (или поле формы) каждого небезопасного запроса (POST, PUT, PATCH, DELETE).
+// Сторонний сайт не может прочитать cookie, поэтому не может и повторить токен. Токен текущего запроса доступен
+// обработчикам через CSRFToken, например, для вставки в HTML форму.
+//
+// Случайный токен не связан с сессией: злоумышленник, который может установить cookie (например, с соседнего
+// поддомена), навязывает жертве свой токен, и тот продолжает действовать после входа. Поэтому для сессий задавайте
+// CSRFOptions.SessionID и CSRFOptions.Secret: токен запроса с сессией вычисляется как HMAC идентификатора сессии, а
+// значение cookie проверяется по нему и заменяется при смене сессии (вход, ротация). Запросы без сессии используют
+// случайный токен.
+func CSRF(opts CSRFOptions) httpx.Middleware {
+	errResponse := httpx.ServerErrorResponses(
+		httpx.MakeServerError,
+		httpx.NewErrorToStatusMapper(httpx.DefaultErrorToStatusMapping()),
+	)
+
+	opts = csrfDefaults(opts)
+
+	return httpx.MiddlewareFn(func(response http.ResponseWriter, request *http.Request, next http.Handler) {
+		ctx := request.Context()
+
+		var token string
+		if cookie, err := request.Cookie(opts.CookieName); err == nil && cookie.Value != "" {
+			token = cookie.Value
+		}
+
+		issued := token
+		if bound := sessionCSRFToken(request, opts); bound != "" {
+			token = bound
+		}
+
+		if !isSafeMethod(request.Method) {
+			submitted := request.Header.Get(opts.HeaderName)
+			if submitted == "" {
+				submitted = request.PostFormValue(opts.FormField)
+			}
+
+			if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(submitted)) != 1 {
+				logs.FromContext(ctx).Warn().Msg("csrf token mismatch")
+				httpx.SendResponse(ctx, response, errResponse(errs.Wrapf(errs.ErrForbidden, "csrf token mismatch")))
+
+				return
+			}
+		}
+
+		if token == "" {
+			var err error
+			if token, err = newCSRFToken(); err != nil {
+				logs.FromContext(ctx).Err(err).Msg("failed to generate csrf token")
+				httpx.SendResponse(ctx, response, errResponse(errs.Wrapf(errs.ErrSystemFailure, "failed to generate csrf token")))
+
+				return
+			}
+		}
+
+		if token != issued {
+			// Cookie доступна клиентскому скрипту, чтобы он мог повторить токен в заголовке.
+			http.SetCookie(response, &http.Cookie{
+				Name:     opts.CookieName,
+				Value:    token,
+				Path:     opts.Path,
+				Domain:   opts.Domain,
+				Secure:   !opts.Insecure,
+				SameSite: opts.SameSite,
+			})
+		}
+
+		next.ServeHTTP(response, request.WithContext(ctxs.ValuePut(ctx, CSRFContextKey, token)))
+	}).Middleware()
+}
+
+// CSRFToken возвращает токен CSRF текущего запроса, см. CSRF.
+func CSRFToken(ctx context.Context) string {
+	return ctxs.ValueGet[string](ctx, CSRFContextKey)
+}
+
+// sessionCSRFToken возвращает токен, привязанный к сессии запроса, или пустую строку, если привязка не настроена или
+// запрос не содержит сессии.
+func sessionCSRFToken(r *http.Request, opts CSRFOptions) string {
+	if opts.SessionID == nil || opts.Secret == "" {
+		return ""
+	}
+
+	id := opts.SessionID(r)
+	if id == "" {
+		return ""
+	}
+
+	return crypto.NewHMAC(opts.Secret).Sign(id)
+}
+
+func csrfDefaults(opts CSRFOptions) CSRFOptions {
+	if opts.CookieName == "" {
+		opts.CookieName = DefaultCSRFCookieName
+	}
+
+	if opts.HeaderName == "" {
+		opts.HeaderName = httpx.HeaderCSRFToken
+	}
+
+	if opts.FormField == "" {
+		opts.FormField = DefaultCSRFFormField
+	}
+
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+
+	if opts.SameSite == 0 {
+		opts.SameSite = http.SameSiteLaxMode
+	}
+
+	return opts
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+func newCSRFToken() (string, error) {
+	raw := make([]byte, csrfTokenLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err //nolint:wrapcheck
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
@@ -0,0 +1,139 @@
+package mw_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/httpx"
+	"github.com/wal1251/pkg/httpx/mw"
+)
+
+func TestCSRF(t *testing.T) {
+	var seen string
+
+	handler := mw.CSRF(mw.CSRFOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = mw.CSRFToken(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Безопасный запрос получает токен в cookie.
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	cookie := cookies[0]
+	assert.Equal(t, mw.DefaultCSRFCookieName, cookie.Name)
+	assert.True(t, cookie.Secure)
+	assert.False(t, cookie.HttpOnly)
+	assert.Equal(t, cookie.Value, seen)
+
+	tests := []struct {
+		name       string
+		cookie     bool
+		header     string
+		form       string
+		wantStatus int
+	}{
+		{name: "Token in header", cookie: true, header: cookie.Value, wantStatus: http.StatusOK},
+		{name: "Token in form", cookie: true, form: cookie.Value, wantStatus: http.StatusOK},
+		{name: "Token mismatch", cookie: true, header: "forged", wantStatus: http.StatusForbidden},
+		{name: "No token", cookie: true, wantStatus: http.StatusForbidden},
+		{name: "No cookie", header: cookie.Value, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(url.Values{mw.DefaultCSRFFormField: {tt.form}}.Encode()))
+			r.Header.Set(httpx.HeaderContentType, "application/x-www-form-urlencoded")
+
+			if tt.cookie {
+				r.AddCookie(cookie)
+			}
+
+			if tt.header != "" {
+				r.Header.Set(httpx.HeaderCSRFToken, tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestCSRF_sessionBound(t *testing.T) {
+	const sessionCookie = "session"
+
+	handler := mw.CSRF(mw.CSRFOptions{
+		SessionID: func(r *http.Request) string {
+			if cookie, err := r.Cookie(sessionCookie); err == nil {
+				return cookie.Value
+			}
+
+			return ""
+		},
+		Secret: "secret",
+	})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	issue := func(session string) *http.Cookie {
+		t.Helper()
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.AddCookie(&http.Cookie{Name: sessionCookie, Value: session})
+		r.AddCookie(&http.Cookie{Name: mw.DefaultCSRFCookieName, Value: "planted"})
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+
+		return cookies[0]
+	}
+
+	alice, bob := issue("alice"), issue("bob")
+	assert.NotEqual(t, "planted", alice.Value)
+	assert.NotEqual(t, alice.Value, bob.Value)
+
+	tests := []struct {
+		name       string
+		session    string
+		cookie     string
+		header     string
+		wantStatus int
+	}{
+		{name: "Session token", session: "alice", cookie: alice.Value, header: alice.Value, wantStatus: http.StatusOK},
+		{name: "Planted cookie", session: "alice", cookie: "planted", header: "planted", wantStatus: http.StatusForbidden},
+		{name: "Token of other session", session: "alice", cookie: bob.Value, header: bob.Value, wantStatus: http.StatusForbidden},
+		{name: "Random token without session", cookie: "anonymous", header: "anonymous", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.AddCookie(&http.Cookie{Name: mw.DefaultCSRFCookieName, Value: tt.cookie})
+			r.Header.Set(httpx.HeaderCSRFToken, tt.header)
+
+			if tt.session != "" {
+				r.AddCookie(&http.Cookie{Name: sessionCookie, Value: tt.session})
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package mw
+
+import (
+	"net/http"
+
+	"github.com/wal1251/pkg/core/ctxs"
+	"github.com/wal1251/pkg/httpx"
+)
+
+// Deadline ограничивает время обработки запроса бюджетом, полученным от вызывающей стороны в заголовке
+// ctxs.HeaderTimeout, оставляя долю reserve оставшегося времени на формирование ответа. См. ctxs.WithBudget.
+func Deadline(reserve float64) httpx.Middleware {
+	propagator := ctxs.DeadlinePropagator{Names: map[ctxs.Transport]string{ctxs.TransportHTTP: ctxs.HeaderTimeout}}
+
+	return httpx.MiddlewareFn(func(response http.ResponseWriter, request *http.Request, next http.Handler) {
+		ctx := propagator.Extract(request.Context(), ctxs.HeaderCarrier(request.Header))
+
+		ctx, cancel := ctxs.WithBudget(ctx, reserve)
+		defer cancel()
+
+		next.ServeHTTP(response, request.WithContext(ctx))
+	}).Middleware()
+}
@@ -0,0 +1,70 @@
+package mw
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/httpx"
+	"github.com/wal1251/pkg/tools/crypto"
+)
+
+const (
+	HeaderDebugSignature = "X-Debug-Signature"
+)
+
+// DebugLogging повышает уровень логирования до trace для отдельного запроса, если запрос содержит заголовок
+// X-Debug-Signature с действительной подписью (см. DebugSignature). Посредник должен предшествовать Logger, тогда в логи
+// будут выведены тела запроса и ответа только для этого запроса. Если секрет в конфигурации не задан, посредник ничего
+// не делает.
+func DebugLogging(cfg *logs.Config) httpx.Middleware {
+	return httpx.MiddlewareFn(func(response http.ResponseWriter, request *http.Request, next http.Handler) {
+		signature := request.Header.Get(HeaderDebugSignature)
+		if cfg.DebugSecret == "" || signature == "" {
+			next.ServeHTTP(response, request)
+
+			return
+		}
+
+		ctx := request.Context()
+		if !verifyDebugSignature(cfg.DebugSecret, signature, time.Now()) {
+			logs.FromContext(ctx).Warn().Msg("debug signature is invalid or expired")
+			next.ServeHTTP(response, request)
+
+			return
+		}
+
+		ctx = logs.Verbose(ctx, zerolog.TraceLevel)
+
+		logs.FromContext(ctx).Info().Msg("debug logging enabled for request")
+
+		next.ServeHTTP(response, request.WithContext(ctx))
+	}).Middleware()
+}
+
+// DebugSignature возвращает значение заголовка X-Debug-Signature, действительное до момента expiresAt.
+func DebugSignature(secret string, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	return fmt.Sprintf("%s.%s", expires, crypto.NewHMAC(secret).Sign(expires))
+}
+
+func verifyDebugSignature(secret, signature string, now time.Time) bool {
+	expires, sign, ok := strings.Cut(signature, ".")
+	if !ok {
+		return false
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.After(time.Unix(expiresAt, 0)) {
+		return false
+	}
+
+	return hmac.Equal([]byte(sign), []byte(crypto.NewHMAC(secret).Sign(expires)))
+}
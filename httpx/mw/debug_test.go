@@ -0,0 +1,85 @@
+package mw_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/core/presenters"
+	"github.com/wal1251/pkg/httpx/mw"
+)
+
+func TestDebugLogging(t *testing.T) {
+	const secret = "debug-secret"
+
+	tests := []struct {
+		name      string
+		signature string
+		wantBody  bool
+	}{
+		{
+			name:      "Valid signature enables request body logging",
+			signature: mw.DebugSignature(secret, time.Now().Add(time.Minute)),
+			wantBody:  true,
+		},
+		{
+			name:     "No signature",
+			wantBody: false,
+		},
+		{
+			name:      "Expired signature",
+			signature: mw.DebugSignature(secret, time.Now().Add(-time.Minute)),
+			wantBody:  false,
+		},
+		{
+			name:      "Signature with wrong secret",
+			signature: mw.DebugSignature("other-secret", time.Now().Add(time.Minute)),
+			wantBody:  false,
+		},
+		{
+			name:      "Malformed signature",
+			signature: "foo",
+			wantBody:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf, other bytes.Buffer
+			level := logs.NewLevelVar(zerolog.InfoLevel)
+			logger := zerolog.New(&buf).Sample(level).Hook(logs.LevelHook{Level: level})
+			otherLogger := zerolog.New(&other).Sample(level).Hook(logs.LevelHook{Level: level})
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				otherLogger.Debug().Msg("other request")
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"status":"ok"}`))
+			})
+
+			debug := mw.DebugLogging(&logs.Config{DebugSecret: secret})
+			logging := mw.Logger(presenters.ViewLogs, presenters.ViewOptions{})
+
+			request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John"}`))
+			request.Header.Set("Content-Type", "application/json")
+			if tt.signature != "" {
+				request.Header.Set(mw.HeaderDebugSignature, tt.signature)
+			}
+			request = request.WithContext(logger.WithContext(request.Context()))
+
+			debug(logging(handler)).ServeHTTP(httptest.NewRecorder(), request)
+
+			assert.Equal(t, tt.wantBody, strings.Contains(buf.String(), "request body"))
+			assert.Equal(t, tt.wantBody, strings.Contains(buf.String(), "response: "))
+			assert.Contains(t, buf.String(), `"level":"info"`)
+			assert.Equal(t, zerolog.InfoLevel, level.Level(), "level must not be changed by request")
+			assert.Empty(t, other.String(), "other loggers must not be affected by request")
+		})
+	}
+}
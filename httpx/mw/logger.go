@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog"
 
 	"github.com/wal1251/pkg/core/ctxs"
 	"github.com/wal1251/pkg/core/logs"
@@ -37,7 +38,7 @@ func Logger(view presenters.ViewType, options presenters.ViewOptions) httpx.Midd
 					responseWrapper.BytesWritten(),
 				)
 
-			if responseWrapper.BytesWritten() > 0 {
+			if responseWrapper.BytesWritten() > 0 && logs.Enabled(ctx, zerolog.TraceLevel) {
 				logger.Trace().Msgf("response: %s", presenters.JSONHideCredentials(buf.String(), options))
 			}
 		}()
@@ -58,7 +59,7 @@ func Logger(view presenters.ViewType, options presenters.ViewOptions) httpx.Midd
 				len(body),
 			)
 
-		if len(body) != 0 && httpx.Header(request.Header).HasJSONContent() {
+		if len(body) != 0 && httpx.Header(request.Header).HasJSONContent() && logs.Enabled(ctx, zerolog.TraceLevel) {
 			logger.Trace().Msgf("request body: %s", presenters.JSONString(string(body), view, options))
 		}
 
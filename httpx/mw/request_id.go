@@ -28,3 +28,11 @@ func AnnotateRequestContext() httpx.Middleware {
 		next.ServeHTTP(response, request.WithContext(httpx.AnnotateContext(request)))
 	}).Middleware()
 }
+
+// Propagation извлекает из заголовков запроса значения контекста всех распространителей, зарегистрированных в
+// ctxs.DefaultPropagators, и добавляет их в контекст запроса.
+func Propagation() httpx.Middleware {
+	return httpx.MiddlewareFn(func(response http.ResponseWriter, request *http.Request, next http.Handler) {
+		next.ServeHTTP(response, request.WithContext(httpx.ExtractHeader(request.Context(), request.Header)))
+	}).Middleware()
+}
@@ -0,0 +1,263 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/presenters/pagination"
+)
+
+const (
+	QueryParamLimit  = "limit"  // Параметр запроса размера страницы.
+	QueryParamOffset = "offset" // Параметр запроса смещения первого элемента страницы.
+	QueryParamCursor = "cursor" // Параметр запроса курсора страницы.
+	QueryParamSort   = "sort"   // Параметр запроса сортировки, см. pagination.ParseSorting.
+	QueryParamNulls  = "nulls"  // Параметр запроса положения пустых значений при сортировке: first или last.
+
+	DefaultMaxLimit = 1000 // Максимальный размер страницы по умолчанию, см. WithMaxLimit.
+
+	LinkRelFirst    = "first" // Ссылка на первую страницу.
+	LinkRelPrevious = "prev"  // Ссылка на предыдущую страницу.
+	LinkRelNext     = "next"  // Ссылка на следующую страницу.
+	LinkRelLast     = "last"  // Ссылка на последнюю страницу.
+)
+
+type (
+	// PageOption опция чтения параметров выдачи, см. BindPageParams и BindCursorQuery.
+	PageOption func(*pageOptions)
+
+	pageOptions struct {
+		maxLimit int
+	}
+)
+
+// WithMaxLimit устанавливает максимальный размер страницы (по умолчанию DefaultMaxLimit), больший размер возвращается
+// как ошибка валидации параметра limit. Значение 0 отключает ограничение.
+func WithMaxLimit(limit int) PageOption {
+	return func(o *pageOptions) {
+		o.maxLimit = limit
+	}
+}
+
+// BindPageParams читает параметры постраничной выдачи limit и offset из строки запроса. Отсутствующие параметры
+// принимают значения по умолчанию, см. pagination.NewPageParams. Некорректные значения, в том числе размер страницы
+// больше максимального (см. WithMaxLimit), возвращаются как ошибки валидации параметра (errs.ErrIllegalArgument),
+// которые отображаются в статус 400.
+func BindPageParams(r *http.Request, opts ...PageOption) (*pagination.PageParams, error) {
+	query := r.URL.Query()
+
+	limit, err := queryLimit(query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, err := queryInt(query, QueryParamOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	params := pagination.NewPageParams(offset, limit)
+	if params.Offset < 0 {
+		return nil, queryParamError(QueryParamOffset, params.Validate())
+	}
+
+	if err = params.Validate(); err != nil {
+		return nil, queryParamError(QueryParamLimit, err)
+	}
+
+	return params, nil
+}
+
+// BindCursorQuery читает параметры курсорной выдачи limit и cursor из строки запроса, см. BindPageParams. Курсор
+// проверяется при получении параметров выборки, см. pagination.CursorQuery.Keyset.
+func BindCursorQuery(r *http.Request, opts ...PageOption) (*pagination.CursorQuery, error) {
+	query := r.URL.Query()
+
+	limit, err := queryLimit(query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor *string
+	if query.Has(QueryParamCursor) {
+		value := query.Get(QueryParamCursor)
+		cursor = &value
+	}
+
+	params := (&pagination.CursorQuery{}).WithLimit(limit).WithCursor(cursor)
+	if err = params.Validate(); err != nil {
+		return nil, queryParamError(QueryParamLimit, err)
+	}
+
+	return params, nil
+}
+
+// BindSortParams читает параметры сортировки из значений параметра запроса sort (см. pagination.ParseSorting) и
+// положение пустых значений из параметра nulls (first или last), проверяет, что поля сортировки входят в acceptable.
+// Маппинг полей и поля по умолчанию задаются после чтения, см. pagination.SortParams. Некорректные значения
+// возвращаются как ошибки валидации параметра, см. BindPageParams.
+func BindSortParams(r *http.Request, acceptable ...string) (*pagination.SortParams, error) {
+	query := r.URL.Query()
+
+	sorting, err := pagination.ParseSorting(query[QueryParamSort]...)
+	if err != nil {
+		return nil, queryParamError(QueryParamSort, err)
+	}
+
+	nulls := pagination.NullsOrder(strings.ToLower(query.Get(QueryParamNulls)))
+	if err = nulls.Validate(); err != nil {
+		return nil, queryParamError(QueryParamNulls, err)
+	}
+
+	sorting.WithNulls(nulls)
+
+	if err = sorting.WithAcceptableFields(acceptable...).Validate(); err != nil {
+		return nil, queryParamError(QueryParamSort, err)
+	}
+
+	return sorting, nil
+}
+
+// WithPageLinks устанавливает заголовки Link (RFC 8288) со ссылками на первую, предыдущую, следующую и последнюю
+// страницы и X-Total-Count с общим количеством элементов. Ссылки строятся из адреса запроса requestURL заменой
+// параметров limit и offset, остальные параметры (например, сортировка) сохраняются.
+func (r *ServerResponseBuilder[T]) WithPageLinks(requestURL *url.URL, page pagination.PageQuery, total int) *ServerResponseBuilder[T] {
+	r.Header.Set(HeaderTotalCount, strconv.Itoa(total))
+
+	limit, offset := page.GetLimit(), page.GetOffset()
+	if limit <= 0 {
+		return r
+	}
+
+	pageURL := func(offset int) string {
+		return linkURL(requestURL, map[string]string{
+			QueryParamLimit:  strconv.Itoa(limit),
+			QueryParamOffset: strconv.Itoa(offset),
+		})
+	}
+
+	links := []string{link(pageURL(0), LinkRelFirst)}
+
+	if offset > 0 {
+		links = append(links, link(pageURL(max(offset-limit, 0)), LinkRelPrevious))
+	}
+
+	if offset+limit < total {
+		links = append(links, link(pageURL(offset+limit), LinkRelNext))
+	}
+
+	if total > 0 {
+		links = append(links, link(pageURL((total-1)/limit*limit), LinkRelLast))
+	}
+
+	r.Header.Set(HeaderLink, strings.Join(links, ", "))
+
+	return r
+}
+
+// WithCursorLinks устанавливает заголовок Link (RFC 8288) со ссылками на предыдущую и следующую страницы курсорной
+// выдачи. Пустой курсор означает отсутствие страницы. Ссылки строятся из адреса запроса requestURL заменой параметра
+// cursor, см. WithPageLinks.
+func (r *ServerResponseBuilder[T]) WithCursorLinks(requestURL *url.URL, previous, next string) *ServerResponseBuilder[T] {
+	var links []string
+
+	if previous != "" {
+		links = append(links, link(linkURL(requestURL, map[string]string{QueryParamCursor: previous}), LinkRelPrevious))
+	}
+
+	if next != "" {
+		links = append(links, link(linkURL(requestURL, map[string]string{QueryParamCursor: next}), LinkRelNext))
+	}
+
+	if len(links) > 0 {
+		r.Header.Set(HeaderLink, strings.Join(links, ", "))
+	}
+
+	return r
+}
+
+// PageResponse опция ответа, отображающая страницу page в формате JSON с заголовками Link и X-Total-Count, см.
+// WithPageLinks. Например:
+//
+//	httpx.SendResponse(ctx, w, httpx.NewServerResponse(httpx.PageResponse(r, page)))
+//
+// .
+func PageResponse[T any](r *http.Request, page *pagination.Page[T]) func(*ServerResponseBuilder[*pagination.Page[T]]) {
+	return func(builder *ServerResponseBuilder[*pagination.Page[T]]) {
+		builder.WithContentTypeJSON().
+			WithValue(page).
+			WithPageLinks(r.URL, &page.PageParams, page.TotalCount)
+	}
+}
+
+// CursorPageResponse опция ответа, отображающая страницу курсорной выдачи page в формате JSON с заголовком Link, см.
+// WithCursorLinks.
+func CursorPageResponse[T any](r *http.Request, page *pagination.CursorPage[T]) func(*ServerResponseBuilder[*pagination.CursorPage[T]]) {
+	return func(builder *ServerResponseBuilder[*pagination.CursorPage[T]]) {
+		builder.WithContentTypeJSON().
+			WithValue(page).
+			WithCursorLinks(r.URL, page.Previous, page.Next)
+	}
+}
+
+// queryLimit читает размер страницы и проверяет, что он не больше максимального.
+func queryLimit(query url.Values, opts []PageOption) (*int, error) {
+	options := pageOptions{maxLimit: DefaultMaxLimit}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	limit, err := queryInt(query, QueryParamLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit != nil && options.maxLimit > 0 && *limit > options.maxLimit {
+		return nil, queryParamError(QueryParamLimit,
+			fmt.Errorf("%w: exceeds maximum %d", pagination.ErrInvalidLimit, options.maxLimit))
+	}
+
+	return limit, nil
+}
+
+func queryInt(query url.Values, param string) (*int, error) {
+	if !query.Has(param) {
+		return nil, nil //nolint:nilnil
+	}
+
+	value, err := strconv.Atoi(query.Get(param))
+	if err != nil {
+		return nil, queryParamError(param, errors.New("not an integer"))
+	}
+
+	return &value, nil
+}
+
+func queryParamError(param string, err error) error {
+	wrapped := errs.WrapFields(errs.ErrIllegalArgument, err.Error(), param)
+	wrapped.Message = fmt.Sprintf("incorrect url query \"%s\": %v", param, err)
+
+	return wrapped
+}
+
+func linkURL(requestURL *url.URL, params map[string]string) string {
+	target := *requestURL
+	query := target.Query()
+
+	for key, value := range params {
+		query.Set(key, value)
+	}
+
+	target.RawQuery = query.Encode()
+
+	return target.String()
+}
+
+func link(target, rel string) string {
+	return fmt.Sprintf("<%s>; rel=\"%s\"", target, rel)
+}
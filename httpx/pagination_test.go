@@ -0,0 +1,224 @@
+package httpx_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/presenters/pagination"
+	"github.com/wal1251/pkg/httpx"
+)
+
+func TestBindPageParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		opts      []httpx.PageOption
+		want      *pagination.PageParams
+		wantField string
+	}{
+		{
+			name:  "Значения по умолчанию",
+			query: "",
+			want:  &pagination.PageParams{Limit: pagination.DefaultLimit},
+		},
+		{
+			name:  "Заданные значения",
+			query: "limit=20&offset=40",
+			want:  &pagination.PageParams{Limit: 20, Offset: 40},
+		},
+		{
+			name:      "Не число",
+			query:     "limit=ten",
+			wantField: httpx.QueryParamLimit,
+		},
+		{
+			name:      "Отрицательное смещение",
+			query:     "offset=-1",
+			wantField: httpx.QueryParamOffset,
+		},
+		{
+			name:      "Отрицательный размер страницы",
+			query:     "limit=-1",
+			wantField: httpx.QueryParamLimit,
+		},
+		{
+			name:      "Размер страницы больше максимального по умолчанию",
+			query:     "limit=" + strconv.Itoa(httpx.DefaultMaxLimit+1),
+			wantField: httpx.QueryParamLimit,
+		},
+		{
+			name:      "Размер страницы больше заданного максимального",
+			query:     "limit=51",
+			opts:      []httpx.PageOption{httpx.WithMaxLimit(50)},
+			wantField: httpx.QueryParamLimit,
+		},
+		{
+			name:  "Максимальный размер страницы",
+			query: "limit=50",
+			opts:  []httpx.PageOption{httpx.WithMaxLimit(50)},
+			want:  &pagination.PageParams{Limit: 50},
+		},
+		{
+			name:  "Без ограничения размера страницы",
+			query: "limit=100000",
+			opts:  []httpx.PageOption{httpx.WithMaxLimit(0)},
+			want:  &pagination.PageParams{Limit: 100000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := httpx.BindPageParams(httptest.NewRequest(http.MethodGet, "/items?"+tt.query, nil), tt.opts...)
+			if tt.wantField != "" {
+				requireBadRequest(t, err, tt.wantField)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, params)
+		})
+	}
+}
+
+func TestBindCursorQuery(t *testing.T) {
+	params, err := httpx.BindCursorQuery(httptest.NewRequest(http.MethodGet, "/items?cursor=abc&limit=5", nil))
+	require.NoError(t, err)
+	assert.Equal(t, &pagination.CursorQuery{Cursor: "abc", Limit: 5}, params)
+
+	_, err = httpx.BindCursorQuery(httptest.NewRequest(http.MethodGet, "/items?limit=-5", nil))
+	requireBadRequest(t, err, httpx.QueryParamLimit)
+
+	_, err = httpx.BindCursorQuery(httptest.NewRequest(http.MethodGet, "/items?limit=11", nil), httpx.WithMaxLimit(10))
+	requireBadRequest(t, err, httpx.QueryParamLimit)
+}
+
+func TestBindSortParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		want      []pagination.SortKey
+		wantField string
+	}{
+		{
+			name:  "Несколько значений",
+			query: "sort=-priority&sort=created:asc",
+			want: []pagination.SortKey{
+				{Field: "priority", Direction: pagination.SortDesc},
+				{Field: "created", Direction: pagination.SortAsc},
+			},
+		},
+		{
+			name:  "Положение пустых значений",
+			query: "sort=-priority&nulls=last",
+			want:  []pagination.SortKey{{Field: "priority", Direction: pagination.SortDesc, Nulls: pagination.NullsLast}},
+		},
+		{
+			name:      "Некорректное положение пустых значений",
+			query:     "sort=priority&nulls=middle",
+			wantField: httpx.QueryParamNulls,
+		},
+		{
+			name:      "Недопустимое поле",
+			query:     "sort=secret",
+			wantField: httpx.QueryParamSort,
+		},
+		{
+			name:      "Некорректное направление",
+			query:     "sort=priority:up",
+			wantField: httpx.QueryParamSort,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/items?"+tt.query, nil)
+
+			sorting, err := httpx.BindSortParams(request, "priority", "created")
+			if tt.wantField != "" {
+				requireBadRequest(t, err, tt.wantField)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, sorting.GetKeys())
+		})
+	}
+}
+
+func TestPageResponse(t *testing.T) {
+	type Item struct {
+		ID int `json:"id"`
+	}
+
+	tests := []struct {
+		name  string
+		page  *pagination.Page[Item]
+		links string
+	}{
+		{
+			name: "Первая страница",
+			page: &pagination.Page[Item]{PageParams: pagination.PageParams{Limit: 10}, TotalCount: 25},
+			links: `</items?limit=10&offset=0&sort=-id>; rel="first", ` +
+				`</items?limit=10&offset=10&sort=-id>; rel="next", ` +
+				`</items?limit=10&offset=20&sort=-id>; rel="last"`,
+		},
+		{
+			name: "Средняя страница",
+			page: &pagination.Page[Item]{PageParams: pagination.PageParams{Limit: 10, Offset: 5}, TotalCount: 25},
+			links: `</items?limit=10&offset=0&sort=-id>; rel="first", ` +
+				`</items?limit=10&offset=0&sort=-id>; rel="prev", ` +
+				`</items?limit=10&offset=15&sort=-id>; rel="next", ` +
+				`</items?limit=10&offset=20&sort=-id>; rel="last"`,
+		},
+		{
+			name:  "Пустая выдача",
+			page:  &pagination.Page[Item]{PageParams: pagination.PageParams{Limit: 10}},
+			links: `</items?limit=10&offset=0&sort=-id>; rel="first"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/items?sort=-id&offset=99", nil)
+			w := httptest.NewRecorder()
+
+			tt.page.Content = []*Item{{ID: 1}}
+
+			require.NoError(t, httpx.NewServerResponse(httpx.PageResponse(request, tt.page)).Send(w))
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.links, w.Header().Get(httpx.HeaderLink))
+			assert.Equal(t, strconv.Itoa(tt.page.TotalCount), w.Header().Get(httpx.HeaderTotalCount))
+			assert.Contains(t, w.Body.String(), `"content":[{"id":1}]`)
+		})
+	}
+}
+
+func TestCursorPageResponse(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/items?limit=5&cursor=a", nil)
+	w := httptest.NewRecorder()
+
+	page := &pagination.CursorPage[int]{Next: "b", Limit: 5}
+
+	require.NoError(t, httpx.NewServerResponse(httpx.CursorPageResponse(request, page)).Send(w))
+	assert.Equal(t, `</items?cursor=b&limit=5>; rel="next"`, w.Header().Get(httpx.HeaderLink))
+}
+
+func requireBadRequest(t *testing.T, err error, field string) {
+	t.Helper()
+
+	require.ErrorIs(t, err, errs.ErrIllegalArgument)
+	assert.Equal(t, http.StatusBadRequest,
+		httpx.NewErrorToStatusMapper(httpx.DefaultErrorToStatusMapping()).Status(errs.AsReason(err).Type))
+
+	var wrapping *errs.WrappingError
+	require.ErrorAs(t, err, &wrapping)
+	assert.Contains(t, wrapping.Fields, field)
+}
@@ -0,0 +1,49 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/jwt"
+)
+
+// RefreshRequest запрос обмена или отзыва токена продления.
+type RefreshRequest struct {
+	RefreshToken security.BearerToken `json:"refresh_token"` //nolint:tagliatelle
+}
+
+// RefreshTokenHandler возвращает обработчик, обменивающий токен продления из тела запроса
+// (например, {"refresh_token": "..."}) на новую пару токенов, см. jwt.RefreshService.Refresh.
+func RefreshTokenHandler(service *jwt.RefreshService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		NewSecureServerHandler[RefreshRequest, jwt.Tokens](writer, request).
+			WithResponseJSON().
+			WithMethod(func(ctx context.Context, req RefreshRequest) (jwt.Tokens, error) {
+				if req.RefreshToken == "" {
+					return jwt.Tokens{}, errs.Wrapf(errs.ErrAuthFailure, "refresh token is required")
+				}
+
+				return service.Refresh(ctx, req.RefreshToken)
+			}).
+			Handle()
+	}
+}
+
+// LogoutHandler возвращает обработчик, отзывающий семейство токена продления из тела запроса, см.
+// jwt.RefreshService.Logout. При успехе отвечает статусом 204.
+func LogoutHandler(service *jwt.RefreshService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		NewSecureServerHandler[RefreshRequest, any](writer, request).
+			WithResponseStatus(http.StatusNoContent).
+			WithMethod(func(ctx context.Context, req RefreshRequest) (any, error) {
+				if req.RefreshToken == "" {
+					return nil, errs.Wrapf(errs.ErrAuthFailure, "refresh token is required")
+				}
+
+				return nil, service.Logout(ctx, req.RefreshToken)
+			}).
+			Handle()
+	}
+}
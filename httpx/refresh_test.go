@@ -0,0 +1,64 @@
+package httpx_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/memorystore/local"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/jwt"
+	"github.com/wal1251/pkg/httpx"
+)
+
+func TestRefreshTokenHandler(t *testing.T) {
+	manager, err := jwt.NewTokenManager(&jwt.ManagerConfig{
+		Secret:          []byte("secret"),
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: 24 * time.Hour,
+	}, func() jwt.RichToken { return &jwt.DefaultRichToken{Claims: &jwt.DefaultClaims{}} })
+	require.NoError(t, err)
+
+	store, err := local.NewStore(&local.Config{})
+	require.NoError(t, err)
+
+	service := jwt.NewRefreshService(manager, store, 24*time.Hour)
+
+	tokens, err := service.Issue(context.Background(), security.Authentication{
+		User: security.User{ID: uuid.New(), Name: "user"},
+	})
+	require.NoError(t, err)
+
+	send := func(handler http.HandlerFunc, token security.BearerToken) *httptest.ResponseRecorder {
+		body, err := json.Marshal(httpx.RefreshRequest{RefreshToken: token})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/token", bytes.NewBuffer(body))
+		r.Header.Set(httpx.HeaderContentType, httpx.ContentTypeJSON)
+
+		handler.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := send(httpx.RefreshTokenHandler(service), tokens.RefreshToken)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var refreshed jwt.Tokens
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &refreshed))
+	assert.NotEmpty(t, refreshed.AccessToken)
+	assert.NotEmpty(t, refreshed.RefreshToken)
+
+	assert.Equal(t, http.StatusUnauthorized, send(httpx.RefreshTokenHandler(service), "").Code)
+	assert.Equal(t, http.StatusNoContent, send(httpx.LogoutHandler(service), refreshed.RefreshToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, send(httpx.RefreshTokenHandler(service), refreshed.RefreshToken).Code)
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 
 	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/core/security/mtls"
 )
 
 func NewServer(cfg *Config, handler http.Handler) *http.Server {
@@ -18,7 +19,21 @@ func NewServer(cfg *Config, handler http.Handler) *http.Server {
 	}
 }
 
-// StartServer starts a server, closes on context cancel.
+// NewTLSServer возвращает сервер HTTPS с конфигурацией TLS из Config.TLS: сертификаты перечитываются при изменении
+// файлов, сертификаты клиентов проверяются согласно Config.TLS.ClientAuth (mTLS), см. mtls.Reloader.
+func NewTLSServer(cfg *Config, handler http.Handler) (*http.Server, error) {
+	tlsConfig, err := mtls.ServerTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure server tls: %w", err)
+	}
+
+	server := NewServer(cfg, handler)
+	server.TLSConfig = tlsConfig
+
+	return server, nil
+}
+
+// StartServer starts a server, closes on context cancel. Server with TLSConfig (see NewTLSServer) serves HTTPS.
 func StartServer(ctx context.Context, server *http.Server) error {
 	log := logs.FromContext(ctx)
 	log.Info().Msgf("starting server at: %s", server.Addr)
@@ -30,6 +45,12 @@ func StartServer(ctx context.Context, server *http.Server) error {
 	serverErrors := make(chan error, 1)
 
 	go func() {
+		if server.TLSConfig != nil {
+			serverErrors <- server.ListenAndServeTLS("", "")
+
+			return
+		}
+
 		serverErrors <- server.ListenAndServe()
 	}()
 
@@ -3,7 +3,10 @@ package httpx
 import (
 	"context"
 	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/wal1251/pkg/core"
 	"github.com/wal1251/pkg/core/errs"
@@ -35,6 +38,14 @@ func (r *ServerResponseBuilder[T]) WithContentTypeXML() *ServerResponseBuilder[T
 	return r.WithContentType("application/xml;charset=UTF-8").WithEncoder(serial.XMLEncode[T])
 }
 
+// WithRetryAfter устанавливает заголовок Retry-After: задержку в секундах (с округлением вверх) перед повторным запросом.
+func (r *ServerResponseBuilder[T]) WithRetryAfter(after time.Duration) *ServerResponseBuilder[T] {
+	seconds := int64(math.Ceil(after.Seconds()))
+	r.Header.Set(HeaderRetryAfter, strconv.FormatInt(max(seconds, 0), 10))
+
+	return r
+}
+
 func (r *ServerResponseBuilder[T]) WithEncoder(e serial.Encoder[T]) *ServerResponseBuilder[T] {
 	r.encoder = e
 
@@ -134,10 +145,16 @@ func ServerErrorResponses[T any](
 	errToStatus *ErrorToStatusMapper,
 ) func(err error) *ServerResponseBuilder[T] {
 	return func(err error) *ServerResponseBuilder[T] {
-		return NewServerResponse[T]().
+		builder := NewServerResponse[T]().
 			WithContentTypeJSON().
 			WithStatus(errToStatus.Status(errs.AsReason(err).Type)).
 			WithValue(errToResponse.Map(err))
+
+		if after, ok := errs.RetryAfter(err); ok {
+			builder.WithRetryAfter(after)
+		}
+
+		return builder
 	}
 }
 
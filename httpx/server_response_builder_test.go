@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -79,6 +80,7 @@ func TestErrorServerResponses(t *testing.T) {
 		err        error
 		wantStatus int
 		wantBody   string
+		wantRetry  string
 	}{
 		{
 			name:       "Unclassified server error",
@@ -98,6 +100,13 @@ func TestErrorServerResponses(t *testing.T) {
 			wantStatus: errMapper.Status(errs.Error{}.Type),
 			wantBody:   `{}`,
 		},
+		{
+			name:       "Too many requests with retry after",
+			err:        errs.WithRetryAfter(errs.Wrapf(errs.ErrTooManyRequests, "locked"), 1500*time.Millisecond),
+			wantStatus: http.StatusTooManyRequests,
+			wantBody:   `{"code":"TOO_MANY_REQUESTS", "message":"TOO_MANY_REQUESTS: locked"}`,
+			wantRetry:  "2",
+		},
 	}
 
 	for _, tt := range tests {
@@ -106,6 +115,7 @@ func TestErrorServerResponses(t *testing.T) {
 			if assert.NoError(t, makeErrorResponse(tt.err).Send(w)) {
 				assert.Equalf(t, tt.wantStatus, w.Code, "response status code not matches")
 				assert.JSONEqf(t, tt.wantBody, w.Body.String(), "response body not matches")
+				assert.Equal(t, tt.wantRetry, w.Header().Get(httpx.HeaderRetryAfter))
 			}
 		})
 	}
@@ -0,0 +1,87 @@
+package search
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wal1251/pkg/core/presenters/filter"
+)
+
+// NewQueryBooleanFromFilter создает логический запрос из выражения фильтрации, проверенного схемой (см.
+// filter.Schema). Условия выражения добавляются в контекст фильтрации (filter и must_not) и не влияют на
+// релевантность. Время передается в формате RFC3339. Оператор filter.OpRegex использует синтаксис регулярных
+// выражений Lucene: выражение должно соответствовать term целиком.
+func NewQueryBooleanFromFilter(expression *filter.Expression) *QueryBoolean {
+	var query QueryBoolean
+
+	if expression.IsEmpty() {
+		return &query
+	}
+
+	for _, condition := range expression.Conditions {
+		query.appendCondition(condition)
+	}
+
+	return &query
+}
+
+func (q *QueryBoolean) appendCondition(condition filter.Condition) {
+	field := condition.Column
+	values := make([]any, len(condition.Values))
+
+	for i, value := range condition.Values {
+		values[i] = filterValue(value)
+	}
+
+	var value any
+	if len(values) > 0 {
+		value = values[0]
+	}
+
+	switch condition.Operator {
+	case filter.OpEq:
+		q.AddFilter(NewQueryTerm(field, value))
+	case filter.OpNe:
+		q.AddMustNot(NewQueryTerm(field, value))
+	case filter.OpGt:
+		q.AddFilter(&QueryRange{Field: field, Gt: value})
+	case filter.OpGte:
+		q.AddFilter(&QueryRange{Field: field, Gte: value})
+	case filter.OpLt:
+		q.AddFilter(&QueryRange{Field: field, Lt: value})
+	case filter.OpLte:
+		q.AddFilter(&QueryRange{Field: field, Lte: value})
+	case filter.OpIn:
+		q.AddFilter(NewQueryTerms(field, values...))
+	case filter.OpNin:
+		q.AddMustNot(NewQueryTerms(field, values...))
+	case filter.OpLike:
+		substr, _ := value.(string)
+		q.AddFilter(NewQueryWildcardContains(field, substr))
+	case filter.OpRegex:
+		pattern, _ := value.(string)
+		regexp := NewQueryRegexp(field, pattern)
+		regexp.CaseInsensitive = true
+		q.AddFilter(regexp)
+	case filter.OpNull:
+		if isNull, _ := value.(bool); isNull {
+			q.AddMustNot(&QueryExists{Field: field})
+		} else {
+			q.AddFilter(&QueryExists{Field: field})
+		}
+	default:
+		q.AddFilter(NewQueryTerms(field))
+	}
+}
+
+func filterValue(value any) any {
+	switch typed := value.(type) {
+	case time.Time:
+		return typed.Format(time.RFC3339Nano)
+	case uuid.UUID:
+		return typed.String()
+	default:
+		return value
+	}
+}
@@ -0,0 +1,66 @@
+package search_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/presenters/filter"
+	"github.com/wal1251/pkg/providers/es/search"
+)
+
+func TestNewQueryBooleanFromFilter(t *testing.T) {
+	schema := filter.NewSchema(
+		filter.Field{Name: "status", Type: filter.TypeString},
+		filter.Field{Name: "name", Type: filter.TypeString, Operators: []filter.Operator{filter.OpLike, filter.OpRegex}},
+		filter.Field{Name: "created", Column: "created_at", Type: filter.TypeTime},
+		filter.Field{Name: "count", Type: filter.TypeInt},
+	)
+
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			name: "Пустое выражение",
+			raw:  "",
+			want: `{"bool":{}}`,
+		},
+		{
+			name: "Несколько условий",
+			raw:  "status:in:new,open;status:ne:closed;created:gte:2024-01-01;count:null:true;name:like:foo",
+			want: `{"bool":{
+				"filter":[
+					{"terms":{"status":["new","open"]}},
+					{"range":{"created_at":{"gte":"2024-01-01T00:00:00Z"}}},
+					{"wildcard":{"name":{"value":"*foo*","case_insensitive":true}}}
+				],
+				"must_not":[
+					{"term":{"status":{"value":"closed"}}},
+					{"exists":{"field":"count"}}
+				]
+			}}`,
+		},
+		{
+			name: "Регулярное выражение",
+			raw:  "name:re:fo+;count:lt:10",
+			want: `{"bool":{"filter":[
+				{"regexp":{"name":{"value":"fo+","case_insensitive":true}}},
+				{"range":{"count":{"lt":10}}}
+			]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, err := schema.Parse(tt.raw)
+			require.NoError(t, err)
+
+			res, err := json.Marshal(search.NewQueryBooleanFromFilter(expression))
+			require.NoError(t, err)
+			require.JSONEq(t, tt.want, string(res))
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package search
+
+import (
+	"github.com/wal1251/pkg/providers/es/api"
+	"github.com/wal1251/pkg/tools/serial"
+)
+
+const (
+	QueryRangeKey    = "range"
+	QueryRangeGtKey  = "gt"
+	QueryRangeGteKey = "gte"
+	QueryRangeLtKey  = "lt"
+	QueryRangeLteKey = "lte"
+)
+
+var _ Query = (*QueryRange)(nil)
+
+// QueryRange запрос, который возвращает документы, значение поля которых попадает в диапазон. Границы со значением
+// nil не ограничивают диапазон.
+type QueryRange struct {
+	Field string
+	Gt    any
+	Gte   any
+	Lt    any
+	Lte   any
+}
+
+func (q *QueryRange) MarshalJSON() ([]byte, error) {
+	bounds := api.Object{}
+	for key, value := range map[string]any{
+		QueryRangeGtKey:  q.Gt,
+		QueryRangeGteKey: q.Gte,
+		QueryRangeLtKey:  q.Lt,
+		QueryRangeLteKey: q.Lte,
+	} {
+		if value != nil {
+			bounds[key] = value
+		}
+	}
+
+	return serial.ToBytes(api.Object{
+		q.QueryType(): api.Object{
+			q.Field: bounds,
+		},
+	}, serial.JSONEncode[api.Object])
+}
+
+func (q *QueryRange) IsEmpty() bool {
+	return q.Field == ""
+}
+
+func (q *QueryRange) QueryType() string {
+	return QueryRangeKey
+}
+
+func NewQueryRange(field string) *QueryRange {
+	return &QueryRange{
+		Field: field,
+	}
+}
@@ -0,0 +1,22 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/providers/es/search"
+)
+
+func TestQueryRange(t *testing.T) {
+	queryRange := search.NewQueryRange("count")
+	queryRange.Gte = 0
+	queryRange.Lt = 10
+
+	require.False(t, queryRange.IsEmpty())
+	require.Equal(t, search.QueryRangeKey, queryRange.QueryType())
+
+	res, err := queryRange.MarshalJSON()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"range":{"count":{"gte":0,"lt":10}}}`, string(res))
+}
@@ -0,0 +1,48 @@
+package search
+
+import (
+	"github.com/wal1251/pkg/providers/es/api"
+	"github.com/wal1251/pkg/tools/serial"
+)
+
+const (
+	QueryRegexpKey                = "regexp"
+	QueryRegexpValueKey           = "value"
+	QueryRegexpCaseInsensitiveKey = "case_insensitive"
+)
+
+var _ Query = (*QueryRegexp)(nil)
+
+// QueryRegexp запрос, который возвращает документы, содержащие в указанном поле term, соответствующий регулярному
+// выражению.
+type QueryRegexp struct {
+	Field           string
+	Value           string
+	CaseInsensitive bool
+}
+
+func (q *QueryRegexp) MarshalJSON() ([]byte, error) {
+	return serial.ToBytes(api.Object{
+		q.QueryType(): api.Object{
+			q.Field: api.CreateObject(
+				api.Property(QueryRegexpValueKey, q.Value),
+				api.PropertyOmitempty(QueryRegexpCaseInsensitiveKey, q.CaseInsensitive),
+			),
+		},
+	}, serial.JSONEncode[api.Object])
+}
+
+func (q *QueryRegexp) IsEmpty() bool {
+	return q.Field == ""
+}
+
+func (q *QueryRegexp) QueryType() string {
+	return QueryRegexpKey
+}
+
+func NewQueryRegexp(field string, value string) *QueryRegexp {
+	return &QueryRegexp{
+		Field: field,
+		Value: value,
+	}
+}
@@ -0,0 +1,20 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/providers/es/search"
+)
+
+func TestNewQueryRegexp(t *testing.T) {
+	queryRegexp := search.NewQueryRegexp("name", "fo+")
+
+	require.False(t, queryRegexp.IsEmpty())
+	require.Equal(t, search.QueryRegexpKey, queryRegexp.QueryType())
+
+	res, err := queryRegexp.MarshalJSON()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"regexp":{"name":{"value":"fo+"}}}`, string(res))
+}
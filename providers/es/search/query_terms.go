@@ -0,0 +1,46 @@
+package search
+
+import (
+	"github.com/wal1251/pkg/providers/es/api"
+	"github.com/wal1251/pkg/tools/serial"
+)
+
+const (
+	QueryTermsKey = "terms"
+)
+
+var _ Query = (*QueryTerms)(nil)
+
+// QueryTerms запрос, который возвращает документы, содержащие в указанном поле один из точных terms.
+type QueryTerms struct {
+	Field  string
+	Values []any
+}
+
+func (q *QueryTerms) MarshalJSON() ([]byte, error) {
+	values := q.Values
+	if values == nil {
+		values = make([]any, 0)
+	}
+
+	return serial.ToBytes(api.Object{
+		q.QueryType(): api.Object{
+			q.Field: values,
+		},
+	}, serial.JSONEncode[api.Object])
+}
+
+func (q *QueryTerms) IsEmpty() bool {
+	return q.Field == ""
+}
+
+func (q *QueryTerms) QueryType() string {
+	return QueryTermsKey
+}
+
+func NewQueryTerms(field string, values ...any) *QueryTerms {
+	return &QueryTerms{
+		Field:  field,
+		Values: values,
+	}
+}
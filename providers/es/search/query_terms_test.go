@@ -0,0 +1,20 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/providers/es/search"
+)
+
+func TestNewQueryTerms(t *testing.T) {
+	queryTerms := search.NewQueryTerms("status", "new", "open")
+
+	require.False(t, queryTerms.IsEmpty())
+	require.Equal(t, search.QueryTermsKey, queryTerms.QueryType())
+
+	res, err := queryTerms.MarshalJSON()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"terms":{"status":["new","open"]}}`, string(res))
+}
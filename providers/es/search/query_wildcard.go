@@ -0,0 +1,54 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/wal1251/pkg/providers/es/api"
+	"github.com/wal1251/pkg/tools/serial"
+)
+
+const (
+	QueryWildcardKey                = "wildcard"
+	QueryWildcardValueKey           = "value"
+	QueryWildcardCaseInsensitiveKey = "case_insensitive"
+)
+
+var _ Query = (*QueryWildcard)(nil)
+
+// QueryWildcard запрос, который возвращает документы, содержащие в указанном поле term, соответствующий шаблону
+// с подстановочными символами "*" и "?".
+type QueryWildcard struct {
+	Field           string
+	Value           string
+	CaseInsensitive bool
+}
+
+func (q *QueryWildcard) MarshalJSON() ([]byte, error) {
+	return serial.ToBytes(api.Object{
+		q.QueryType(): api.Object{
+			q.Field: api.CreateObject(
+				api.Property(QueryWildcardValueKey, q.Value),
+				api.PropertyOmitempty(QueryWildcardCaseInsensitiveKey, q.CaseInsensitive),
+			),
+		},
+	}, serial.JSONEncode[api.Object])
+}
+
+func (q *QueryWildcard) IsEmpty() bool {
+	return q.Field == ""
+}
+
+func (q *QueryWildcard) QueryType() string {
+	return QueryWildcardKey
+}
+
+// NewQueryWildcardContains создает запрос документов, содержащих в поле field подстроку substr без учета регистра.
+func NewQueryWildcardContains(field string, substr string) *QueryWildcard {
+	return &QueryWildcard{
+		Field:           field,
+		Value:           "*" + wildcardEscaper.Replace(substr) + "*",
+		CaseInsensitive: true,
+	}
+}
+
+var wildcardEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`)
@@ -0,0 +1,20 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/providers/es/search"
+)
+
+func TestNewQueryWildcardContains(t *testing.T) {
+	queryWildcard := search.NewQueryWildcardContains("name", `f?o*`)
+
+	require.False(t, queryWildcard.IsEmpty())
+	require.Equal(t, search.QueryWildcardKey, queryWildcard.QueryType())
+
+	res, err := queryWildcard.MarshalJSON()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"wildcard":{"name":{"value":"*f\\?o\\**","case_insensitive":true}}}`, string(res))
+}
@@ -29,10 +29,12 @@ type (
 	}
 
 	Search struct {
-		From  *int                   `json:"from,omitempty"`
-		Size  *int                   `json:"size,omitempty"`
-		Query Query                  `json:"query,omitempty"`
-		Aggs  map[string]Aggregation `json:"aggs,omitempty"`
+		From        *int                   `json:"from,omitempty"`
+		Size        *int                   `json:"size,omitempty"`
+		Query       Query                  `json:"query,omitempty"`
+		Aggs        map[string]Aggregation `json:"aggs,omitempty"`
+		Sort        Sorts                  `json:"sort,omitempty"`
+		SearchAfter []any                  `json:"search_after,omitempty"` //nolint: tagliatelle
 	}
 
 	MultiSearchHeader struct {
@@ -75,6 +77,7 @@ type (
 		Index  string          `json:"_index"`  //nolint: tagliatelle
 		Score  float64         `json:"_score"`  //nolint: tagliatelle
 		Source json.RawMessage `json:"_source"` //nolint: tagliatelle
+		Sort   []any           `json:"sort,omitempty"`
 	}
 )
 
@@ -0,0 +1,72 @@
+package search
+
+import (
+	"github.com/wal1251/pkg/core/presenters/pagination"
+	"github.com/wal1251/pkg/providers/es/api"
+	"github.com/wal1251/pkg/tools/serial"
+)
+
+const (
+	SortOrderKey   = "order"
+	SortMissingKey = "missing"
+
+	SortMissingFirst = "_first" // Документы без значения поля в начале выдачи.
+	SortMissingLast  = "_last"  // Документы без значения поля в конце выдачи.
+)
+
+type (
+	// Sort сортировка выдачи по полю документа.
+	Sort struct {
+		Field   string
+		Order   pagination.SortDirection
+		Missing string // Положение документов без значения поля: SortMissingFirst, SortMissingLast.
+	}
+
+	// Sorts последовательность сортировок выдачи.
+	Sorts []Sort
+)
+
+func (s Sort) MarshalJSON() ([]byte, error) {
+	return serial.ToBytes(api.Object{
+		s.Field: api.CreateObject(
+			api.PropertyOmitempty(SortOrderKey, s.Order),
+			api.PropertyOmitempty(SortMissingKey, s.Missing),
+		),
+	}, serial.JSONEncode[api.Object])
+}
+
+// NewSorts возвращает сортировки выдачи по ключам keys.
+func NewSorts(keys ...pagination.SortKey) Sorts {
+	sorts := make(Sorts, len(keys))
+
+	for i, key := range keys {
+		sorts[i] = Sort{Field: key.Field, Order: key.Direction}
+
+		switch key.Nulls {
+		case pagination.NullsFirst:
+			sorts[i].Missing = SortMissingFirst
+		case pagination.NullsLast:
+			sorts[i].Missing = SortMissingLast
+		}
+	}
+
+	return sorts
+}
+
+// NewSortsFromQuery возвращает сортировки выдачи по параметрам сортировки sort с направлением каждого поля.
+func NewSortsFromQuery(sort pagination.SortQuery) Sorts {
+	return NewSorts(pagination.SortKeys(sort)...)
+}
+
+// Keyset задает выборку страницы курсорной пагинации: порядок keyset.Order(), значения search_after граничного
+// документа курсора и размер keyset.FetchLimit(). Значения ключей курсора удобно получать из Hit.Sort.
+func (s *Search) Keyset(keyset *pagination.Keyset) *Search {
+	size := keyset.FetchLimit()
+
+	s.Sort = NewSorts(keyset.Order()...)
+	s.From = nil
+	s.Size = &size
+	s.SearchAfter = keyset.Values
+
+	return s
+}
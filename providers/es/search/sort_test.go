@@ -0,0 +1,54 @@
+package search_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/presenters/pagination"
+	"github.com/wal1251/pkg/providers/es/search"
+)
+
+func TestSearch_Keyset(t *testing.T) {
+	keys := []pagination.SortKey{{Field: "created", Direction: pagination.SortDesc}, {Field: "id", Direction: pagination.SortAsc}}
+
+	tests := []struct {
+		name   string
+		keyset *pagination.Keyset
+		want   string
+	}{
+		{
+			name:   "Первая страница",
+			keyset: &pagination.Keyset{Keys: keys, Limit: 10},
+			want:   `{"size":11,"sort":[{"created":{"order":"desc"}},{"id":{"order":"asc"}}]}`,
+		},
+		{
+			name:   "Предыдущая страница",
+			keyset: &pagination.Keyset{Keys: keys, Values: []any{1704067200000, "a"}, Backward: true, Limit: 10},
+			want:   `{"size":11,"sort":[{"created":{"order":"asc"}},{"id":{"order":"desc"}}],"search_after":[1704067200000,"a"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from := 20
+			query := (&search.Search{From: &from}).Keyset(tt.keyset)
+
+			res, err := json.Marshal(query)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.want, string(res))
+		})
+	}
+}
+
+func TestNewSortsFromQuery(t *testing.T) {
+	sorting, err := pagination.ParseSorting("priority:desc", "created")
+	require.NoError(t, err)
+
+	sorting.WithNulls(pagination.NullsFirst)
+
+	res, err := json.Marshal(search.NewSortsFromQuery(sorting))
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"priority":{"order":"desc","missing":"_first"}},{"created":{"order":"asc","missing":"_first"}}]`, string(res))
+}
@@ -53,7 +53,11 @@ func (p *Producer) Client() *confluent.Producer {
 func (p *Producer) send(ctx context.Context, message *kafka.Message, isSync bool) error {
 	logs.LocalContext(p).To(logs.FromContext(ctx).Debug).Msg("sending message")
 
-	kafkaMessage, err := MakeMessage(message)
+	// Значения контекста дописываются в копию, сообщение вызывающей стороны не изменяется.
+	outgoing := *message
+	kafka.InjectContext(ctx, &outgoing)
+
+	kafkaMessage, err := MakeMessage(&outgoing)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,100 @@
+package kafka
+
+import (
+	"context"
+	"maps"
+	"strings"
+
+	"github.com/wal1251/pkg/core/bus"
+	"github.com/wal1251/pkg/core/ctxs"
+	"github.com/wal1251/pkg/tools/collections"
+)
+
+var _ ctxs.Carrier = HeadersCarrier{}
+
+type (
+	// HeadersCarrier носитель значений контекста в заголовках сообщения KAFKA, см. ctxs.Carrier.
+	HeadersCarrier struct {
+		Message *Message
+	}
+
+	// preservingCarrier носитель, не перезаписывающий заголовки, установленные в сообщении original.
+	preservingCarrier struct {
+		HeadersCarrier
+		original HeadersCarrier
+	}
+)
+
+// Transport см. ctxs.Carrier.
+func (c HeadersCarrier) Transport() ctxs.Transport {
+	return ctxs.TransportKafka
+}
+
+// Get см. ctxs.Carrier. Ключи заголовков сравниваются без учета регистра.
+func (c HeadersCarrier) Get(key string) string {
+	if value := c.Message.Header(key); value != "" {
+		return value
+	}
+
+	for name, values := range c.Message.Headers {
+		if strings.EqualFold(name, key) && len(values) > 0 {
+			return string(values[0])
+		}
+	}
+
+	return ""
+}
+
+// Set см. ctxs.Carrier.
+func (c HeadersCarrier) Set(key, value string) {
+	if c.Message.Headers == nil {
+		c.Message.Headers = make(collections.MultiMap[string, []byte])
+	}
+
+	c.Message.Headers[key] = [][]byte{[]byte(value)}
+}
+
+// Keys см. ctxs.Carrier.
+func (c HeadersCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.Message.Headers))
+	for key := range c.Message.Headers {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Set см. ctxs.Carrier.
+func (c preservingCarrier) Set(key, value string) {
+	if c.original.Get(key) == "" {
+		c.HeadersCarrier.Set(key, value)
+	}
+}
+
+// InjectContext записывает в заголовки сообщения значения контекста, см. ctxs.Inject. Заголовки, уже установленные в
+// сообщении, не перезаписываются. Заголовки дополняются в копии, исходная коллекция message.Headers не изменяется.
+func InjectContext(ctx context.Context, message *Message) {
+	original := HeadersCarrier{Message: &Message{Headers: message.Headers}}
+
+	message.Headers = maps.Clone(message.Headers)
+	ctxs.Inject(ctx, preservingCarrier{HeadersCarrier: HeadersCarrier{Message: message}, original: original})
+}
+
+// ExtractContext возвращает контекст, дополненный значениями из заголовков сообщения, см. ctxs.Extract.
+func ExtractContext(ctx context.Context, message *Message) context.Context {
+	return ctxs.Extract(ctx, HeadersCarrier{Message: message})
+}
+
+// WithContextPropagation возвращает посредника получателя, который передает каждое сообщение дальше с контекстом,
+// дополненным значениями из заголовков сообщения.
+func WithContextPropagation() bus.SubscriberMiddlewareFn[*Message] {
+	return func(ctx context.Context, messages []*Message, next bus.Subscriber[*Message]) error {
+		for _, message := range messages {
+			if err := next.Publish(ExtractContext(ctx, message), message); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,75 @@
+package kafka_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/bus"
+	"github.com/wal1251/pkg/core/ctxs"
+	"github.com/wal1251/pkg/providers/kafka"
+	"github.com/wal1251/pkg/tools/collections"
+)
+
+func TestInjectContext(t *testing.T) {
+	source := context.WithValue(context.Background(), middleware.RequestIDKey, "req-1")
+	source = context.WithValue(source, ctxs.UserInfoKey, map[string]any{"user_id": "42", "name": "John"}) //nolint:staticcheck
+
+	tests := []struct {
+		name          string
+		headers       collections.MultiMap[string, []byte]
+		wantRequestID string
+	}{
+		{
+			name:          "Без заголовков",
+			wantRequestID: "req-1",
+		},
+		{
+			name:          "Заголовок вызывающей стороны не перезаписывается",
+			headers:       collections.MultiMap[string, []byte]{"x-request-id": {[]byte("caller")}},
+			wantRequestID: "caller",
+		},
+		{
+			name:          "Ключи заголовков сравниваются без учета регистра",
+			headers:       collections.MultiMap[string, []byte]{"X-Request-Id": {[]byte("caller")}},
+			wantRequestID: "caller",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := tt.headers
+			size := len(headers)
+
+			message := &kafka.Message{Headers: headers}
+			kafka.InjectContext(source, message)
+
+			assert.Len(t, headers, size, "caller headers must not be modified")
+			assert.Equal(t, "42", message.Header("user_id"))
+			assert.Empty(t, message.Header("name"), "personal data is not propagated by default")
+
+			ctx := kafka.ExtractContext(context.Background(), message)
+			assert.Equal(t, tt.wantRequestID, ctx.Value(middleware.RequestIDKey))
+			assert.Equal(t, map[string]any{"user_id": "42"}, ctx.Value(ctxs.UserInfoKey))
+		})
+	}
+}
+
+func TestWithContextPropagation(t *testing.T) {
+	message := &kafka.Message{}
+	kafka.InjectContext(context.WithValue(context.Background(), middleware.RequestIDKey, "req-1"), message)
+
+	var requestID any
+
+	subscriber := bus.SubscriberFn[*kafka.Message](func(ctx context.Context, _ ...*kafka.Message) error {
+		requestID = ctx.Value(middleware.RequestIDKey)
+
+		return nil
+	})
+
+	require.NoError(t, kafka.WithContextPropagation()(context.Background(), []*kafka.Message{message}, subscriber))
+	assert.Equal(t, "req-1", requestID)
+}
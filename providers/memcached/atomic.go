@@ -0,0 +1,139 @@
+package memcached
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/wal1251/pkg/core/memorystore"
+)
+
+var (
+	_ memorystore.Counter           = (*Client)(nil)
+	_ memorystore.ConditionalSetter = (*Client)(nil)
+	_ memorystore.CompareAndSwapper = (*Client)(nil)
+	_ memorystore.Expirer           = (*Client)(nil)
+)
+
+// Increment см. memorystore.Counter. Счетчики Memcached беззнаковые: значение не может стать отрицательным,
+// уменьшение ниже нуля дает ноль.
+func (c *Client) Increment(_ context.Context, key string, delta int64, expiration time.Duration) (int64, error) {
+	value, err := c.incrDecr(key, delta)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		// Счетчик еще не создан: создаем его, если не успел другой экземпляр, иначе повторяем операцию.
+		err = c.client.Add(&memcache.Item{
+			Key:        key,
+			Value:      []byte(strconv.FormatInt(max(0, delta), 10)),
+			Expiration: seconds(expiration),
+		})
+		if err == nil {
+			return max(0, delta), nil
+		}
+
+		if errors.Is(err, memcache.ErrNotStored) {
+			value, err = c.incrDecr(key, delta)
+		}
+	}
+
+	if err != nil {
+		if isNonNumeric(err) {
+			return 0, fmt.Errorf("can't increment memcache key %s: %w", key, memorystore.ErrNotInteger)
+		}
+
+		return 0, fmt.Errorf("can't increment memcache key %s: %w", key, err)
+	}
+
+	return int64(value), nil //nolint:gosec
+}
+
+// Decrement см. memorystore.Counter.
+func (c *Client) Decrement(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, error) {
+	return c.Increment(ctx, key, -delta, expiration)
+}
+
+// SetIfNotExists см. memorystore.ConditionalSetter.
+func (c *Client) SetIfNotExists(_ context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	err = c.client.Add(&memcache.Item{Key: key, Value: data, Expiration: seconds(expiration)})
+	if err != nil {
+		if errors.Is(err, memcache.ErrNotStored) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("can't add memcache key %s: %w", key, err)
+	}
+
+	return true, nil
+}
+
+// CompareAndSwap см. memorystore.CompareAndSwapper. Текущее значение читается и заменяется командой cas, поэтому
+// параллельное изменение ключа между чтением и заменой также приводит к false.
+func (c *Client) CompareAndSwap(_ context.Context, key string, old *memorystore.Value, value any, expiration time.Duration) (bool, error) {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	item, err := c.client.Get(key)
+	if err != nil {
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("can't get memcache key %s: %w", key, err)
+	}
+
+	if !bytes.Equal(item.Value, old.RawBytes()) {
+		return false, nil
+	}
+
+	item.Value = data
+	item.Expiration = seconds(expiration)
+
+	if err = c.client.CompareAndSwap(item); err != nil {
+		if errors.Is(err, memcache.ErrCASConflict) || errors.Is(err, memcache.ErrNotStored) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("can't swap memcache key %s: %w", key, err)
+	}
+
+	return true, nil
+}
+
+// Expire см. memorystore.Expirer.
+func (c *Client) Expire(_ context.Context, key string, expiration time.Duration) (bool, error) {
+	err := c.client.Touch(key, seconds(expiration))
+	if err != nil {
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("can't touch memcache key %s: %w", key, err)
+	}
+
+	return true, nil
+}
+
+func (c *Client) incrDecr(key string, delta int64) (uint64, error) {
+	if delta < 0 {
+		return c.client.Decrement(key, uint64(-delta)) //nolint:wrapcheck
+	}
+
+	return c.client.Increment(key, uint64(delta)) //nolint:wrapcheck
+}
+
+// isNonNumeric проверяет ответ сервера на попытку изменить нечисловое значение.
+func isNonNumeric(err error) bool {
+	return strings.Contains(err.Error(), "non-numeric value")
+}
@@ -0,0 +1,112 @@
+package memcached
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/wal1251/pkg/core/memorystore/lock"
+)
+
+var _ lock.Backend = (*LockBackend)(nil)
+
+// LockBackend хранилище распределенных блокировок в Memcached, см. lock.Backend. Блокировка захватывается атомарно
+// командой add, а продление и освобождение проверяют владельца и выполняются командой cas. Токены ограждения хранятся
+// в ключах с суффиксом ":fence" в течение lock.FenceTTL с последнего захвата. Срок аренды округляется вверх до секунд.
+//
+// Memcached может вытеснить счетчик токенов при нехватке памяти раньше срока, и токены ключа начнутся заново, поэтому
+// монотонность токенов ограждения не гарантируется. Если ресурс полагается на токены, используйте providers/redis.
+type LockBackend struct {
+	client *memcache.Client
+}
+
+// Acquire см. lock.Backend.
+func (b *LockBackend) Acquire(_ context.Context, key, owner string, ttl time.Duration) (int64, bool, error) {
+	err := b.client.Add(&memcache.Item{Key: key, Value: []byte(owner), Expiration: seconds(max(time.Second, ttl))})
+	if err != nil {
+		if errors.Is(err, memcache.ErrNotStored) {
+			return 0, false, nil
+		}
+
+		return 0, false, fmt.Errorf("can't acquire memcache lock %s: %w", key, err)
+	}
+
+	token, err := b.nextToken(key+":fence", seconds(lock.FenceTTL(ttl)))
+	if err != nil {
+		_ = b.client.Delete(key)
+
+		return 0, false, err
+	}
+
+	return token, true, nil
+}
+
+// Refresh см. lock.Backend.
+func (b *LockBackend) Refresh(_ context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	return b.compareAndSwap(key, owner, seconds(max(time.Second, ttl)))
+}
+
+// Release см. lock.Backend. Блокировка освобождается заменой с отрицательным сроком хранения, после которой ключ сразу
+// считается истекшим.
+func (b *LockBackend) Release(_ context.Context, key, owner string) (bool, error) {
+	return b.compareAndSwap(key, owner, -1)
+}
+
+func (b *LockBackend) compareAndSwap(key, owner string, expiration int32) (bool, error) {
+	item, err := b.client.Get(key)
+	if err != nil {
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("can't get memcache lock %s: %w", key, err)
+	}
+
+	if string(item.Value) != owner {
+		return false, nil
+	}
+
+	item.Expiration = expiration
+
+	if err = b.client.CompareAndSwap(item); err != nil {
+		if errors.Is(err, memcache.ErrCASConflict) || errors.Is(err, memcache.ErrNotStored) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("can't update memcache lock %s: %w", key, err)
+	}
+
+	return true, nil
+}
+
+func (b *LockBackend) nextToken(key string, expiration int32) (int64, error) {
+	token, err := b.client.Increment(key, 1)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		// Счетчик еще не создан: создаем его, если не успел другой экземпляр, и повторяем.
+		err = b.client.Add(&memcache.Item{Key: key, Value: []byte("0"), Expiration: expiration})
+		if err != nil && !errors.Is(err, memcache.ErrNotStored) {
+			return 0, fmt.Errorf("can't create memcache fencing token %s: %w", key, err)
+		}
+
+		token, err = b.client.Increment(key, 1)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("can't increment memcache fencing token %s: %w", key, err)
+	}
+
+	// Increment не продлевает срок хранения счетчика.
+	if err = b.client.Touch(key, expiration); err != nil {
+		return 0, fmt.Errorf("can't touch memcache fencing token %s: %w", key, err)
+	}
+
+	return int64(token), nil //nolint:gosec
+}
+
+// LockBackend возвращает хранилище распределенных блокировок на базе клиента.
+func (c *Client) LockBackend() *LockBackend {
+	return &LockBackend{client: c.client}
+}
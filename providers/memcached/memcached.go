@@ -1,32 +1,41 @@
 // Package memcached.
-// Представляет собой адаптер memcached-клиента.
+// Представляет собой адаптер memcached-клиента. Кроме memorystore.Manager клиент поддерживает memorystore.Counter,
+// memorystore.ConditionalSetter, memorystore.CompareAndSwapper и memorystore.Expirer. memorystore.TTLReader и
+// memorystore.Scanner не поддерживаются: Memcached не позволяет узнать оставшееся время жизни ключа и перебрать ключи.
 package memcached
 
 import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/bradfitz/gomemcache/memcache"
 
 	"github.com/wal1251/pkg/core/logs"
 	"github.com/wal1251/pkg/core/memorystore"
-	"github.com/wal1251/pkg/tools/serial"
 )
 
+// maxRelativeExpiration максимальное время жизни, которое Memcached принимает в секундах от текущего момента.
+const maxRelativeExpiration = 30 * 24 * time.Hour
+
 var _ memorystore.Manager = (*Client)(nil)
 
 // Client является клиентом для работы с Memcached.
 type Client struct {
 	client *memcache.Client // Низкоуровневый клиент Memcached.
 	cfg    *Config
+	codec  memorystore.Codec
 }
 
+// Option опция Client.
+type Option func(*Client)
+
 // NewClient инициализирует и возвращает новый экземпляр клиента Memcached.
 // Проверяет доступность Memcached и устанавливает соединение.
 // В случае, когда Memcached недоступен, возвращает ошибку.
-func NewClient(_ context.Context, cfg *Config) (*Client, error) {
+func NewClient(_ context.Context, cfg *Config, opts ...Option) (*Client, error) {
 	client := memcache.New(cfg.Hosts...)
 
 	err := client.Ping()
@@ -34,12 +43,25 @@ func NewClient(_ context.Context, cfg *Config) (*Client, error) {
 		return nil, fmt.Errorf("failed to connect to memcache: %w", err)
 	}
 
-	return &Client{client: client, cfg: cfg}, nil
+	result := &Client{client: client, cfg: cfg, codec: memorystore.JSONCodec{}}
+	for _, opt := range opts {
+		opt(result)
+	}
+
+	return result, nil
+}
+
+// WithCodec устанавливает кодек значений, по умолчанию memorystore.JSONCodec. Кодек memorystore.EnvelopeCodec
+// позволяет сжимать и шифровать значения, а также менять формат без очистки хранилища.
+func WithCodec(codec memorystore.Codec) Option {
+	return func(c *Client) {
+		c.codec = codec
+	}
 }
 
 // Set сохраняет значение по ключу в Memcached с заданным временем истечения.
 func (c *Client) Set(_ context.Context, key string, value any, expiration time.Duration) error {
-	data, err := serial.ToBytes(value, serial.JSONEncode[any])
+	data, err := c.codec.Marshal(value)
 	if err != nil {
 		return err
 	}
@@ -47,7 +69,7 @@ func (c *Client) Set(_ context.Context, key string, value any, expiration time.D
 	item := &memcache.Item{
 		Key:        key,
 		Value:      data,
-		Expiration: int32(expiration.Seconds()),
+		Expiration: seconds(expiration),
 	}
 
 	err = c.client.Set(item)
@@ -58,6 +80,20 @@ func (c *Client) Set(_ context.Context, key string, value any, expiration time.D
 	return nil
 }
 
+// seconds переводит время жизни в формат Memcached: число секунд с округлением вверх, 0 - без срока истечения. Время
+// жизни больше 30 дней Memcached считает моментом времени Unix, поэтому оно передается как момент истечения.
+func seconds(ttl time.Duration) int32 {
+	if ttl <= 0 {
+		return 0
+	}
+
+	if ttl > maxRelativeExpiration {
+		return int32(min(time.Now().Add(ttl).Unix(), math.MaxInt32)) //nolint:gosec
+	}
+
+	return int32((ttl + time.Second - 1) / time.Second) //nolint:gosec
+}
+
 // Get извлекает и возвращает значение по ключу из Memcached.
 // Если ключ не существует, возвращает ошибку memorystore.ErrKeyNotFound.
 func (c *Client) Get(_ context.Context, key string) (*memorystore.Value, error) {
@@ -70,7 +106,7 @@ func (c *Client) Get(_ context.Context, key string) (*memorystore.Value, error)
 		return nil, fmt.Errorf("can't get memcache key %s: %w", key, err)
 	}
 
-	return memorystore.NewValue(item.Value), nil
+	return memorystore.NewCodecValue(item.Value, c.codec), nil
 }
 
 // GetList извлекает и возвращает значения для списка ключей из Memcached.
@@ -100,7 +136,7 @@ func (c *Client) GetList(_ context.Context, keys ...string) ([]*memorystore.Valu
 			continue
 		}
 
-		results[idx] = memorystore.NewValue(item.Value)
+		results[idx] = memorystore.NewCodecValue(item.Value, c.codec)
 	}
 
 	return results, nil
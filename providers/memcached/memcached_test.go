@@ -0,0 +1,33 @@
+package memcached
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeconds(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want int32
+	}{
+		{name: "Без срока истечения", ttl: 0, want: 0},
+		{name: "Отрицательное время жизни", ttl: -time.Second, want: 0},
+		{name: "Округление вверх", ttl: 1500 * time.Millisecond, want: 2},
+		{name: "Меньше секунды", ttl: time.Millisecond, want: 1},
+		{name: "30 дней", ttl: maxRelativeExpiration, want: int32(maxRelativeExpiration / time.Second)},
+		{name: "Переполнение", ttl: time.Duration(math.MaxInt64), want: math.MaxInt32},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, seconds(tt.ttl))
+		})
+	}
+
+	// Время жизни больше 30 дней передается как момент истечения.
+	assert.InDelta(t, time.Now().Add(31*24*time.Hour).Unix(), seconds(31*24*time.Hour), 1)
+}
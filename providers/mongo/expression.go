@@ -0,0 +1,98 @@
+package mongo
+
+import (
+	"regexp"
+
+	"github.com/google/uuid"
+
+	"github.com/wal1251/pkg/core/presenters/filter"
+)
+
+// Expression добавляет условия выражения фильтрации, проверенного схемой (см. filter.Schema). Для пустого выражения
+// условие не добавляется. Значения типа filter.TypeUUID сравниваются как строки, значение поля FieldID в виде hex
+// строки преобразуется в ObjectID. Например:
+//
+//	expression, err := schema.Parse(raw)
+//	if err != nil {
+//		return nil, err
+//	}
+//
+//	documents, err := storage.FindMany(ctx, "items", mongo.NewFilter().Expression(expression), nil)
+//
+// .
+func (f *Filter) Expression(expression *filter.Expression) *Filter {
+	if expression.IsEmpty() {
+		return f
+	}
+
+	conditions := make([]*Filter, len(expression.Conditions))
+	for i, condition := range expression.Conditions {
+		conditions[i] = NewFilter().Condition(condition)
+	}
+
+	if len(conditions) == 1 {
+		f.value = append(f.value, conditions[0].value...)
+
+		return f
+	}
+
+	return f.And(conditions...)
+}
+
+// Condition добавляет условие выражения фильтрации, см. Expression.
+func (f *Filter) Condition(condition filter.Condition) *Filter {
+	field := condition.Column
+	values := make([]interface{}, len(condition.Values))
+
+	for i, value := range condition.Values {
+		values[i] = expressionValue(field, value)
+	}
+
+	var value interface{}
+	if len(values) > 0 {
+		value = values[0]
+	}
+
+	switch condition.Operator {
+	case filter.OpEq:
+		return f.Eq(field, value)
+	case filter.OpNe:
+		return f.Ne(field, value)
+	case filter.OpGt:
+		return f.Gt(field, value)
+	case filter.OpGte:
+		return f.Gte(field, value)
+	case filter.OpLt:
+		return f.Lt(field, value)
+	case filter.OpLte:
+		return f.Lte(field, value)
+	case filter.OpIn:
+		return f.In(field, values...)
+	case filter.OpNin:
+		return f.Nin(field, values...)
+	case filter.OpLike:
+		substr, _ := value.(string)
+
+		return f.RegexFold(field, regexp.QuoteMeta(substr))
+	case filter.OpRegex:
+		pattern, _ := value.(string)
+
+		return f.RegexFold(field, pattern)
+	case filter.OpNull:
+		if isNull, _ := value.(bool); isNull {
+			return f.Eq(field, nil)
+		}
+
+		return f.Ne(field, nil)
+	default:
+		return f.In(field, []interface{}{}...)
+	}
+}
+
+func expressionValue(field string, value any) any {
+	if id, ok := value.(uuid.UUID); ok {
+		return id.String()
+	}
+
+	return keysetValue(field, value)
+}
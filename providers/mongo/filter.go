@@ -35,6 +35,48 @@ func (f *Filter) Lt(field string, value interface{}) *Filter {
 	return f
 }
 
+// Ne добавляет условие "не равно" (field != value).
+func (f *Filter) Ne(field string, value interface{}) *Filter {
+	f.value = append(f.value, bson.E{Key: field, Value: bson.M{"$ne": value}})
+
+	return f
+}
+
+// Gte добавляет условие "больше или равно" (field >= value).
+func (f *Filter) Gte(field string, value interface{}) *Filter {
+	f.value = append(f.value, bson.E{Key: field, Value: bson.M{"$gte": value}})
+
+	return f
+}
+
+// Lte добавляет условие "меньше или равно" (field <= value).
+func (f *Filter) Lte(field string, value interface{}) *Filter {
+	f.value = append(f.value, bson.E{Key: field, Value: bson.M{"$lte": value}})
+
+	return f
+}
+
+// In добавляет условие "равно одному из значений" (field IN values).
+func (f *Filter) In(field string, values ...interface{}) *Filter {
+	f.value = append(f.value, bson.E{Key: field, Value: bson.M{"$in": bson.A(values)}})
+
+	return f
+}
+
+// Nin добавляет условие "не равно ни одному из значений" (field NOT IN values).
+func (f *Filter) Nin(field string, values ...interface{}) *Filter {
+	f.value = append(f.value, bson.E{Key: field, Value: bson.M{"$nin": bson.A(values)}})
+
+	return f
+}
+
+// RegexFold добавляет условие соответствия регулярному выражению pattern без учета регистра.
+func (f *Filter) RegexFold(field string, pattern string) *Filter {
+	f.value = append(f.value, bson.E{Key: field, Value: bson.M{"$regex": pattern, "$options": "i"}})
+
+	return f
+}
+
 // And добавляет условие "и" (логическое $and).
 func (f *Filter) And(filters ...*Filter) *Filter {
 	var andConditions bson.A
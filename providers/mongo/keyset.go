@@ -0,0 +1,67 @@
+package mongo
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/wal1251/pkg/core/presenters/pagination"
+)
+
+const FieldID = "_id" // Поле идентификатора документа.
+
+// Keyset добавляет условие выборки страницы курсорной пагинации: документы, следующие за граничным документом
+// курсора в порядке keyset.Order(). Для первой страницы условие не добавляется. Значение ключа FieldID передается в
+// курсор строкой (ID.Hex()) и преобразуется обратно в ObjectID. Например:
+//
+//	keyset, err := query.Keyset(signer, pagination.SortKeys(sorting, mongo.FieldID))
+//	if err != nil {
+//		return nil, err
+//	}
+//
+//	documents, err := storage.FindMany(ctx, "items", mongo.NewFilter().Keyset(keyset), nil, mongo.KeysetOptions(keyset))
+//
+// .
+func (f *Filter) Keyset(keyset *pagination.Keyset) *Filter {
+	terms := keyset.Terms()
+	if len(terms) == 0 {
+		return f
+	}
+
+	conditions := make(bson.A, len(terms))
+
+	for i, term := range terms {
+		condition := make(bson.D, 0, len(term.Equal)+1)
+		for _, equal := range term.Equal {
+			condition = append(condition, bson.E{Key: equal.Field, Value: keysetValue(equal.Field, equal.Value)})
+		}
+
+		operator := "$lt"
+		if term.Greater {
+			operator = "$gt"
+		}
+
+		condition = append(condition, bson.E{Key: term.Field, Value: bson.M{operator: keysetValue(term.Field, term.Value)}})
+		conditions[i] = condition
+	}
+
+	f.value = append(f.value, bson.E{Key: "$or", Value: conditions})
+
+	return f
+}
+
+// KeysetOptions возвращает параметры выборки страницы курсорной пагинации: порядок keyset.Order() и ограничение
+// keyset.FetchLimit().
+func KeysetOptions(keyset *pagination.Keyset) *options.FindOptions {
+	return NewSort().Keys(keyset.Order()...).FindOptions().SetLimit(int64(keyset.FetchLimit()))
+}
+
+func keysetValue(field string, value any) any {
+	if hex, ok := value.(string); ok && field == FieldID {
+		if id, err := primitive.ObjectIDFromHex(hex); err == nil {
+			return id
+		}
+	}
+
+	return value
+}
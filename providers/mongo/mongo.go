@@ -45,8 +45,9 @@ type Storage interface {
 	FindOne(ctx context.Context, collection string, filter *Filter, projection *Projection) (*Document, error)
 
 	// FindMany находит несколько документов в указанной коллекции по фильтру и проекции.
+	// Дополнительные параметры выборки (сортировка, ограничение) задаются opts, см. KeysetOptions.
 	// Возвращает список найденных документов или ошибку в случае сбоя.
-	FindMany(ctx context.Context, collection string, filter *Filter, projection *Projection) ([]*Document, error)
+	FindMany(ctx context.Context, collection string, filter *Filter, projection *Projection, opts ...*options.FindOptions) ([]*Document, error)
 
 	// UpdateOne обновляет один документ в указанной коллекции, соответствующий фильтру.
 	// Возвращает количество обновлённых документов (0 или 1) или ошибку в случае сбоя.
@@ -154,15 +155,21 @@ func (s *StorageImpl) FindOne(ctx context.Context, collection string, filter *Fi
 	return &document, nil
 }
 
-func (s *StorageImpl) FindMany(ctx context.Context, collection string, filter *Filter, projection *Projection) ([]*Document, error) {
+func (s *StorageImpl) FindMany(
+	ctx context.Context,
+	collection string,
+	filter *Filter,
+	projection *Projection,
+	opts ...*options.FindOptions,
+) ([]*Document, error) {
 	coll := s.DB.Collection(collection)
 
-	opts := options.Find()
+	findOpts := options.Find()
 	if projection != nil {
-		opts.SetProjection(projection.Value())
+		findOpts.SetProjection(projection.Value())
 	}
 
-	cur, err := coll.Find(ctx, filter.Value(), opts)
+	cur, err := coll.Find(ctx, filter.Value(), append([]*options.FindOptions{findOpts}, opts...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find documents: %w", err)
 	}
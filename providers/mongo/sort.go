@@ -0,0 +1,63 @@
+package mongo
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/wal1251/pkg/core/presenters/pagination"
+)
+
+type (
+	// Sort оборачивает bson.D порядка сортировки выдачи.
+	Sort struct {
+		value bson.D
+	}
+)
+
+// NewSort создает новый пустой порядок сортировки.
+func NewSort() *Sort {
+	return &Sort{value: bson.D{}}
+}
+
+// NewSortFromQuery создает порядок сортировки по параметрам сортировки sort с направлением каждого поля.
+func NewSortFromQuery(sort pagination.SortQuery) *Sort {
+	return NewSort().Keys(pagination.SortKeys(sort)...)
+}
+
+// Asc добавляет сортировку по возрастанию значений поля.
+func (s *Sort) Asc(field string) *Sort {
+	s.value = append(s.value, bson.E{Key: field, Value: 1})
+
+	return s
+}
+
+// Desc добавляет сортировку по убыванию значений поля.
+func (s *Sort) Desc(field string) *Sort {
+	s.value = append(s.value, bson.E{Key: field, Value: -1})
+
+	return s
+}
+
+// Keys добавляет сортировку по ключам keys. Положение пустых значений не настраивается: MongoDB упорядочивает null
+// перед остальными значениями по возрастанию и после них по убыванию.
+func (s *Sort) Keys(keys ...pagination.SortKey) *Sort {
+	for _, key := range keys {
+		if key.Direction == pagination.SortDesc {
+			s.Desc(key.Field)
+		} else {
+			s.Asc(key.Field)
+		}
+	}
+
+	return s
+}
+
+// FindOptions возвращает параметры выборки с порядком сортировки, см. Storage.FindMany.
+func (s *Sort) FindOptions() *options.FindOptions {
+	return options.Find().SetSort(s.value)
+}
+
+// Value возвращает внутренний bson.D.
+func (s *Sort) Value() bson.D {
+	return s.value
+}
@@ -6,13 +6,21 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/logs"
 	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/core/memorystore/lock"
+	"github.com/wal1251/pkg/core/security"
+	"github.com/wal1251/pkg/core/security/lockout"
 	"github.com/wal1251/pkg/providers/otp/generator"
 )
 
 const (
 	memoryStoreKeyPrefixOTP              = "otp:"
 	memoryStorePrefixOTPValidateAttempts = "otp_validate_attempts:"
+	lockKeyPrefixOTPSend                 = "otp_send:"
+
+	sendLockTTL = 30 * time.Second // Время аренды блокировки отправки, должно превышать время отправки кода.
 )
 
 var (
@@ -47,8 +55,13 @@ type Manager struct {
 	memoryStore memorystore.MemoryStore
 
 	config *Config
+	guard  *lockout.Guard
+	locker *lock.Locker
 }
 
+// Option опция Manager.
+type Option func(*Manager)
+
 // Send генерирует одноразовый пароль (OTP), отправляет его целевому адресату и возвращает
 // задержку перед следующей попыткой отправки OTP. Если достигнуто максимальное количество
 // попыток, функция вернет ошибку ErrTooManyAttempts.
@@ -64,6 +77,10 @@ type Manager struct {
 //   - error: Ошибка, если что-то пошло не так, например, при генерации OTP, сохранении
 //     его в хранилище данных в памяти или отправке OTP.
 //
+// Если подключены блокировки (см. WithSendLock), одновременные отправки одному адресату (например, с разных
+// экземпляров приложения) выполняются по очереди: пока код отправляется, другие попытки завершаются ошибкой
+// ErrTooFrequentAttempts.
+//
 // Пример использования:
 //
 //	delay, err := manager.Send(ctx, "+79123456789")
@@ -73,6 +90,29 @@ type Manager struct {
 //	    log.Info("OTP sent successfully. Next attempt in:", delay)
 //	}
 func (m *Manager) Send(ctx context.Context, target string, msgTemplate string) (*time.Duration, error) {
+	if m.locker == nil {
+		return m.send(ctx, target, msgTemplate)
+	}
+
+	lease, err := m.locker.TryLock(ctx, lockKeyPrefixOTPSend+target, sendLockTTL)
+	if err != nil {
+		if errors.Is(err, lock.ErrNotAcquired) {
+			return nil, ErrTooFrequentAttempts
+		}
+
+		return nil, fmt.Errorf("failed to lock OTP sending: %w", err)
+	}
+
+	defer func() {
+		if err := lease.Release(context.WithoutCancel(ctx)); err != nil {
+			logs.FromContext(ctx).Warn().Err(err).Msg("failed to unlock OTP sending")
+		}
+	}()
+
+	return m.send(ctx, target, msgTemplate)
+}
+
+func (m *Manager) send(ctx context.Context, target string, msgTemplate string) (*time.Duration, error) {
 	key := memoryStoreKeyPrefixOTP + target
 	// Попытка получить существующий OTP из хранилища
 	rawOTP, err := m.memoryStore.Get(ctx, key)
@@ -123,9 +163,8 @@ func (m *Manager) Send(ctx context.Context, target string, msgTemplate string) (
 	}
 
 	// Обнуление количества попыток валидации OTP
-	otpValidateKey := memoryStorePrefixOTPValidateAttempts + target
-	if err := m.memoryStore.Set(ctx, otpValidateKey, 0, m.config.Lifetime); err != nil {
-		return nil, fmt.Errorf("failed to set OTP validate count in memory store: %w", err)
+	if err := m.resetOTPValidateAttemptsCount(ctx, target); err != nil {
+		return nil, err
 	}
 
 	// Формирование и отправка сообщения с OTP
@@ -156,6 +195,9 @@ func (m *Manager) Send(ctx context.Context, target string, msgTemplate string) (
 //   - error: Ошибка, если OTP неверный или его срок действия истек, или если произошла
 //     ошибка при удалении OTP из хранилища.
 //
+// Если подключен lockout.Guard (см. WithLockout), неверные коды учитываются по адресату и IP адресу клиента, а попытки
+// во время блокировки отклоняются ошибкой errs.ErrTooManyRequests.
+//
 // Пример использования:
 //
 //	err := manager.Validate(ctx, "+79123456789", "123456")
@@ -165,6 +207,37 @@ func (m *Manager) Send(ctx context.Context, target string, msgTemplate string) (
 //	    log.Info("OTP validated successfully.")
 //	}
 func (m *Manager) Validate(ctx context.Context, target string, code string) error {
+	if m.guard == nil {
+		return m.validate(ctx, target, code)
+	}
+
+	attempt := lockout.Attempt{Method: security.AuthMethodOTP, Login: target, IP: security.ClientIP(ctx)}
+	if err := m.guard.Check(ctx, attempt); err != nil {
+		return err
+	}
+
+	err := m.validate(ctx, target, code)
+	switch {
+	case err == nil:
+		if err = m.guard.Success(ctx, attempt, security.Authentication{}); err != nil {
+			logs.FromContext(ctx).Warn().Err(err).Msg("failed to register successful OTP validation")
+		}
+
+		return nil
+	case errors.Is(err, ErrWrongCode):
+		if lockErr := m.guard.Failure(ctx, attempt, err); lockErr != nil {
+			if errs.ErrTooManyRequests.Is(lockErr) {
+				return lockErr
+			}
+
+			logs.FromContext(ctx).Warn().Err(lockErr).Msg("failed to register failed OTP validation")
+		}
+	}
+
+	return err
+}
+
+func (m *Manager) validate(ctx context.Context, target string, code string) error {
 	if err := m.checkOTPValidateAttemptsCount(ctx, target); err != nil {
 		return err
 	}
@@ -197,25 +270,74 @@ func (m *Manager) Validate(ctx context.Context, target string, code string) erro
 }
 
 // NewNumericOTPManager создает новый экземпляр Manager с генератором числовых OTP.
-func NewNumericOTPManager(sender sender, memoryStore memorystore.MemoryStore, config *Config) *Manager {
+func NewNumericOTPManager(sender sender, memoryStore memorystore.MemoryStore, config *Config, opts ...Option) *Manager {
 	otpGenerator := generator.NewNumericOTPGenerator(config.Length)
 
-	return NewManager(sender, memoryStore, config, otpGenerator)
+	return NewManager(sender, memoryStore, config, otpGenerator, opts...)
+}
+
+// WithLockout подключает защиту от перебора кодов, см. Manager.Validate.
+func WithLockout(guard *lockout.Guard) Option {
+	return func(m *Manager) {
+		m.guard = guard
+	}
+}
+
+// WithSendLock подключает распределенные блокировки отправки кодов, см. Manager.Send.
+func WithSendLock(locker *lock.Locker) Option {
+	return func(m *Manager) {
+		m.locker = locker
+	}
 }
 
 // NewManager создает новый экземпляр Manager.
-func NewManager(sender sender, memoryStore memorystore.MemoryStore, config *Config, generator generator.Generator) *Manager {
-	return &Manager{
+func NewManager(
+	sender sender,
+	memoryStore memorystore.MemoryStore,
+	config *Config,
+	generator generator.Generator,
+	opts ...Option,
+) *Manager {
+	manager := &Manager{
 		generator:   generator,
 		sender:      sender,
 		memoryStore: memoryStore,
 		config:      config,
 	}
+
+	for _, opt := range opts {
+		opt(manager)
+	}
+
+	return manager
+}
+
+func (m *Manager) resetOTPValidateAttemptsCount(ctx context.Context, target string) error {
+	otpValidateAttemptsKey := memoryStorePrefixOTPValidateAttempts + target
+
+	// Атомарный счетчик нельзя записать через Set, поэтому он сбрасывается удалением ключа.
+	if _, ok := m.memoryStore.(memorystore.Counter); ok {
+		if _, err := m.memoryStore.Delete(ctx, otpValidateAttemptsKey); err != nil {
+			return fmt.Errorf("failed to delete OTP validate count from memory store: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := m.memoryStore.Set(ctx, otpValidateAttemptsKey, 0, m.config.Lifetime); err != nil {
+		return fmt.Errorf("failed to set OTP validate count in memory store: %w", err)
+	}
+
+	return nil
 }
 
 func (m *Manager) checkOTPValidateAttemptsCount(ctx context.Context, target string) error {
 	otpValidateAttemptsKey := memoryStorePrefixOTPValidateAttempts + target
 
+	if counter, ok := m.memoryStore.(memorystore.Counter); ok {
+		return m.incrementOTPValidateAttemptsCount(ctx, counter, otpValidateAttemptsKey)
+	}
+
 	rawOTPValidateCount, err := m.memoryStore.Get(ctx, otpValidateAttemptsKey)
 	if err != nil && !errors.Is(err, memorystore.ErrKeyNotFound) {
 		return fmt.Errorf("failed to get OTP validate count from memory store: %w", err)
@@ -246,3 +368,26 @@ func (m *Manager) checkOTPValidateAttemptsCount(ctx context.Context, target stri
 
 	return nil
 }
+
+// incrementOTPValidateAttemptsCount учитывает попытку проверки атомарным счетчиком, поэтому параллельные попытки не
+// могут превысить ограничение MaxValidateAttempts.
+func (m *Manager) incrementOTPValidateAttemptsCount(ctx context.Context, counter memorystore.Counter, key string) error {
+	otpValidateCount, err := counter.Increment(ctx, key, 1, m.config.Lifetime)
+	if err != nil {
+		return fmt.Errorf("failed to increment OTP validate count in memory store: %w", err)
+	}
+
+	if otpValidateCount > int64(m.config.MaxValidateAttempts) {
+		return ErrTooManyAttempts
+	}
+
+	if otpValidateCount == int64(m.config.MaxValidateAttempts) {
+		if expirer, ok := m.memoryStore.(memorystore.Expirer); ok {
+			if _, err = expirer.Expire(ctx, key, m.config.ValidateBlockDuration); err != nil {
+				return fmt.Errorf("failed to set OTP validate count expiration in memory store: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
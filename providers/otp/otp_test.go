@@ -0,0 +1,192 @@
+package otp_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/errs"
+	"github.com/wal1251/pkg/core/memorystore/local"
+	"github.com/wal1251/pkg/core/memorystore/lock"
+	"github.com/wal1251/pkg/core/security/lockout"
+	"github.com/wal1251/pkg/providers/otp"
+	"github.com/wal1251/pkg/providers/otp/generator"
+)
+
+const (
+	target    = "+79123456789"
+	validCode = "0000" // Код генератора generator.NewTestDouble длины 4.
+	wrongCode = "1234"
+)
+
+type senderStub struct {
+	mu      sync.Mutex
+	sent    []string
+	entered chan struct{} // Сигнал о начале отправки, если задан.
+	release chan struct{} // Отправка ждет закрытия канала, если он задан.
+}
+
+func (s *senderStub) Send(_ context.Context, _ string, code string) error {
+	if s.entered != nil {
+		s.entered <- struct{}{}
+	}
+
+	if s.release != nil {
+		<-s.release
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sent = append(s.sent, code)
+
+	return nil
+}
+
+func newMemoryStore(t *testing.T, now func() time.Time) *local.Store {
+	t.Helper()
+
+	store, err := local.NewStore(&local.Config{}, local.WithClock(now))
+	require.NoError(t, err)
+
+	return store
+}
+
+func newConfig() *otp.Config {
+	return &otp.Config{
+		Length:                4,
+		Lifetime:              time.Hour,
+		MaxSendAttempts:       10,
+		SendBlockDuration:     time.Hour,
+		MaxValidateAttempts:   3,
+		ValidateBlockDuration: 10 * time.Minute,
+	}
+}
+
+func TestManager_Send_lock(t *testing.T) {
+	ctx := context.Background()
+	sender := &senderStub{entered: make(chan struct{}, 3), release: make(chan struct{})}
+	manager := otp.NewManager(sender, newMemoryStore(t, time.Now), newConfig(), generator.NewTestDouble(4),
+		otp.WithSendLock(lock.NewLocker(lock.NewMemoryBackend(nil))))
+
+	done := make(chan error)
+
+	go func() {
+		_, err := manager.Send(ctx, target, "")
+		done <- err
+	}()
+
+	// Пока первый код отправляется, повторная отправка тому же адресату отклоняется блокировкой, а не задержкой
+	// NextCodeDelay (она равна нулю).
+	<-sender.entered
+
+	_, err := manager.Send(ctx, target, "")
+	require.ErrorIs(t, err, otp.ErrTooFrequentAttempts)
+
+	close(sender.release)
+	require.NoError(t, <-done)
+
+	_, err = manager.Send(ctx, target, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{validCode, validCode}, sender.sent)
+}
+
+func TestManager_Validate(t *testing.T) {
+	type step struct {
+		advance time.Duration // Сдвиг часов хранилища перед шагом.
+		resend  bool          // Отправить новый код перед проверкой.
+		code    string
+		wantErr error
+	}
+
+	wrong := step{code: wrongCode, wantErr: otp.ErrWrongCode}
+
+	tests := []struct {
+		name    string
+		lockout *lockout.Config
+		steps   []step
+	}{
+		{
+			name:  "Верный код",
+			steps: []step{{code: validCode}},
+		},
+		{
+			name:  "Код проверяется однократно",
+			steps: []step{{code: validCode}, {code: validCode, wantErr: otp.ErrWrongCode}},
+		},
+		{
+			name:  "Последняя допустимая попытка",
+			steps: []step{wrong, wrong, {code: validCode}},
+		},
+		{
+			name:  "Превышено количество попыток",
+			steps: []step{wrong, wrong, wrong, {code: validCode, wantErr: otp.ErrTooManyAttempts}},
+		},
+		{
+			name: "Блокировка длится ValidateBlockDuration",
+			steps: []step{
+				wrong, wrong, wrong,
+				{advance: 10*time.Minute - time.Second, code: validCode, wantErr: otp.ErrTooManyAttempts},
+				{advance: time.Second, code: validCode},
+			},
+		},
+		{
+			name:  "Новый код сбрасывает счетчик попыток",
+			steps: []step{wrong, wrong, wrong, {resend: true, code: validCode}},
+		},
+		{
+			name:    "Блокировка перебора",
+			lockout: &lockout.Config{MaxUserAttempts: 2, MaxIPAttempts: 10, Window: time.Hour, Duration: time.Minute},
+			steps: []step{
+				wrong,
+				{code: wrongCode, wantErr: errs.ErrTooManyRequests},
+				{code: validCode, wantErr: errs.ErrTooManyRequests},
+				{advance: time.Minute, resend: true, code: validCode},
+			},
+		},
+		{
+			name:    "Успешная проверка сбрасывает блокировку перебора",
+			lockout: &lockout.Config{MaxUserAttempts: 2, MaxIPAttempts: 10, Window: time.Hour, Duration: time.Minute},
+			steps:   []step{wrong, {code: validCode}, {resend: true, code: wrongCode, wantErr: otp.ErrWrongCode}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Now()
+			clock := func() time.Time { return now }
+			store := newMemoryStore(t, clock)
+
+			var opts []otp.Option
+			if tt.lockout != nil {
+				opts = append(opts, otp.WithLockout(lockout.NewGuard(store, tt.lockout, lockout.WithClock(clock))))
+			}
+
+			manager := otp.NewManager(&senderStub{}, store, newConfig(), generator.NewTestDouble(4), opts...)
+
+			_, err := manager.Send(ctx, target, "")
+			require.NoError(t, err)
+
+			for i, step := range tt.steps {
+				now = now.Add(step.advance)
+
+				if step.resend {
+					_, err = manager.Send(ctx, target, "")
+					require.NoError(t, err, "step %d", i)
+				}
+
+				err = manager.Validate(ctx, target, step.code)
+				if step.wantErr == nil {
+					require.NoError(t, err, "step %d", i)
+				} else {
+					require.ErrorIs(t, err, step.wantErr, "step %d", i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	rv9 "github.com/redis/go-redis/v9"
+
+	"github.com/wal1251/pkg/core/memorystore"
+)
+
+const scanBatchSize = 100
+
+var (
+	_ memorystore.Counter           = (*Client)(nil)
+	_ memorystore.ConditionalSetter = (*Client)(nil)
+	_ memorystore.CompareAndSwapper = (*Client)(nil)
+	_ memorystore.Expirer           = (*Client)(nil)
+	_ memorystore.TTLReader         = (*Client)(nil)
+	_ memorystore.Scanner           = (*Client)(nil)
+
+	// incrementScript увеличивает счетчик и устанавливает время жизни, если у ключа его нет (ключ создан).
+	incrementScript = rv9.NewScript(`
+local value = redis.call("INCRBY", KEYS[1], ARGV[1])
+if tonumber(ARGV[2]) > 0 and redis.call("PTTL", KEYS[1]) == -1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return value`)
+
+	// compareAndSwapScript заменяет значение, если текущее равно ARGV[1].
+	compareAndSwapScript = rv9.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+else
+	redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1`)
+
+	globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+)
+
+// Increment см. memorystore.Counter.
+func (r *Client) Increment(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, error) {
+	value, err := incrementScript.Run(ctx, r.client, []string{key}, delta, expiration.Milliseconds()).Int64()
+	if err != nil {
+		if strings.Contains(err.Error(), "not an integer") {
+			return 0, fmt.Errorf("can't increment redis key %s: %w", key, memorystore.ErrNotInteger)
+		}
+
+		return 0, fmt.Errorf("can't increment redis key %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// Decrement см. memorystore.Counter.
+func (r *Client) Decrement(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, error) {
+	return r.Increment(ctx, key, -delta, expiration)
+}
+
+// SetIfNotExists см. memorystore.ConditionalSetter.
+func (r *Client) SetIfNotExists(ctx context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	data, err := r.codec.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	set, err := r.client.SetNX(ctx, key, data, expiration).Result()
+	if err != nil {
+		return false, fmt.Errorf("can't set redis key %s: %w", key, err)
+	}
+
+	return set, nil
+}
+
+// CompareAndSwap см. memorystore.CompareAndSwapper.
+func (r *Client) CompareAndSwap(ctx context.Context, key string, old *memorystore.Value, value any, expiration time.Duration) (bool, error) {
+	data, err := r.codec.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	swapped, err := compareAndSwapScript.Run(ctx, r.client, []string{key}, old.RawBytes(), data, expiration.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("can't swap redis key %s: %w", key, err)
+	}
+
+	return swapped == 1, nil
+}
+
+// Expire см. memorystore.Expirer.
+func (r *Client) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	var (
+		ok  bool
+		err error
+	)
+
+	if expiration > 0 {
+		ok, err = r.client.PExpire(ctx, key, expiration).Result()
+	} else {
+		ok, err = r.client.Persist(ctx, key).Result()
+		if err == nil && !ok {
+			ok, err = r.exists(ctx, key)
+		}
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("can't expire redis key %s: %w", key, err)
+	}
+
+	return ok, nil
+}
+
+// TTL см. memorystore.TTLReader.
+func (r *Client) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("can't get redis key %s ttl: %w", key, err)
+	}
+
+	// Redis возвращает -2 для отсутствующего ключа и -1 для бессрочного.
+	switch {
+	case ttl == -2:
+		return 0, memorystore.ErrKeyNotFound
+	case ttl < 0:
+		return memorystore.NoExpiration, nil
+	default:
+		return ttl, nil
+	}
+}
+
+// ScanPrefix см. memorystore.Scanner. Ключи перебираются командой SCAN, не блокируя Redis.
+func (r *Client) ScanPrefix(ctx context.Context, prefix string, fn func(key string) error) error {
+	iter := r.client.Scan(ctx, 0, globEscaper.Replace(prefix)+"*", scanBatchSize).Iterator()
+	for iter.Next(ctx) {
+		if err := fn(iter.Val()); err != nil {
+			return err
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("can't scan redis keys %s*: %w", prefix, err)
+	}
+
+	return nil
+}
+
+func (r *Client) exists(ctx context.Context, key string) (bool, error) {
+	count, err := r.client.Exists(ctx, key).Result()
+	if err != nil && !errors.Is(err, rv9.Nil) {
+		return false, err //nolint:wrapcheck
+	}
+
+	return count > 0, nil
+}
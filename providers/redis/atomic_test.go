@@ -0,0 +1,148 @@
+package redis_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/providers/redis"
+)
+
+func TestClient_Atomic(t *testing.T) {
+	ctx := context.Background()
+	config := &redis.Config{Host: "localhost", Port: "6383", MaxBulkRequestSize: memorystore.DefaultMaxBulkRequestSize}
+
+	server := redis.NewTestRedisServer()
+	require.NoError(t, server.Run(*config))
+	defer server.Close()
+
+	client, err := redis.NewClient(ctx, config)
+	require.NoError(t, err)
+	defer client.Close(ctx)
+
+	tests := []struct {
+		name string
+		test func(*testing.T)
+	}{
+		{
+			name: "Increment and Decrement counter",
+			test: func(t *testing.T) {
+				value, err := client.Increment(ctx, "counter", 2, time.Minute)
+				require.NoError(t, err)
+				assert.Equal(t, int64(2), value)
+
+				value, err = client.Decrement(ctx, "counter", 1, time.Hour)
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), value)
+
+				ttl, err := client.TTL(ctx, "counter")
+				require.NoError(t, err)
+				assert.Equal(t, time.Minute, ttl)
+
+				stored, err := client.Get(ctx, "counter")
+				require.NoError(t, err)
+				number, err := stored.Int()
+				require.NoError(t, err)
+				assert.Equal(t, 1, number)
+
+				require.NoError(t, client.Set(ctx, "text", "value", 0))
+				_, err = client.Increment(ctx, "text", 1, 0)
+				assert.ErrorIs(t, err, memorystore.ErrNotInteger)
+			},
+		},
+		{
+			name: "SetIfNotExists",
+			test: func(t *testing.T) {
+				set, err := client.SetIfNotExists(ctx, "nx", "first", 0)
+				require.NoError(t, err)
+				assert.True(t, set)
+
+				set, err = client.SetIfNotExists(ctx, "nx", "second", 0)
+				require.NoError(t, err)
+				assert.False(t, set)
+
+				stored, err := client.Get(ctx, "nx")
+				require.NoError(t, err)
+				value, err := stored.String()
+				require.NoError(t, err)
+				assert.Equal(t, "first", value)
+			},
+		},
+		{
+			name: "CompareAndSwap",
+			test: func(t *testing.T) {
+				require.NoError(t, client.Set(ctx, "cas", "old", 0))
+
+				old, err := client.Get(ctx, "cas")
+				require.NoError(t, err)
+
+				swapped, err := client.CompareAndSwap(ctx, "cas", old, "new", time.Minute)
+				require.NoError(t, err)
+				assert.True(t, swapped)
+
+				swapped, err = client.CompareAndSwap(ctx, "cas", old, "newer", 0)
+				require.NoError(t, err)
+				assert.False(t, swapped)
+
+				ttl, err := client.TTL(ctx, "cas")
+				require.NoError(t, err)
+				assert.Equal(t, time.Minute, ttl)
+			},
+		},
+		{
+			name: "Expire and TTL",
+			test: func(t *testing.T) {
+				require.NoError(t, client.Set(ctx, "expire", 1, 0))
+
+				ttl, err := client.TTL(ctx, "expire")
+				require.NoError(t, err)
+				assert.Equal(t, memorystore.NoExpiration, ttl)
+
+				ok, err := client.Expire(ctx, "expire", time.Minute)
+				require.NoError(t, err)
+				assert.True(t, ok)
+
+				ttl, err = client.TTL(ctx, "expire")
+				require.NoError(t, err)
+				assert.Equal(t, time.Minute, ttl)
+
+				ok, err = client.Expire(ctx, "expire", memorystore.NoExpiration)
+				require.NoError(t, err)
+				assert.True(t, ok)
+
+				ok, err = client.Expire(ctx, "missing", time.Minute)
+				require.NoError(t, err)
+				assert.False(t, ok)
+
+				_, err = client.TTL(ctx, "missing")
+				assert.ErrorIs(t, err, memorystore.ErrKeyNotFound)
+			},
+		},
+		{
+			name: "ScanPrefix",
+			test: func(t *testing.T) {
+				for _, key := range []string{"scan:a", "scan:b", "scan*c", "other"} {
+					require.NoError(t, client.Set(ctx, key, 1, 0))
+				}
+
+				var keys []string
+				require.NoError(t, client.ScanPrefix(ctx, "scan:", func(key string) error {
+					keys = append(keys, key)
+
+					return nil
+				}))
+				sort.Strings(keys)
+				assert.Equal(t, []string{"scan:a", "scan:b"}, keys)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.test)
+	}
+}
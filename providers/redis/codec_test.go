@@ -0,0 +1,202 @@
+package redis_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/providers/redis"
+	"github.com/wal1251/pkg/tools/serial"
+)
+
+type codecPayload struct {
+	Name  string
+	Count int
+}
+
+func TestClient_WithCodec(t *testing.T) {
+	ctx := context.Background()
+	config := &redis.Config{Host: "localhost", Port: "6384", MaxBulkRequestSize: memorystore.DefaultMaxBulkRequestSize}
+
+	server := redis.NewTestRedisServer()
+	require.NoError(t, server.Run(*config))
+	defer server.Close()
+
+	key := bytes.Repeat([]byte{1}, 32)
+	rotated := bytes.Repeat([]byte{2}, 32)
+
+	newClient := func(t *testing.T, codec memorystore.Codec) *redis.Client {
+		t.Helper()
+
+		client, err := redis.NewClient(ctx, config, redis.WithCodec(codec))
+		require.NoError(t, err)
+		t.Cleanup(func() { client.Close(ctx) })
+
+		return client
+	}
+
+	newEnvelope := func(t *testing.T, opts ...memorystore.EnvelopeOption) memorystore.Codec {
+		t.Helper()
+
+		codec, err := memorystore.NewEnvelopeCodec(opts...)
+		require.NoError(t, err)
+
+		return codec
+	}
+
+	payload := codecPayload{Name: strings.Repeat("name", 100), Count: 42}
+
+	tests := []struct {
+		name  string
+		write memorystore.Codec
+		read  memorystore.Codec
+	}{
+		{
+			name:  "JSON",
+			write: memorystore.JSONCodec{},
+			read:  memorystore.JSONCodec{},
+		},
+		{
+			name:  "gob",
+			write: memorystore.GobCodec{},
+			read:  memorystore.GobCodec{},
+		},
+		{
+			name:  "Envelope with gob, compression and encryption",
+			write: newEnvelope(t, memorystore.WithFormat(memorystore.FormatGob, nil), memorystore.WithCompression(64), memorystore.WithEncryption(key)),
+			read:  newEnvelope(t, memorystore.WithEncryption(key)),
+		},
+		{
+			name:  "Envelope reads values written before key rotation",
+			write: newEnvelope(t, memorystore.WithEncryption(key)),
+			read:  newEnvelope(t, memorystore.WithFormat(memorystore.FormatGob, nil), memorystore.WithEncryption(rotated, key)),
+		},
+		{
+			name:  "Envelope reads legacy JSON values",
+			write: memorystore.JSONCodec{},
+			read:  newEnvelope(t, memorystore.WithFormat(memorystore.FormatGob, nil), memorystore.WithCompression(1)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, newClient(t, tt.write).Set(ctx, "payload", payload, 0))
+
+			value, err := newClient(t, tt.read).Get(ctx, "payload")
+			require.NoError(t, err)
+
+			var result codecPayload
+			require.NoError(t, value.Struct(&result))
+			assert.Equal(t, payload, result)
+		})
+	}
+
+	t.Run("Envelope compresses large values", func(t *testing.T) {
+		client := newClient(t, newEnvelope(t, memorystore.WithCompression(64)))
+		require.NoError(t, client.Set(ctx, "large", strings.Repeat("a", 1000), 0))
+		require.NoError(t, client.Set(ctx, "small", "a", 0))
+
+		large, err := client.Get(ctx, "large")
+		require.NoError(t, err)
+		assert.Less(t, len(large.RawBytes()), 100)
+
+		str, err := large.String()
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat("a", 1000), str)
+
+		small, err := client.Get(ctx, "small")
+		require.NoError(t, err)
+		str, err = small.String()
+		require.NoError(t, err)
+		assert.Equal(t, "a", str)
+	})
+
+	t.Run("Envelope fails with unknown key", func(t *testing.T) {
+		require.NoError(t, newClient(t, newEnvelope(t, memorystore.WithEncryption(key))).Set(ctx, "secret", "value", 0))
+
+		value, err := newClient(t, newEnvelope(t, memorystore.WithEncryption(rotated))).Get(ctx, "secret")
+		require.NoError(t, err)
+
+		_, err = value.String()
+		assert.ErrorIs(t, err, memorystore.ErrDecryption)
+	})
+
+	t.Run("Envelope rejects unencrypted values when encryption is required", func(t *testing.T) {
+		strict := newClient(t, newEnvelope(t, memorystore.WithEncryption(key), memorystore.WithRequireEncryption()))
+
+		for name, codec := range map[string]memorystore.Codec{
+			"legacy JSON":         memorystore.JSONCodec{},
+			"envelope":            newEnvelope(t),
+			"compressed envelope": newEnvelope(t, memorystore.WithCompression(1)),
+		} {
+			require.NoError(t, newClient(t, codec).Set(ctx, "downgrade", "value", 0), name)
+
+			value, err := strict.Get(ctx, "downgrade")
+			require.NoError(t, err, name)
+
+			_, err = value.String()
+			assert.ErrorIs(t, err, memorystore.ErrNotEncrypted, name)
+		}
+
+		require.NoError(t, strict.Set(ctx, "encrypted", "value", 0))
+
+		value, err := strict.Get(ctx, "encrypted")
+		require.NoError(t, err)
+
+		str, err := value.String()
+		require.NoError(t, err)
+		assert.Equal(t, "value", str)
+	})
+
+	t.Run("Envelope limits decompressed size", func(t *testing.T) {
+		require.NoError(t, newClient(t, newEnvelope(t, memorystore.WithCompression(64))).Set(ctx, "bomb", strings.Repeat("a", 1000), 0))
+
+		value, err := newClient(t, newEnvelope(t, memorystore.WithMaxDecompressedSize(100))).Get(ctx, "bomb")
+		require.NoError(t, err)
+
+		_, err = value.String()
+		assert.ErrorIs(t, err, memorystore.ErrInvalidFormat)
+	})
+
+	t.Run("Raw and serial codecs", func(t *testing.T) {
+		raw := newClient(t, memorystore.RawCodec{})
+		require.NoError(t, raw.Set(ctx, "raw", []byte{0, 1, 2}, 0))
+		assert.ErrorIs(t, raw.Set(ctx, "raw", 1, 0), memorystore.ErrUnsupportedValue)
+
+		value, err := raw.Get(ctx, "raw")
+		require.NoError(t, err)
+		data, err := value.Bytes()
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0, 1, 2}, data)
+
+		xml := newClient(t, newEnvelope(t, memorystore.WithFormat(100,
+			memorystore.NewSerialCodec(serial.XMLEncode[codecPayload], serial.XMLDecode[codecPayload]))))
+		require.NoError(t, xml.Set(ctx, "xml", payload, 0))
+
+		value, err = xml.Get(ctx, "xml")
+		require.NoError(t, err)
+
+		var result codecPayload
+		require.NoError(t, value.Struct(&result))
+		assert.Equal(t, payload, result)
+	})
+
+	t.Run("Invalid envelope config", func(t *testing.T) {
+		_, err := memorystore.NewEnvelopeCodec(memorystore.WithEncryption([]byte("short")))
+		assert.ErrorIs(t, err, memorystore.ErrInvalidCodec)
+
+		_, err = memorystore.NewEnvelopeCodec(memorystore.WithFormat(100, nil))
+		assert.ErrorIs(t, err, memorystore.ErrInvalidCodec)
+
+		_, err = memorystore.NewEnvelopeCodec(memorystore.WithRequireEncryption())
+		assert.ErrorIs(t, err, memorystore.ErrInvalidCodec)
+
+		_, err = memorystore.NewEnvelopeCodec(memorystore.WithMaxDecompressedSize(0))
+		assert.ErrorIs(t, err, memorystore.ErrInvalidCodec)
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/wal1251/pkg/core/cfg"
 	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/core/memorystore/local"
 )
 
 const (
@@ -32,6 +33,14 @@ const (
 	BusCfgConsumerPrefetchLimit cfg.Key = "REDIS_BUS_CONSUMER_PREFETCH_LIMIT"
 	BusCfgConsumerPollDuration  cfg.Key = "REDIS_BUS_CONSUMER_POLL_DURATION"
 
+	TieredCfgDefaultLocalTTL = 30 * time.Second    // Время актуальности значений локального уровня по умолчанию.
+	TieredCfgDefaultStaleTTL = 5 * time.Minute     // Время хранения устаревших значений на случай сбоя Redis.
+	TieredCfgDefaultChannel  = "memorystore:evict" // Канал уведомлений об изменении ключей по умолчанию.
+
+	TieredCfgLocalTTL cfg.Key = "REDIS_TIERED_LOCAL_TTL" // Время актуальности значений локального уровня (duration).
+	TieredCfgStaleTTL cfg.Key = "REDIS_TIERED_STALE_TTL" // Время хранения устаревших значений (duration).
+	TieredCfgChannel  cfg.Key = "REDIS_TIERED_CHANNEL"   // Канал уведомлений об изменении ключей (string).
+
 	redisFailedTxRetryInterval = time.Second
 	RMQServiceName             = "RMQ"
 
@@ -54,6 +63,14 @@ type (
 		ConsumerPrefetchLimit int
 		ConsumerPollDuration  time.Duration
 	}
+
+	// TieredConfig конфигурация двухуровневого хранилища TieredClient.
+	TieredConfig struct {
+		LocalTTL time.Duration // Время, в течение которого значение локального уровня используется без обращения к Redis.
+		StaleTTL time.Duration // Время, в течение которого устаревшее значение возвращается при недоступности Redis.
+		Channel  string        // Канал Redis pub/sub для уведомлений об изменении ключей.
+		Local    *local.Config // Конфигурация локального уровня.
+	}
 )
 
 func NewConfig(
@@ -5,6 +5,7 @@ import (
 
 	"github.com/wal1251/pkg/core/cfg"
 	"github.com/wal1251/pkg/core/cfg/viperx"
+	"github.com/wal1251/pkg/core/memorystore/local"
 )
 
 func CfgFromViper(loader *viper.Viper, keyMapping ...cfg.KeyMap) *Config {
@@ -26,3 +27,12 @@ func BusCfgFromViper(loader *viper.Viper, keyMapping ...cfg.KeyMap) *BusConfig {
 		viperx.Get(loader, BusCfgConsumerPollDuration.Map(keyMapping...), BusCfgDefaultConsumerPollDuration),
 	)
 }
+
+func TieredCfgFromViper(loader *viper.Viper, keyMapping ...cfg.KeyMap) *TieredConfig {
+	return &TieredConfig{
+		LocalTTL: viperx.Get(loader, TieredCfgLocalTTL.Map(keyMapping...), TieredCfgDefaultLocalTTL),
+		StaleTTL: viperx.Get(loader, TieredCfgStaleTTL.Map(keyMapping...), TieredCfgDefaultStaleTTL),
+		Channel:  viperx.Get(loader, TieredCfgChannel.Map(keyMapping...), TieredCfgDefaultChannel),
+		Local:    local.CfgFromViper(loader, keyMapping...),
+	}
+}
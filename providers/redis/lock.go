@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rv9 "github.com/redis/go-redis/v9"
+
+	"github.com/wal1251/pkg/core/memorystore/lock"
+)
+
+var (
+	_ lock.Backend = (*LockBackend)(nil)
+
+	// acquireScript захватывает блокировку командой SET NX PX, увеличивает токен ограждения ключа и продлевает срок
+	// хранения счетчика на ARGV[3] миллисекунд.
+	acquireScript = rv9.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	local token = redis.call("INCR", KEYS[2])
+	redis.call("PEXPIRE", KEYS[2], ARGV[3])
+	return token
+end
+return 0`)
+
+	// refreshScript продлевает аренду, если блокировкой владеет ARGV[1].
+	refreshScript = rv9.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	// releaseScript удаляет блокировку, если ею владеет ARGV[1].
+	releaseScript = rv9.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// LockBackend хранилище распределенных блокировок в Redis, см. lock.Backend. Блокировка захватывается атомарно
+// командой SET NX PX, а продление и освобождение проверяют владельца в скриптах Lua. Токены ограждения хранятся в
+// ключах с суффиксом ":fence" в течение lock.FenceTTL с последнего захвата.
+type LockBackend struct {
+	client *rv9.Client
+}
+
+// Acquire см. lock.Backend.
+func (b *LockBackend) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (int64, bool, error) {
+	token, err := acquireScript.Run(ctx, b.client, []string{key, key + ":fence"}, owner, ttl.Milliseconds(),
+		lock.FenceTTL(ttl).Milliseconds()).Int64()
+	if err != nil {
+		return 0, false, fmt.Errorf("can't acquire redis lock %s: %w", key, err)
+	}
+
+	return token, token > 0, nil
+}
+
+// Refresh см. lock.Backend.
+func (b *LockBackend) Refresh(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	refreshed, err := refreshScript.Run(ctx, b.client, []string{key}, owner, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("can't refresh redis lock %s: %w", key, err)
+	}
+
+	return refreshed == 1, nil
+}
+
+// Release см. lock.Backend.
+func (b *LockBackend) Release(ctx context.Context, key, owner string) (bool, error) {
+	released, err := releaseScript.Run(ctx, b.client, []string{key}, owner).Int64()
+	if err != nil {
+		return false, fmt.Errorf("can't release redis lock %s: %w", key, err)
+	}
+
+	return released == 1, nil
+}
+
+// LockBackend возвращает хранилище распределенных блокировок на базе клиента.
+func (r *Client) LockBackend() *LockBackend {
+	return &LockBackend{client: r.client}
+}
@@ -0,0 +1,49 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/core/memorystore/lock"
+	"github.com/wal1251/pkg/providers/redis"
+)
+
+func TestLockBackend(t *testing.T) {
+	ctx := context.Background()
+	config := &redis.Config{Host: "localhost", Port: "6382", MaxBulkRequestSize: memorystore.DefaultMaxBulkRequestSize}
+
+	server := redis.NewTestRedisServer()
+	require.NoError(t, server.Run(*config))
+	defer server.Close()
+
+	client, err := redis.NewClient(ctx, config)
+	require.NoError(t, err)
+	defer client.Close(ctx)
+
+	locker := lock.NewLocker(client.LockBackend())
+
+	first, err := locker.TryLock(ctx, "job", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), first.Token())
+
+	fenceTTL, err := client.TTL(ctx, first.Key()+":fence")
+	require.NoError(t, err)
+	assert.Equal(t, lock.DefaultFenceTTL, fenceTTL)
+
+	_, err = locker.TryLock(ctx, "job", time.Minute)
+	assert.ErrorIs(t, err, lock.ErrNotAcquired)
+
+	require.NoError(t, first.Refresh(ctx, time.Minute))
+	require.NoError(t, first.Release(ctx))
+	assert.ErrorIs(t, first.Release(ctx), lock.ErrLeaseLost)
+
+	second, err := locker.TryLock(ctx, "job", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), second.Token())
+	assert.ErrorIs(t, first.Refresh(ctx, time.Minute), lock.ErrLeaseLost)
+}
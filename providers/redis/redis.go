@@ -15,7 +15,6 @@ import (
 
 	"github.com/wal1251/pkg/core/logs"
 	"github.com/wal1251/pkg/core/memorystore"
-	"github.com/wal1251/pkg/tools/serial"
 )
 
 var _ memorystore.Manager = (*Client)(nil)
@@ -26,12 +25,16 @@ var _ memorystore.Manager = (*Client)(nil)
 type Client struct {
 	client *rv9.Client // Низкоуровневый клиент Redis.
 	cfg    *Config
+	codec  memorystore.Codec
 }
 
+// Option опция Client.
+type Option func(*Client)
+
 // NewClient инициализирует и возвращает новый экземпляр клиента Redis.
 // Проверяет доступность Redis и устанавливает соединение с учетом TLS и кластерной конфигурации.
 // В случае, когда Redis недоступен, возвращает ошибку.
-func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
+func NewClient(ctx context.Context, cfg *Config, opts ...Option) (*Client, error) {
 	rdb, err := createRedisClient(cfg)
 	if err != nil {
 		return nil, err
@@ -42,7 +45,20 @@ func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 		return nil, fmt.Errorf("redis is not responding: %w", err)
 	}
 
-	return &Client{client: rdb, cfg: cfg}, nil
+	client := &Client{client: rdb, cfg: cfg, codec: memorystore.JSONCodec{}}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client, nil
+}
+
+// WithCodec устанавливает кодек значений, по умолчанию memorystore.JSONCodec. Кодек memorystore.EnvelopeCodec
+// позволяет сжимать и шифровать значения, а также менять формат без очистки хранилища.
+func WithCodec(codec memorystore.Codec) Option {
+	return func(c *Client) {
+		c.codec = codec
+	}
 }
 
 // createRedisClient создает и возвращает клиент Redis с учетом конфигурации.
@@ -96,7 +112,7 @@ func getTLSConfig(certPath string) (*tls.Config, error) {
 
 // Set сохраняет значение по ключу в Redis с заданным временем истечения.
 func (r *Client) Set(ctx context.Context, key string, value any, expiration time.Duration) error {
-	data, err := serial.ToBytes(value, serial.JSONEncode[any])
+	data, err := r.codec.Marshal(value)
 	if err != nil {
 		return err
 	}
@@ -127,7 +143,7 @@ func (r *Client) Get(ctx context.Context, key string) (*memorystore.Value, error
 		return nil, fmt.Errorf("can't read redis key %s value: %w", key, err)
 	}
 
-	return memorystore.NewValue(data), nil
+	return memorystore.NewCodecValue(data, r.codec), nil
 }
 
 // GetList извлекает и возвращает значения для списка ключей из Redis.
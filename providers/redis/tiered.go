@@ -0,0 +1,349 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	rv9 "github.com/redis/go-redis/v9"
+
+	"github.com/wal1251/pkg/core/logs"
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/core/memorystore/local"
+)
+
+var _ memorystore.Manager = (*TieredClient)(nil)
+
+type (
+	// TieredClient двухуровневое хранилище: небольшой локальный уровень в памяти процесса перед Client. Значения
+	// локального уровня используются без обращения к Redis в течение TieredConfig.LocalTTL, но не дольше оставшегося
+	// времени жизни ключа в Redis.
+	//
+	// Set и Delete публикуют измененные ключи в канал Redis pub/sub, и другие экземпляры приложения удаляют их из
+	// локального уровня. Если уведомление потеряно (например, при переподключении), устаревшее значение используется
+	// не дольше TieredConfig.LocalTTL.
+	//
+	// Если Redis недоступен, Get и GetList возвращают устаревшие значения локального уровня, сохраненные не более
+	// TieredConfig.StaleTTL назад. Запись при недоступности Redis завершается ошибкой, а ключ удаляется из локального
+	// уровня.
+	TieredClient struct {
+		remote     *Client
+		local      *local.Store
+		config     TieredConfig
+		instanceID string
+		pubsub     *rv9.PubSub
+		done       chan struct{}
+		closeOnce  sync.Once
+		now        func() time.Time
+	}
+
+	// localEntry значение локального уровня.
+	localEntry struct {
+		Data       []byte    `json:"d"`
+		FreshUntil time.Time `json:"f"`
+	}
+
+	// evictMessage уведомление об изменении ключей.
+	evictMessage struct {
+		Origin string   `json:"origin"`
+		Keys   []string `json:"keys"`
+	}
+)
+
+// Set сохраняет значение в Redis и в локальном уровне, затем уведомляет другие экземпляры об изменении ключа.
+func (c *TieredClient) Set(ctx context.Context, key string, value any, expiration time.Duration) error {
+	data, err := c.remote.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if err = c.remote.client.Set(ctx, key, data, expiration).Err(); err != nil {
+		c.evictLocal(ctx, key)
+
+		return fmt.Errorf("can't set redis key %s: %w", key, err)
+	}
+
+	c.setLocal(ctx, key, data, expiration)
+	c.publish(ctx, key)
+
+	return nil
+}
+
+// Get возвращает значение из локального уровня, если оно актуально, иначе из Redis. Если Redis недоступен, вернет
+// устаревшее значение локального уровня, если оно есть.
+func (c *TieredClient) Get(ctx context.Context, key string) (*memorystore.Value, error) {
+	cached, found := c.getLocal(ctx, key)
+	if found && c.now().Before(cached.FreshUntil) {
+		return memorystore.NewCodecValue(cached.Data, c.remote.codec), nil
+	}
+
+	values, err := c.fetch(ctx, key)
+	if err != nil {
+		if found {
+			logs.FromContext(ctx).Warn().Err(err).Str("key", key).Msg("redis is unavailable, stale local value is used")
+
+			return memorystore.NewCodecValue(cached.Data, c.remote.codec), nil
+		}
+
+		return nil, err
+	}
+
+	if values[0] == nil {
+		c.evictLocal(ctx, key)
+
+		return nil, memorystore.ErrKeyNotFound
+	}
+
+	return values[0], nil
+}
+
+// GetList возвращает значения из локального уровня, недостающие запрашиваются в Redis одним запросом.
+func (c *TieredClient) GetList(ctx context.Context, keys ...string) ([]*memorystore.Value, error) {
+	if len(keys) > c.remote.cfg.MaxBulkRequestSize {
+		return nil, fmt.Errorf("can't get more than %d keys at once: %w", c.remote.cfg.MaxBulkRequestSize, memorystore.ErrBulkRequestTooLarge)
+	}
+
+	now := c.now()
+	results := make([]*memorystore.Value, len(keys))
+	stale := make(map[int]*memorystore.Value)
+	missing := make([]string, 0, len(keys))
+	positions := make([]int, 0, len(keys))
+
+	for idx, key := range keys {
+		cached, found := c.getLocal(ctx, key)
+		if found && now.Before(cached.FreshUntil) {
+			results[idx] = memorystore.NewCodecValue(cached.Data, c.remote.codec)
+
+			continue
+		}
+
+		if found {
+			stale[idx] = memorystore.NewCodecValue(cached.Data, c.remote.codec)
+		}
+
+		missing = append(missing, key)
+		positions = append(positions, idx)
+	}
+
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	values, err := c.fetch(ctx, missing...)
+	if err != nil {
+		logs.FromContext(ctx).Warn().Err(err).Msg("redis is unavailable, stale local values are used")
+
+		if len(stale) == 0 {
+			return nil, err
+		}
+
+		for idx, value := range stale {
+			results[idx] = value
+		}
+
+		return results, nil
+	}
+
+	for i, value := range values {
+		results[positions[i]] = value
+	}
+
+	return results, nil
+}
+
+// Delete удаляет ключи из Redis и локального уровня, затем уведомляет другие экземпляры об изменении ключей.
+func (c *TieredClient) Delete(ctx context.Context, keys ...string) (int, error) {
+	deleted, err := c.remote.Delete(ctx, keys...)
+	if errors.Is(err, memorystore.ErrBulkRequestTooLarge) {
+		return 0, err
+	}
+
+	c.evictLocal(ctx, keys...)
+
+	if err != nil {
+		return deleted, err
+	}
+
+	c.publish(ctx, keys...)
+
+	return deleted, nil
+}
+
+// Close прекращает прием уведомлений, очищает локальный уровень и закрывает Client.
+func (c *TieredClient) Close(ctx context.Context) {
+	c.closeOnce.Do(func() {
+		if err := c.pubsub.Close(); err != nil {
+			logs.FromContext(ctx).Warn().Err(err).Msg("can't close redis subscription")
+		}
+
+		<-c.done
+
+		c.local.Close(ctx)
+		c.remote.Close(ctx)
+	})
+}
+
+func (c *TieredClient) getLocal(ctx context.Context, key string) (localEntry, bool) {
+	var cached localEntry
+
+	value, err := c.local.Get(ctx, key)
+	if err != nil {
+		return cached, false
+	}
+
+	if err = value.Struct(&cached); err != nil {
+		return cached, false
+	}
+
+	return cached, true
+}
+
+// fetch запрашивает значения ключей вместе с их оставшимся временем жизни одним конвейером и сохраняет найденные
+// значения в локальном уровне. Для отсутствующих ключей в соответствующих позициях будет nil.
+func (c *TieredClient) fetch(ctx context.Context, keys ...string) ([]*memorystore.Value, error) {
+	pipe := c.remote.client.Pipeline()
+	gets := make([]*rv9.StringCmd, len(keys))
+	ttls := make([]*rv9.DurationCmd, len(keys))
+
+	for idx, key := range keys {
+		gets[idx] = pipe.Get(ctx, key)
+		ttls[idx] = pipe.PTTL(ctx, key)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, rv9.Nil) {
+		return nil, fmt.Errorf("can't get redis keys: %w", err)
+	}
+
+	values := make([]*memorystore.Value, len(keys))
+
+	for idx, key := range keys {
+		data, err := gets[idx].Bytes()
+		if errors.Is(err, rv9.Nil) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("can't get redis key %s: %w", key, err)
+		}
+
+		values[idx] = memorystore.NewCodecValue(data, c.remote.codec)
+
+		// Redis возвращает -1 для бессрочного ключа и -2, если ключ истек между GET и PTTL.
+		switch ttl := ttls[idx].Val(); {
+		case ttl > 0:
+			c.setLocal(ctx, key, data, ttl)
+		case ttl == -1:
+			c.setLocal(ctx, key, data, 0)
+		}
+	}
+
+	return values, nil
+}
+
+// setLocal сохраняет значение в локальном уровне. Параметр expiration - оставшееся время жизни ключа в Redis (0 -
+// бессрочный), ни актуальная, ни устаревшая локальная копия не используются дольше него.
+func (c *TieredClient) setLocal(ctx context.Context, key string, data []byte, expiration time.Duration) {
+	ttl, fresh := c.config.StaleTTL, c.config.LocalTTL
+	if expiration > 0 {
+		ttl, fresh = min(ttl, expiration), min(fresh, expiration)
+	}
+
+	entry := localEntry{Data: data, FreshUntil: c.now().Add(fresh)}
+	if err := c.local.Set(ctx, key, entry, ttl); err != nil {
+		logs.FromContext(ctx).Debug().Err(err).Str("key", key).Msg("value is not cached locally")
+	}
+}
+
+func (c *TieredClient) evictLocal(ctx context.Context, keys ...string) {
+	for len(keys) > 0 {
+		chunk := keys[:min(len(keys), memorystore.DefaultMaxBulkRequestSize)]
+		keys = keys[len(chunk):]
+
+		if _, err := c.local.Delete(ctx, chunk...); err != nil {
+			logs.FromContext(ctx).Warn().Err(err).Msg("can't evict local values")
+		}
+	}
+}
+
+func (c *TieredClient) publish(ctx context.Context, keys ...string) {
+	message, err := json.Marshal(evictMessage{Origin: c.instanceID, Keys: keys})
+	if err != nil {
+		logs.FromContext(ctx).Warn().Err(err).Msg("can't encode eviction message")
+
+		return
+	}
+
+	if err = c.remote.client.Publish(ctx, c.config.Channel, message).Err(); err != nil {
+		logs.FromContext(ctx).Warn().Err(err).Msg("can't publish eviction message")
+	}
+}
+
+func (c *TieredClient) listen(ctx context.Context) {
+	defer close(c.done)
+
+	logger := logs.FromContext(ctx)
+
+	for message := range c.pubsub.Channel() {
+		var evict evictMessage
+		if err := json.Unmarshal([]byte(message.Payload), &evict); err != nil {
+			logger.Warn().Err(err).Msg("can't decode eviction message")
+
+			continue
+		}
+
+		if evict.Origin != c.instanceID {
+			c.evictLocal(ctx, evict.Keys...)
+		}
+	}
+}
+
+// NewTieredClient возвращает двухуровневое хранилище поверх клиента remote и подписывается на уведомления об изменении
+// ключей. TieredClient владеет клиентом remote и закрывает его в Close.
+func NewTieredClient(ctx context.Context, remote *Client, config *TieredConfig) (*TieredClient, error) {
+	cfg := *config
+	if cfg.LocalTTL <= 0 {
+		cfg.LocalTTL = TieredCfgDefaultLocalTTL
+	}
+
+	if cfg.StaleTTL < cfg.LocalTTL {
+		cfg.StaleTTL = cfg.LocalTTL
+	}
+
+	if cfg.Channel == "" {
+		cfg.Channel = TieredCfgDefaultChannel
+	}
+
+	if cfg.Local == nil {
+		cfg.Local = local.DefaultConfig()
+	}
+
+	store, err := local.NewStore(cfg.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	pubsub := remote.client.Subscribe(ctx, cfg.Channel)
+	if _, err = pubsub.Receive(ctx); err != nil {
+		store.Close(ctx)
+
+		return nil, fmt.Errorf("can't subscribe to redis channel %s: %w", cfg.Channel, err)
+	}
+
+	client := &TieredClient{
+		remote:     remote,
+		local:      store,
+		config:     cfg,
+		instanceID: uuid.NewString(),
+		pubsub:     pubsub,
+		done:       make(chan struct{}),
+		now:        time.Now,
+	}
+
+	go client.listen(context.WithoutCancel(ctx))
+
+	return client, nil
+}
@@ -0,0 +1,150 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wal1251/pkg/core/memorystore"
+	"github.com/wal1251/pkg/core/memorystore/local"
+	"github.com/wal1251/pkg/providers/redis"
+)
+
+func newTieredClient(t *testing.T, config *redis.Config, tiered *redis.TieredConfig) *redis.TieredClient {
+	t.Helper()
+
+	remote, err := redis.NewClient(context.Background(), config)
+	require.NoError(t, err)
+
+	client, err := redis.NewTieredClient(context.Background(), remote, tiered)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close(context.Background()) })
+
+	return client
+}
+
+func getString(t *testing.T, client memorystore.MemoryStore, key string) string {
+	t.Helper()
+
+	value, err := client.Get(context.Background(), key)
+	require.NoError(t, err)
+
+	s, err := value.String()
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestTieredClient(t *testing.T) {
+	ctx := context.Background()
+	config := &redis.Config{Host: "localhost", Port: "6380", MaxBulkRequestSize: memorystore.DefaultMaxBulkRequestSize}
+
+	server := redis.NewTestRedisServer()
+	require.NoError(t, server.Run(*config))
+	defer server.Close()
+
+	tiered := &redis.TieredConfig{LocalTTL: time.Hour, StaleTTL: time.Hour, Local: &local.Config{}}
+	first := newTieredClient(t, config, tiered)
+	second := newTieredClient(t, config, tiered)
+
+	require.NoError(t, first.Set(ctx, "greeting", "hello", 0))
+	assert.Equal(t, "hello", getString(t, second, "greeting"))
+
+	// Значение обновлено первым экземпляром: второй получает уведомление и удаляет локальную копию.
+	require.NoError(t, first.Set(ctx, "greeting", "hi", 0))
+	assert.Eventually(t, func() bool {
+		value, err := second.Get(ctx, "greeting")
+		if err != nil {
+			return false
+		}
+
+		s, _ := value.String()
+
+		return s == "hi"
+	}, time.Second, 10*time.Millisecond)
+
+	values, err := second.GetList(ctx, "greeting", "missing")
+	require.NoError(t, err)
+	require.Len(t, values, 2)
+	assert.NotNil(t, values[0])
+	assert.Nil(t, values[1])
+
+	deleted, err := first.Delete(ctx, "greeting")
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	assert.Eventually(t, func() bool {
+		_, err := second.Get(ctx, "greeting")
+
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = second.Get(ctx, "greeting")
+	assert.ErrorIs(t, err, memorystore.ErrKeyNotFound)
+}
+
+func TestTieredClient_fallback(t *testing.T) {
+	ctx := context.Background()
+	config := &redis.Config{Host: "localhost", Port: "6381", MaxBulkRequestSize: memorystore.DefaultMaxBulkRequestSize}
+
+	server := redis.NewTestRedisServer()
+	require.NoError(t, server.Run(*config))
+
+	client := newTieredClient(t, config, &redis.TieredConfig{LocalTTL: time.Millisecond, StaleTTL: time.Hour})
+
+	require.NoError(t, client.Set(ctx, "greeting", "hello", 0))
+	time.Sleep(5 * time.Millisecond)
+
+	server.Close()
+
+	// Redis недоступен, актуальность локальной копии истекла, но она еще может использоваться.
+	assert.Equal(t, "hello", getString(t, client, "greeting"))
+
+	values, err := client.GetList(ctx, "greeting")
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+	assert.NotNil(t, values[0])
+
+	assert.Error(t, client.Set(ctx, "greeting", "hi", 0))
+
+	_, err = client.Get(ctx, "greeting")
+	assert.Error(t, err)
+}
+
+func TestTieredClient_remoteTTL(t *testing.T) {
+	ctx := context.Background()
+	config := &redis.Config{Host: "localhost", Port: "6382", MaxBulkRequestSize: memorystore.DefaultMaxBulkRequestSize}
+
+	server := redis.NewTestRedisServer()
+	require.NoError(t, server.Run(*config))
+
+	remote, err := redis.NewClient(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(func() { remote.Close(ctx) })
+
+	client := newTieredClient(t, config, &redis.TieredConfig{LocalTTL: time.Hour, StaleTTL: time.Hour})
+
+	// Ключи записаны в Redis в обход TieredClient со сроком жизни меньше LocalTTL.
+	require.NoError(t, remote.Set(ctx, "single", "hello", 50*time.Millisecond))
+	require.NoError(t, remote.Set(ctx, "listed", "hello", 50*time.Millisecond))
+
+	assert.Equal(t, "hello", getString(t, client, "single"))
+
+	values, err := client.GetList(ctx, "listed")
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+	assert.NotNil(t, values[0])
+
+	time.Sleep(100 * time.Millisecond)
+	server.Close()
+
+	// Срок жизни ключей истек: локальные копии не используются даже при недоступном Redis.
+	_, err = client.Get(ctx, "single")
+	assert.Error(t, err)
+
+	_, err = client.GetList(ctx, "listed")
+	assert.Error(t, err)
+}
@@ -11,9 +11,11 @@ var (
 )
 
 func BytesWrite(w io.Writer, t []byte) error {
-	_, err := w.Write(t)
+	if _, err := w.Write(t); err != nil {
+		return fmt.Errorf("can't write bytes: %w", err)
+	}
 
-	return fmt.Errorf("can't write bytes: %w", err)
+	return nil
 }
 
 func BytesRead(r io.Reader) ([]byte, error) {